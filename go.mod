@@ -1,3 +1,3 @@
 module github.com/es-na-battlesnake/snakes
 
-go 1.17
+go 1.18
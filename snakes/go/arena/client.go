@@ -0,0 +1,112 @@
+/*
+Package arena plays complete Battlesnake games locally against any set of snake base URLs. It acts as a stand-in
+for the official engine: every snake gets proper /start, /move and /end requests with its own GameState, moves
+are subject to the game's timeout, and the moves are applied with our own rules engine.
+*/
+package arena
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// Client talks to snake servers over HTTP.
+type Client struct {
+	HTTP *http.Client
+}
+
+// NewClient returns a Client with a sensible overall timeout. Per-move timeouts are applied separately.
+func NewClient() *Client {
+	return &Client{HTTP: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Info fetches the snake's customisation and API version from its index endpoint.
+func (c *Client) Info(ctx context.Context, url string) (rules.BattlesnakeInfoResponse, error) {
+	var info rules.BattlesnakeInfoResponse
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint(url, ""), nil)
+	if err != nil {
+		return info, err
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return info, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return info, fmt.Errorf("%s responded with %s", url, resp.Status)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&info)
+	return info, err
+}
+
+// Start sends the /start request.
+func (c *Client) Start(ctx context.Context, url string, state rules.GameState) error {
+	_, err := c.post(ctx, url, "start", state)
+	return err
+}
+
+// End sends the /end request.
+func (c *Client) End(ctx context.Context, url string, state rules.GameState) error {
+	_, err := c.post(ctx, url, "end", state)
+	return err
+}
+
+// Move asks the snake for its next move and measures how long it took to answer. The request is abandoned once
+// timeout has passed, in which case the error is context.DeadlineExceeded and the latency is the timeout.
+func (c *Client) Move(ctx context.Context, url string, state rules.GameState, timeout time.Duration) (rules.BattlesnakeMoveResponse, time.Duration, error) {
+	var response rules.BattlesnakeMoveResponse
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	started := time.Now()
+	body, err := c.post(ctx, url, "move", state)
+	latency := time.Since(started)
+	if err != nil {
+		if ctx.Err() != nil {
+			return response, timeout, ctx.Err()
+		}
+		return response, latency, err
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return response, latency, fmt.Errorf("decoding move from %s: %w", url, err)
+	}
+	return response, latency, nil
+}
+
+func (c *Client) post(ctx context.Context, url, path string, state rules.GameState) ([]byte, error) {
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint(url, path), bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s/%s responded with %s", url, path, resp.Status)
+	}
+	return body, nil
+}
+
+// endpoint joins a snake's base URL and a path without doubling up slashes.
+func endpoint(url, path string) string {
+	return strings.TrimRight(url, "/") + "/" + path
+}
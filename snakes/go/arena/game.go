@@ -0,0 +1,297 @@
+package arena
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// SnakeConfig names a snake server taking part in a game. Squad is only used in squad games.
+type SnakeConfig struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+	Squad string `json:"squad,omitempty"`
+}
+
+// Options describes the game to play. Zero values fall back to an 11x11 standard game with a 500ms timeout.
+type Options struct {
+	Width    int
+	Height   int
+	Ruleset  string
+	Map      string
+	Settings rules.Settings
+	Timeout  time.Duration
+	Seed     int64
+	// MaxTurns stops a game that would otherwise never end, such as two snakes chasing their tails forever.
+	MaxTurns int
+	// Board, if set, is the position to start from instead of placing the snakes and food from the seed. Its
+	// snakes must have the IDs of the snakes playing.
+	Board *rules.Board
+	// Opening forces the first moves of snakes, by snake ID, instead of asking them. The snakes are still asked,
+	// so they see the game as it goes.
+	Opening map[string][]string
+}
+
+func (o Options) withDefaults() Options {
+	if o.Width == 0 {
+		o.Width = 11
+	}
+	if o.Height == 0 {
+		o.Height = 11
+	}
+	if o.Ruleset == "" {
+		o.Ruleset = rules.RulesetStandard
+	}
+	if o.Map == "" {
+		o.Map = rules.MapStandard
+	}
+	if o.Settings == (rules.Settings{}) {
+		o.Settings = rules.DefaultSettings()
+	}
+	if o.Timeout == 0 {
+		o.Timeout = 500 * time.Millisecond
+	}
+	if o.MaxTurns == 0 {
+		o.MaxTurns = 2000
+	}
+	return o
+}
+
+// Runner plays games. Recorder may be nil if no recording is wanted.
+type Runner struct {
+	Client   *Client
+	Recorder *Recorder
+}
+
+// NewRunner returns a Runner with a default Client.
+func NewRunner() *Runner {
+	return &Runner{Client: NewClient()}
+}
+
+type moveResult struct {
+	move    string
+	latency time.Duration
+	err     error
+}
+
+type snakeStats struct {
+	moves    int
+	timeouts int
+	total    time.Duration
+	max      time.Duration
+}
+
+// Play runs one game from /start to /end and returns the result.
+func (r *Runner) Play(ctx context.Context, opts Options, snakes []SnakeConfig) (Result, error) {
+	opts = opts.withDefaults()
+	if len(snakes) == 0 {
+		return Result{}, errors.New("no snakes to play")
+	}
+	snakes = append([]SnakeConfig{}, snakes...)
+	players := make([]rules.Battlesnake, len(snakes))
+	for i := range snakes {
+		if snakes[i].ID == "" {
+			snakes[i].ID = fmt.Sprintf("snake-%d", i+1)
+		}
+		if snakes[i].Name == "" {
+			snakes[i].Name = snakes[i].ID
+		}
+		players[i] = rules.Battlesnake{ID: snakes[i].ID, Name: snakes[i].Name, Squad: snakes[i].Squad}
+	}
+
+	game := rules.Game{
+		ID:      fmt.Sprintf("arena-%d", opts.Seed),
+		Ruleset: rules.Ruleset{Name: opts.Ruleset, Version: "arena", Settings: opts.Settings},
+		Timeout: int32(opts.Timeout / time.Millisecond),
+		Map:     opts.Map,
+		Source:  "arena",
+	}
+	var engine *rules.Engine
+	var err error
+	if opts.Board != nil {
+		engine, err = startFrom(game, *opts.Board, snakes, opts.Seed)
+	} else {
+		engine, err = rules.NewEngine(game, opts.Width, opts.Height, players, opts.Seed)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	if err := r.Recorder.writeHeader(Header{Game: engine.Game, Seed: opts.Seed, Snakes: snakes}); err != nil {
+		return Result{}, err
+	}
+
+	// Keep the last state each snake was in so /end can be sent to eliminated snakes too.
+	last := make(map[string]rules.Battlesnake)
+	stats := make(map[string]*snakeStats)
+	for _, snake := range engine.Board.Snakes {
+		last[snake.ID] = snake
+		stats[snake.ID] = &snakeStats{}
+	}
+
+	r.broadcast(ctx, engine, snakes, last, r.Client.Start)
+
+	for !engine.IsOver() && engine.Turn < opts.MaxTurns {
+		if err := ctx.Err(); err != nil {
+			return Result{}, err
+		}
+		frame := Frame{
+			Turn:    engine.Turn,
+			Board:   engine.Board.Clone(),
+			Moves:   make(map[string]string),
+			Latency: make(map[string]int64),
+		}
+
+		results := r.requestMoves(ctx, engine, snakes, opts.Timeout)
+		moves := make(map[string]string)
+		for id, res := range results {
+			s := stats[id]
+			s.moves++
+			s.total += res.latency
+			if res.latency > s.max {
+				s.max = res.latency
+			}
+			if errors.Is(res.err, context.DeadlineExceeded) {
+				s.timeouts++
+				frame.Timeouts = append(frame.Timeouts, id)
+			} else if res.err != nil {
+				log.Printf("ERROR: %s move failed on turn %d, %s", id, engine.Turn, res.err)
+			}
+			if opening := opts.Opening[id]; engine.Turn < len(opening) {
+				res.move = opening[engine.Turn]
+			}
+			moves[id] = res.move
+			frame.Moves[id] = res.move
+			frame.Latency[id] = res.latency.Milliseconds()
+		}
+		sort.Strings(frame.Timeouts)
+
+		frame.Eliminated = engine.Step(moves)
+		for id, res := range results {
+			engine.SetLatency(id, strconv.FormatInt(res.latency.Milliseconds(), 10))
+		}
+		for _, snake := range engine.Board.Snakes {
+			last[snake.ID] = snake
+		}
+		if err := r.Recorder.writeFrame(frame); err != nil {
+			return Result{}, err
+		}
+	}
+	if err := r.Recorder.writeFrame(Frame{Turn: engine.Turn, Board: engine.Board.Clone()}); err != nil {
+		return Result{}, err
+	}
+
+	r.broadcast(ctx, engine, snakes, last, r.Client.End)
+
+	result := buildResult(engine, snakes, stats)
+	return result, r.Recorder.writeResult(result)
+}
+
+// startFrom returns an engine for a game starting from the board, which must have a snake for each one playing.
+func startFrom(game rules.Game, board rules.Board, snakes []SnakeConfig, seed int64) (*rules.Engine, error) {
+	if len(board.Snakes) != len(snakes) {
+		return nil, fmt.Errorf("board has %d snakes for %d players", len(board.Snakes), len(snakes))
+	}
+	for _, snake := range snakes {
+		found := false
+		for i := range board.Snakes {
+			if board.Snakes[i].ID == snake.ID {
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("board has no snake %s", snake.ID)
+		}
+	}
+	return rules.ResumeEngine(game, 0, board, seed)
+}
+
+// requestMoves asks every live snake for a move in parallel.
+func (r *Runner) requestMoves(ctx context.Context, engine *rules.Engine, snakes []SnakeConfig, timeout time.Duration) map[string]moveResult {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]moveResult)
+	for _, snake := range snakes {
+		state, ok := engine.StateFor(snake.ID)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(snake SnakeConfig, state rules.GameState) {
+			defer wg.Done()
+			response, latency, err := r.Client.Move(ctx, snake.URL, state, timeout)
+			mu.Lock()
+			results[snake.ID] = moveResult{move: response.Move, latency: latency, err: err}
+			mu.Unlock()
+		}(snake, state)
+	}
+	wg.Wait()
+	return results
+}
+
+// broadcast sends /start or /end to every snake in the game. Failures are logged but don't stop the game,
+// the same as the official engine.
+func (r *Runner) broadcast(ctx context.Context, engine *rules.Engine, snakes []SnakeConfig, last map[string]rules.Battlesnake, send func(context.Context, string, rules.GameState) error) {
+	var wg sync.WaitGroup
+	for _, snake := range snakes {
+		state := rules.GameState{Game: engine.Game, Turn: engine.Turn, Board: engine.Board, You: last[snake.ID]}
+		wg.Add(1)
+		go func(snake SnakeConfig, state rules.GameState) {
+			defer wg.Done()
+			if err := send(ctx, snake.URL, state); err != nil {
+				log.Printf("ERROR: %s did not accept request, %s", snake.Name, err)
+			}
+		}(snake, state)
+	}
+	wg.Wait()
+}
+
+// buildResult ranks the snakes. The survivor is first, everyone else is placed by how long they lasted, with
+// snakes eliminated on the same turn sharing a place.
+func buildResult(engine *rules.Engine, snakes []SnakeConfig, stats map[string]*snakeStats) Result {
+	result := Result{Turns: engine.Turn, IsDraw: true}
+	if winner, ok := engine.Winner(); ok {
+		result.WinnerID = winner.ID
+		result.WinnerName = winner.Name
+		result.IsDraw = false
+	}
+
+	eliminations := make(map[string]rules.Elimination)
+	for _, elimination := range engine.Eliminated {
+		eliminations[elimination.ID] = elimination
+	}
+	// Snakes still on the board outrank one eliminated on the final turn.
+	lasted := make([]int, len(snakes))
+	for i, snake := range snakes {
+		s := stats[snake.ID]
+		sr := SnakeResult{ID: snake.ID, Name: snake.Name, URL: snake.URL, Squad: snake.Squad, Survived: engine.Turn, Moves: s.moves, Timeouts: s.timeouts, MaxMillis: s.max.Milliseconds()}
+		if s.moves > 0 {
+			sr.MeanMillis = (s.total / time.Duration(s.moves)).Milliseconds()
+		}
+		lasted[i] = engine.Turn + 1
+		if elimination, ok := eliminations[snake.ID]; ok {
+			sr.Survived = elimination.Turn
+			sr.Cause = elimination.Cause
+			lasted[i] = elimination.Turn
+		}
+		result.Snakes = append(result.Snakes, sr)
+	}
+
+	for i := range result.Snakes {
+		place := 1
+		for j := range result.Snakes {
+			if lasted[j] > lasted[i] {
+				place++
+			}
+		}
+		result.Snakes[i].Place = place
+	}
+	return result
+}
@@ -0,0 +1,198 @@
+package arena
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// testSnake is a minimal snake server that answers every move with the same direction.
+type testSnake struct {
+	move  string
+	delay time.Duration
+
+	mu    sync.Mutex
+	calls map[string]int
+	ids   []string
+}
+
+func (s *testSnake) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	if s.calls == nil {
+		s.calls = make(map[string]int)
+	}
+	s.calls[r.URL.Path]++
+	s.mu.Unlock()
+
+	if r.URL.Path == "/" {
+		json.NewEncoder(w).Encode(rules.BattlesnakeInfoResponse{APIVersion: "1"})
+		return
+	}
+	var state rules.GameState
+	json.NewDecoder(r.Body).Decode(&state)
+	s.mu.Lock()
+	s.ids = append(s.ids, state.You.ID)
+	s.mu.Unlock()
+	if r.URL.Path == "/move" {
+		time.Sleep(s.delay)
+		json.NewEncoder(w).Encode(rules.BattlesnakeMoveResponse{Move: s.move})
+	}
+}
+
+func TestPlayRecordsGame(t *testing.T) {
+	// Arrange
+	up := &testSnake{move: rules.MoveUp}
+	down := &testSnake{move: rules.MoveDown}
+	upServer := httptest.NewServer(up)
+	defer upServer.Close()
+	downServer := httptest.NewServer(down)
+	defer downServer.Close()
+
+	var out bytes.Buffer
+	runner := NewRunner()
+	runner.Recorder = NewRecorder(&out)
+	snakes := []SnakeConfig{{Name: "up", URL: upServer.URL}, {Name: "down", URL: downServer.URL}}
+
+	// Act
+	result, err := runner.Play(context.Background(), Options{Seed: 7}, snakes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	if result.Turns == 0 || result.Turns > 11 {
+		t.Errorf("both snakes should hit a wall within 11 turns, game lasted %d", result.Turns)
+	}
+	if up.calls["/start"] != 1 || up.calls["/end"] != 1 || up.calls["/move"] == 0 {
+		t.Errorf("unexpected requests to snake: %v", up.calls)
+	}
+	for _, id := range up.ids {
+		if id != "snake-1" {
+			t.Errorf("snake got a request with You set to %q", id)
+		}
+	}
+	rec, err := ReadRecording(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.Frames) != result.Turns+1 {
+		t.Errorf("expected a frame per turn plus the final board, got %d frames for %d turns", len(rec.Frames), result.Turns)
+	}
+	if rec.Result.WinnerName != result.WinnerName || rec.Header.Seed != 7 {
+		t.Errorf("recording does not match result: %+v", rec.Result)
+	}
+}
+
+func TestPlayEnforcesTimeout(t *testing.T) {
+	// Arrange
+	slow := &testSnake{move: rules.MoveLeft, delay: 100 * time.Millisecond}
+	server := httptest.NewServer(slow)
+	defer server.Close()
+	runner := NewRunner()
+
+	// Act
+	result, err := runner.Play(context.Background(), Options{Ruleset: rules.RulesetSolo, Timeout: 20 * time.Millisecond, MaxTurns: 3}, []SnakeConfig{{Name: "slow", URL: server.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	snake := result.Snakes[0]
+	if snake.Timeouts != snake.Moves || snake.Moves == 0 {
+		t.Errorf("expected every move to time out, got %d timeouts in %d moves", snake.Timeouts, snake.Moves)
+	}
+	if snake.MaxMillis < 20 {
+		t.Errorf("expected latency to be recorded as the timeout, got %dms", snake.MaxMillis)
+	}
+}
+
+func TestPlayFromBoardWithOpening(t *testing.T) {
+	// Arrange
+	up := &testSnake{move: rules.MoveUp}
+	server := httptest.NewServer(up)
+	defer server.Close()
+	board := rules.Board{Width: 11, Height: 11, Snakes: []rules.Battlesnake{
+		{ID: "snake-1", Health: 100, Body: []rules.Coord{{X: 5, Y: 5}, {X: 5, Y: 5}, {X: 5, Y: 5}}},
+	}}
+
+	var out bytes.Buffer
+	runner := NewRunner()
+	runner.Recorder = NewRecorder(&out)
+	opts := Options{Ruleset: rules.RulesetSolo, Board: &board, Opening: map[string][]string{"snake-1": {rules.MoveLeft, rules.MoveLeft}}}
+
+	// Act
+	result, err := runner.Play(context.Background(), opts, []SnakeConfig{{Name: "up", URL: server.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	rec, err := ReadRecording(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head := rec.Frames[0].Board.Snakes[0].Head; head != (rules.Coord{X: 5, Y: 5}) {
+		t.Errorf("expected the game to start from the board, snake started at %v", head)
+	}
+	var moves []string
+	for _, frame := range rec.Frames[:3] {
+		moves = append(moves, frame.Moves["snake-1"])
+	}
+	if moves[0] != rules.MoveLeft || moves[1] != rules.MoveLeft || moves[2] != rules.MoveUp {
+		t.Errorf("expected the opening to be played before the snake's own moves, got %v", moves)
+	}
+	if result.Turns != 8 {
+		t.Errorf("expected the snake to go left twice then hit the top wall after 6 more, game lasted %d", result.Turns)
+	}
+}
+
+func TestPlaySquadGame(t *testing.T) {
+	// Arrange
+	up := &testSnake{move: rules.MoveUp}
+	down := &testSnake{move: rules.MoveDown}
+	upServer := httptest.NewServer(up)
+	defer upServer.Close()
+	downServer := httptest.NewServer(down)
+	defer downServer.Close()
+	snake := func(id, squad string, x, y int) rules.Battlesnake {
+		c := rules.Coord{X: x, Y: y}
+		return rules.Battlesnake{ID: id, Name: squad, Squad: squad, Health: 100, Body: []rules.Coord{c, c, c}}
+	}
+	board := rules.Board{Width: 11, Height: 11, Food: []rules.Coord{}, Hazards: []rules.Coord{}, Snakes: []rules.Battlesnake{
+		snake("snake-1", "up", 1, 1), snake("snake-2", "up", 3, 1), snake("snake-3", "down", 7, 7), snake("snake-4", "down", 9, 9),
+	}}
+	snakes := []SnakeConfig{
+		{Name: "up", URL: upServer.URL, Squad: "up"},
+		{Name: "up", URL: upServer.URL, Squad: "up"},
+		{Name: "down", URL: downServer.URL, Squad: "down"},
+		{Name: "down", URL: downServer.URL, Squad: "down"},
+	}
+	opts := Options{Ruleset: rules.RulesetSquad, Map: rules.MapEmpty, Board: &board}
+
+	// Act
+	result, err := NewRunner().Play(context.Background(), opts, snakes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert: snake-3 hits the bottom wall on turn 8 and takes snake-4 with it, before the up squad reaches the top.
+	if result.IsDraw || result.WinnerName != "up" || result.Turns != 8 {
+		t.Errorf("expected the up squad to win after 8 turns, got %+v", result)
+	}
+	places := []int{1, 1, 3, 3}
+	for i, snake := range result.Snakes {
+		if snake.Place != places[i] || snake.Squad != snakes[i].Squad {
+			t.Errorf("unexpected result for %s: %+v", snake.ID, snake)
+		}
+	}
+	if cause := result.Snakes[3].Cause; cause != rules.EliminatedBySquad {
+		t.Errorf("expected snake-4 to be eliminated with its squad, got %q", cause)
+	}
+}
@@ -0,0 +1,148 @@
+package arena
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// A recording is a JSONL file: one Header line, one Frame line per turn and a final Result line.
+// The result line uses the same winnerId/winnerName/isDraw keys as the battlesnake CLI's output file.
+
+// Header is the first line of a recording.
+type Header struct {
+	Game   rules.Game    `json:"game"`
+	Seed   int64         `json:"seed"`
+	Snakes []SnakeConfig `json:"snakes"`
+}
+
+// Frame is the board at the start of a turn, together with the moves each snake answered with and how long it
+// took. The last frame of a game has no moves.
+type Frame struct {
+	Turn       int                 `json:"turn"`
+	Board      rules.Board         `json:"board"`
+	Moves      map[string]string   `json:"moves,omitempty"`
+	Latency    map[string]int64    `json:"latency,omitempty"`
+	Timeouts   []string            `json:"timeouts,omitempty"`
+	Eliminated []rules.Elimination `json:"eliminated,omitempty"`
+}
+
+// Result is the last line of a recording.
+type Result struct {
+	WinnerID   string        `json:"winnerId"`
+	WinnerName string        `json:"winnerName"`
+	IsDraw     bool          `json:"isDraw"`
+	Turns      int           `json:"turns"`
+	Snakes     []SnakeResult `json:"snakes"`
+}
+
+// SnakeResult summarises how one snake did in a game.
+type SnakeResult struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	URL        string `json:"url"`
+	Squad      string `json:"squad,omitempty"`
+	Place      int    `json:"place"`
+	Survived   int    `json:"survived"`
+	Cause      string `json:"cause,omitempty"`
+	Moves      int    `json:"moves"`
+	Timeouts   int    `json:"timeouts"`
+	MeanMillis int64  `json:"meanLatency"`
+	MaxMillis  int64  `json:"maxLatency"`
+}
+
+// Recording is a fully parsed game.
+type Recording struct {
+	Header Header
+	Frames []Frame
+	Result Result
+}
+
+// Recorder writes a game to a JSONL stream as it is played.
+type Recorder struct {
+	enc *json.Encoder
+}
+
+// NewRecorder returns a Recorder that writes to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{enc: json.NewEncoder(w)}
+}
+
+func (r *Recorder) writeHeader(h Header) error {
+	if r == nil {
+		return nil
+	}
+	return r.enc.Encode(h)
+}
+
+func (r *Recorder) writeFrame(f Frame) error {
+	if r == nil {
+		return nil
+	}
+	return r.enc.Encode(f)
+}
+
+func (r *Recorder) writeResult(res Result) error {
+	if r == nil {
+		return nil
+	}
+	return r.enc.Encode(res)
+}
+
+// ReadRecording parses a recording written by a Recorder. A recording cut short by a crash is returned with an
+// empty Result and io.ErrUnexpectedEOF.
+func ReadRecording(r io.Reader) (Recording, error) {
+	var rec Recording
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var lines [][]byte
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		lines = append(lines, append([]byte{}, scanner.Bytes()...))
+	}
+	if err := scanner.Err(); err != nil {
+		return rec, err
+	}
+	if len(lines) == 0 {
+		return rec, errors.New("empty recording")
+	}
+	if err := json.Unmarshal(lines[0], &rec.Header); err != nil {
+		return rec, fmt.Errorf("reading header: %w", err)
+	}
+
+	complete := false
+	for i, line := range lines[1:] {
+		if isResultLine(line) {
+			if err := json.Unmarshal(line, &rec.Result); err != nil {
+				return rec, fmt.Errorf("reading result: %w", err)
+			}
+			complete = true
+			break
+		}
+		var frame Frame
+		if err := json.Unmarshal(line, &frame); err != nil {
+			return rec, fmt.Errorf("reading frame %d: %w", i, err)
+		}
+		rec.Frames = append(rec.Frames, frame)
+	}
+	if !complete {
+		return rec, io.ErrUnexpectedEOF
+	}
+	return rec, nil
+}
+
+func isResultLine(line []byte) bool {
+	var probe map[string]json.RawMessage
+	if json.Unmarshal(line, &probe) != nil {
+		return false
+	}
+	_, ok := probe["isDraw"]
+	return ok
+}
@@ -0,0 +1,142 @@
+package asciiboard
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+func TestParse(t *testing.T) {
+	// Act
+	state, err := Parse(`
+		ruleset: wrapped
+		turn: 12
+		health: A=20
+		name: A=me B=other
+
+		. . . . . . .
+		. . a a a . .
+		. . a . A . *
+		. . . . . . .
+		B b b . . . x
+		. . . . . . .
+		x x x x x x *x
+	`)
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Game.Ruleset.Name != rules.RulesetWrapped || state.Turn != 12 || state.Board.Width != 7 || state.Board.Height != 7 {
+		t.Errorf("unexpected game %+v turn %d", state.Game, state.Turn)
+	}
+	me := []rules.Coord{{X: 4, Y: 4}, {X: 4, Y: 5}, {X: 3, Y: 5}, {X: 2, Y: 5}, {X: 2, Y: 4}}
+	if state.You.ID != "me" || state.You.Health != 20 || !reflect.DeepEqual(state.You.Body, me) {
+		t.Errorf("unexpected you %+v", state.You)
+	}
+	other := state.Board.Snakes[1]
+	if other.ID != "other" || other.Health != 100 || other.Head != (rules.Coord{X: 0, Y: 2}) || other.Length != 3 {
+		t.Errorf("unexpected other %+v", other)
+	}
+	if len(state.Board.Hazards) != 8 || len(state.Board.Food) != 2 {
+		t.Errorf("expected 8 hazards and 2 food, got %v and %v", state.Board.Hazards, state.Board.Food)
+	}
+}
+
+func TestParseBodyOrder(t *testing.T) {
+	// Arrange
+	cases := []struct {
+		name  string
+		board string
+		body  []rules.Coord
+		err   string
+	}{
+		{
+			// Two segments touch the head, but only one order uses every segment.
+			name:  "backtracking",
+			board: "a a .\nA a a",
+			body:  []rules.Coord{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 0}, {X: 2, Y: 0}},
+		},
+		{
+			name:  "ambiguous",
+			board: "a a .\nA a .",
+			err:   "more than one way",
+		},
+		{
+			name:  "arrows",
+			board: "av a< .\nA a^ .",
+			body:  []rules.Coord{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 0}},
+		},
+		{
+			name:  "numbers",
+			board: "a3 a2 .\nA a1 .",
+			body:  []rules.Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}},
+		},
+		{
+			name:  "wrapped",
+			board: "ruleset: wrapped\na . A\n. . .",
+			body:  []rules.Coord{{X: 2, Y: 1}, {X: 0, Y: 1}},
+		},
+		{
+			name:  "stacked",
+			board: "length: A=4\nA a .",
+			body:  []rules.Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 0}},
+		},
+		{
+			name:  "disconnected",
+			board: "A . a",
+			err:   "single line",
+		},
+	}
+
+	for _, c := range cases {
+		// Act
+		state, err := Parse(c.board)
+
+		// Assert
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%s: expected an error containing %q, got %v", c.name, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(state.You.Body, c.body) {
+			t.Errorf("%s: expected body %v, got %v", c.name, c.body, state.You.Body)
+		}
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	// Arrange
+	state := MustParse(`
+		ruleset: wrapped
+		turn: 3
+		you: B
+		health: B=42
+		length: A=3
+		squad: A=red B=red
+
+		a . . . A
+		b b . . .
+		. B . . *x
+		x . . . .
+	`)
+
+	// Act
+	text := Format(state)
+	again, err := Parse(text)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("could not parse formatted board:\n%s\n%v", text, err)
+	}
+	if !reflect.DeepEqual(state, again) {
+		t.Errorf("round trip changed the state:\n%s\nbefore %+v\nafter  %+v", text, state, again)
+	}
+}
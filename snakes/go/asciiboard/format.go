@@ -0,0 +1,119 @@
+package asciiboard
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// Format draws a game state as a board that Parse reads back to the same state, apart from latencies and
+// shouts. Snakes are lettered in board order, skipping X, and every body segment gets an arrow so the output
+// is never ambiguous. Snake IDs other than their letter are kept in a name header.
+func Format(state rules.GameState) string {
+	board := state.Board
+	letters := make(map[string]rune)
+	letter := 'A'
+	for _, snake := range board.Snakes {
+		if letter == 'X' {
+			letter++
+		}
+		letters[snake.ID] = letter
+		letter++
+	}
+
+	var b strings.Builder
+	if state.Game.Ruleset.Name != "" && state.Game.Ruleset.Name != rules.RulesetStandard {
+		fmt.Fprintf(&b, "ruleset: %s\n", state.Game.Ruleset.Name)
+	}
+	if state.Game.Map != "" {
+		fmt.Fprintf(&b, "map: %s\n", state.Game.Map)
+	}
+	if state.Turn != 0 {
+		fmt.Fprintf(&b, "turn: %d\n", state.Turn)
+	}
+	if damage := state.Game.Ruleset.Settings.HazardDamagePerTurn; damage != 14 {
+		fmt.Fprintf(&b, "hazardDamage: %d\n", damage)
+	}
+	if you, ok := letters[state.You.ID]; ok && you != 'A' {
+		fmt.Fprintf(&b, "you: %c\n", you)
+	}
+	var health, length, names, squads []string
+	for _, snake := range board.Snakes {
+		l := letters[snake.ID]
+		if snake.Health != 100 {
+			health = append(health, fmt.Sprintf("%c=%d", l, snake.Health))
+		}
+		if stacked(snake.Body) {
+			length = append(length, fmt.Sprintf("%c=%d", l, len(snake.Body)))
+		}
+		if snake.ID != string(l) {
+			names = append(names, fmt.Sprintf("%c=%s", l, snake.ID))
+		}
+		if snake.Squad != "" {
+			squads = append(squads, fmt.Sprintf("%c=%s", l, snake.Squad))
+		}
+	}
+	for _, h := range []struct {
+		key   string
+		pairs []string
+	}{{"health", health}, {"length", length}, {"name", names}, {"squad", squads}} {
+		if len(h.pairs) > 0 {
+			fmt.Fprintf(&b, "%s: %s\n", h.key, strings.Join(h.pairs, " "))
+		}
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+
+	cells := make(map[rules.Coord]string)
+	for _, food := range board.Food {
+		cells[food] = "*"
+	}
+	for _, snake := range board.Snakes {
+		l := letters[snake.ID]
+		for i := len(snake.Body) - 1; i > 0; i-- {
+			cells[snake.Body[i]] = fmt.Sprintf("%c%c", l+'a'-'A', arrow(snake.Body[i], snake.Body[i-1], board))
+		}
+		if len(snake.Body) > 0 {
+			cells[snake.Body[0]] = string(l)
+		}
+	}
+	for _, hazard := range board.Hazards {
+		if cell, ok := cells[hazard]; ok {
+			cells[hazard] = cell + "x"
+		} else {
+			cells[hazard] = "x"
+		}
+	}
+
+	for y := board.Height - 1; y >= 0; y-- {
+		row := make([]string, board.Width)
+		for x := range row {
+			row[x] = "."
+			if cell, ok := cells[rules.Coord{X: x, Y: y}]; ok {
+				row[x] = cell
+			}
+		}
+		b.WriteString(strings.Join(row, " "))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// arrow points from a body segment to the one nearer the head, across the edge of the board if need be.
+func arrow(from, to rules.Coord, board rules.Board) rune {
+	arrows := map[string]rune{rules.MoveUp: '^', rules.MoveDown: 'v', rules.MoveLeft: '<', rules.MoveRight: '>'}
+	for _, move := range rules.Moves {
+		n := from.Neighbour(move)
+		if n == to || (rules.Coord{X: (n.X + board.Width) % board.Width, Y: (n.Y + board.Height) % board.Height}) == to {
+			return arrows[move]
+		}
+	}
+	return '?'
+}
+
+// stacked reports whether the tail of a body is stacked on itself.
+func stacked(body []rules.Coord) bool {
+	return len(body) > 1 && body[len(body)-1] == body[len(body)-2]
+}
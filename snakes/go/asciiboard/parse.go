@@ -0,0 +1,375 @@
+/*
+Package asciiboard reads and writes game states as small ASCII pictures, so snake tests can show the position
+they are about instead of building Battlesnake literals coordinate by coordinate.
+
+A board looks like this:
+
+	ruleset: wrapped
+	turn: 12
+	health: A=20
+	name: A=me B=other
+
+	. . . . . . .
+	. . a a a . .
+	. . a . A . *
+	. . . . . . .
+	B b b . . . x
+	. . . . . . .
+	x x x x x x *x
+
+Header lines are "key: value" and come before the board. The keys are:
+
+  - ruleset, map, turn: the game's ruleset (default standard), map and turn.
+  - you: the letter of the snake the state is for, A by default.
+  - health: letter=health for each snake that isn't at 100.
+  - length: letter=length for a snake whose tail is stacked, for example just after eating. The last segment
+    is repeated to make up the length.
+  - name: letter=name to give a snake an ID and name. Otherwise both are its letter.
+  - squad: letter=squad for each snake in a squad, for squad games.
+  - hazardDamage: damage per turn in hazards, 14 by default.
+
+Each row of the board is a row of cells separated by spaces, with the top row being the highest y as in the
+official viewer. A cell is one of:
+
+  - "." for an empty cell, "*" for food and "x" for a hazard. A hazard under something else is written as a
+    trailing x, like "*x" or "Ax". Repeat the x for stacked hazards.
+  - an upper case letter for a snake's head. X is not allowed because x means hazard.
+  - the same letter in lower case for the rest of its body. The body is found by walking from the head to
+    neighbouring cells of the same letter. Where that is ambiguous, as when the tail touches the head in a loop,
+    either number the segments from the head ("a1", "a2", ...) or add an arrow pointing at the segment nearer
+    the head ("a<", "a^", "a>", "av").
+*/
+package asciiboard
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// MustParse is like Parse but panics if the board can't be parsed. It is meant for tests, where a bad board is
+// a mistake in the test itself.
+func MustParse(board string) rules.GameState {
+	state, err := Parse(board)
+	if err != nil {
+		panic(err)
+	}
+	return state
+}
+
+// Parse reads a board in the format described in the package documentation.
+func Parse(board string) (rules.GameState, error) {
+	p := parser{
+		health:  make(map[rune]int32),
+		length:  make(map[rune]int),
+		names:   make(map[rune]string),
+		squads:  make(map[rune]string),
+		you:     'A',
+		damage:  14,
+		ruleset: rules.RulesetStandard,
+	}
+	var rows [][]string
+	for number, line := range strings.Split(board, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case strings.Contains(line, ":"):
+			if len(rows) > 0 {
+				return rules.GameState{}, fmt.Errorf("line %d: header after the board", number+1)
+			}
+			if err := p.header(line); err != nil {
+				return rules.GameState{}, fmt.Errorf("line %d: %w", number+1, err)
+			}
+		default:
+			rows = append(rows, strings.Fields(line))
+		}
+	}
+	if len(rows) == 0 {
+		return rules.GameState{}, fmt.Errorf("no board")
+	}
+	return p.board(rows)
+}
+
+type segment struct {
+	coord rules.Coord
+	index int
+	arrow rune
+}
+
+type parser struct {
+	ruleset, gameMap string
+	turn             int
+	you              rune
+	damage           int32
+	health           map[rune]int32
+	length           map[rune]int
+	names            map[rune]string
+	squads           map[rune]string
+
+	width, height int
+	heads         map[rune]rules.Coord
+	segments      map[rune][]segment
+}
+
+func (p *parser) header(line string) error {
+	i := strings.Index(line, ":")
+	key, value := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+	var err error
+	switch key {
+	case "ruleset":
+		p.ruleset = value
+	case "map":
+		p.gameMap = value
+	case "turn":
+		p.turn, err = strconv.Atoi(value)
+	case "you":
+		if len(value) != 1 || !isSnakeLetter(rune(value[0])) {
+			return fmt.Errorf("you should be a snake letter, got %q", value)
+		}
+		p.you = unicode.ToUpper(rune(value[0]))
+	case "hazardDamage":
+		var n int
+		n, err = strconv.Atoi(value)
+		p.damage = int32(n)
+	case "health", "length", "name", "squad":
+		for _, pair := range strings.Fields(value) {
+			i := strings.Index(pair, "=")
+			if i != 1 || !isSnakeLetter(rune(pair[0])) {
+				return fmt.Errorf("%s should be letter=value pairs, got %q", key, pair)
+			}
+			letter, v := unicode.ToUpper(rune(pair[0])), pair[2:]
+			switch key {
+			case "name":
+				p.names[letter] = v
+				continue
+			case "squad":
+				p.squads[letter] = v
+				continue
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("%s of %c: %w", key, letter, err)
+			}
+			if key == "health" {
+				p.health[letter] = int32(n)
+			} else {
+				p.length[letter] = n
+			}
+		}
+	default:
+		return fmt.Errorf("unknown header %q", key)
+	}
+	return err
+}
+
+func (p *parser) board(rows [][]string) (rules.GameState, error) {
+	p.height = len(rows)
+	p.width = len(rows[0])
+	p.heads = make(map[rune]rules.Coord)
+	p.segments = make(map[rune][]segment)
+
+	board := rules.Board{Width: p.width, Height: p.height, Food: []rules.Coord{}, Hazards: []rules.Coord{}, Snakes: []rules.Battlesnake{}}
+	for r, row := range rows {
+		if len(row) != p.width {
+			return rules.GameState{}, fmt.Errorf("row %d has %d cells, the first row has %d", r+1, len(row), p.width)
+		}
+		y := p.height - 1 - r
+		for x, cell := range row {
+			c := rules.Coord{X: x, Y: y}
+			for len(cell) > 1 && strings.HasSuffix(cell, "x") {
+				board.Hazards = append(board.Hazards, c)
+				cell = cell[:len(cell)-1]
+			}
+			if err := p.cell(&board, c, cell); err != nil {
+				return rules.GameState{}, fmt.Errorf("row %d, cell %d: %w", r+1, x+1, err)
+			}
+		}
+	}
+
+	letters := make([]rune, 0, len(p.heads))
+	for letter := range p.heads {
+		letters = append(letters, letter)
+	}
+	for letter := range p.segments {
+		if _, ok := p.heads[letter]; !ok {
+			return rules.GameState{}, fmt.Errorf("snake %c has a body but no head", letter)
+		}
+	}
+	sort.Slice(letters, func(i, j int) bool { return letters[i] < letters[j] })
+
+	state := rules.GameState{
+		Game: rules.Game{
+			Ruleset: rules.Ruleset{Name: p.ruleset, Settings: rules.Settings{HazardDamagePerTurn: p.damage}},
+			Map:     p.gameMap,
+		},
+		Turn: p.turn,
+	}
+	found := false
+	for _, letter := range letters {
+		snake, err := p.snake(letter)
+		if err != nil {
+			return rules.GameState{}, err
+		}
+		board.Snakes = append(board.Snakes, snake)
+		if letter == p.you {
+			state.You = snake
+			found = true
+		}
+	}
+	if !found && len(board.Snakes) > 0 {
+		return rules.GameState{}, fmt.Errorf("there is no snake %c to be you", p.you)
+	}
+	state.Board = board
+	return state, nil
+}
+
+func (p *parser) cell(board *rules.Board, c rules.Coord, cell string) error {
+	switch cell {
+	case ".":
+		return nil
+	case "*":
+		board.Food = append(board.Food, c)
+		return nil
+	case "x":
+		board.Hazards = append(board.Hazards, c)
+		return nil
+	}
+
+	letter := rune(cell[0])
+	if !isSnakeLetter(letter) {
+		return fmt.Errorf("unknown cell %q", cell)
+	}
+	if unicode.IsUpper(letter) {
+		if len(cell) != 1 {
+			return fmt.Errorf("unknown cell %q", cell)
+		}
+		if _, ok := p.heads[letter]; ok {
+			return fmt.Errorf("snake %c has two heads", letter)
+		}
+		p.heads[letter] = c
+		return nil
+	}
+
+	letter = unicode.ToUpper(letter)
+	s := segment{coord: c}
+	rest := cell[1:]
+	switch {
+	case rest == "":
+	case rest == "<" || rest == ">" || rest == "^" || rest == "v":
+		s.arrow = rune(rest[0])
+	default:
+		n, err := strconv.Atoi(rest)
+		if err != nil || n < 1 {
+			return fmt.Errorf("unknown cell %q, body segments are numbered from 1", cell)
+		}
+		s.index = n
+	}
+	p.segments[letter] = append(p.segments[letter], s)
+	return nil
+}
+
+// snake puts the body of one snake in order from the head.
+func (p *parser) snake(letter rune) (rules.Battlesnake, error) {
+	head := p.heads[letter]
+	segments := p.segments[letter]
+	body := []rules.Coord{head}
+
+	numbered := 0
+	for _, s := range segments {
+		if s.index > 0 {
+			numbered++
+		}
+	}
+	switch {
+	case numbered == len(segments):
+		sort.Slice(segments, func(i, j int) bool { return segments[i].index < segments[j].index })
+		for i, s := range segments {
+			if s.index != i+1 {
+				return rules.Battlesnake{}, fmt.Errorf("snake %c: segment numbers should run 1 to %d", letter, len(segments))
+			}
+			if !p.adjacent(body[i], s.coord) {
+				return rules.Battlesnake{}, fmt.Errorf("snake %c: segment %d at %v is not next to the one before it", letter, s.index, s.coord)
+			}
+			body = append(body, s.coord)
+		}
+	case numbered > 0:
+		return rules.Battlesnake{}, fmt.Errorf("snake %c: either number every body segment or none", letter)
+	default:
+		paths := p.trace(body, segments, make([]bool, len(segments)), nil, 2)
+		switch len(paths) {
+		case 0:
+			return rules.Battlesnake{}, fmt.Errorf("snake %c: body segments don't make a single line from the head", letter)
+		case 2:
+			return rules.Battlesnake{}, fmt.Errorf("snake %c: the body could be read more than one way, number the segments or add arrows", letter)
+		}
+		body = paths[0]
+	}
+
+	for len(body) < p.length[letter] {
+		body = append(body, body[len(body)-1])
+	}
+	health, ok := p.health[letter]
+	if !ok {
+		health = 100
+	}
+	id := string(letter)
+	if name, ok := p.names[letter]; ok {
+		id = name
+	}
+	return rules.Battlesnake{ID: id, Name: id, Health: health, Body: body, Head: head, Length: int32(len(body)), Latency: "0", Squad: p.squads[letter]}, nil
+}
+
+// trace finds the ways of walking from the end of body through every unused segment, one neighbour at a time,
+// stopping once limit have been found.
+func (p *parser) trace(body []rules.Coord, segments []segment, used []bool, found [][]rules.Coord, limit int) [][]rules.Coord {
+	if len(body) == len(segments)+1 {
+		return append(found, append([]rules.Coord{}, body...))
+	}
+	end := body[len(body)-1]
+	for i, s := range segments {
+		if used[i] || !p.adjacent(end, s.coord) {
+			continue
+		}
+		if s.arrow != 0 && p.step(s.coord, s.arrow) != end {
+			continue
+		}
+		used[i] = true
+		found = p.trace(append(body, s.coord), segments, used, found, limit)
+		used[i] = false
+		if len(found) >= limit {
+			break
+		}
+	}
+	return found
+}
+
+// step returns the cell an arrow points to.
+func (p *parser) step(c rules.Coord, arrow rune) rules.Coord {
+	moves := map[rune]string{'^': rules.MoveUp, 'v': rules.MoveDown, '<': rules.MoveLeft, '>': rules.MoveRight}
+	return p.wrap(c.Neighbour(moves[arrow]))
+}
+
+func (p *parser) adjacent(a, b rules.Coord) bool {
+	for _, move := range rules.Moves {
+		if p.wrap(a.Neighbour(move)) == b {
+			return true
+		}
+	}
+	return false
+}
+
+// wrap brings a coord that has stepped off the edge back onto a wrapped board. Other boards are left alone.
+func (p *parser) wrap(c rules.Coord) rules.Coord {
+	if p.ruleset != rules.RulesetWrapped {
+		return c
+	}
+	return rules.Coord{X: (c.X + p.width) % p.width, Y: (c.Y + p.height) % p.height}
+}
+
+func isSnakeLetter(r rune) bool {
+	return r < unicode.MaxASCII && unicode.IsLetter(r) && unicode.ToUpper(r) != 'X'
+}
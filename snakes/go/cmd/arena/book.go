@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/arena"
+	"github.com/es-na-battlesnake/snakes/snakes/go/openingbook"
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// runBook fills in pathy's opening book by self-play. For each starting position, every first move the first
+// snake has is played out a number of times against copies of pathy, and the move that scores best goes in the
+// book.
+func runBook(args []string) error {
+	fs := flag.NewFlagSet("book", flag.ExitOnError)
+	pathyDir := fs.String("pathy", "snakes/go/pathy-snake", "directory of the pathy snake to build and play")
+	path := fs.String("book", "snakes/go/pathy-snake/openings.json", "book to add to, created if it doesn't exist")
+	positions := fs.Int("positions", 10, "number of starting positions to play, one per seed")
+	games := fs.Int("games", 5, "games played for each first move in each position")
+	players := fs.Int("snakes", 4, "number of snakes in each game")
+	seed := fs.Int64("seed", 1, "seed of the first starting position")
+	ruleset := fs.String("g", rules.RulesetStandard, "ruleset: standard or wrapped")
+	port := fs.Int("port", 9200, "first port to run the snakes on, one port per snake")
+	timeout := fs.Int("t", 500, "move timeout in milliseconds")
+	maxTurns := fs.Int("max-turns", 500, "stop a game as a draw after this many turns")
+	fs.Parse(args)
+
+	if *players < 1 || *games < 1 {
+		return errors.New("--snakes and --games must be at least 1")
+	}
+	book, err := readBook(*path)
+	if err != nil {
+		return err
+	}
+	binary, cleanup, err := buildPathy(*pathyDir)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// Each snake gets its own process, so copies of pathy don't share what they remember about the game.
+	snakes := make([]arena.SnakeConfig, *players)
+	for i := range snakes {
+		p := *port + i
+		url := fmt.Sprintf("http://127.0.0.1:%d/", p)
+		stop, err := startSnake(binary, url, p, "")
+		if err != nil {
+			return err
+		}
+		defer stop()
+		snakes[i] = arena.SnakeConfig{ID: fmt.Sprintf("snake-%d", i+1), Name: fmt.Sprintf("pathy-%d", i+1), URL: url}
+	}
+
+	opts := arena.Options{
+		Ruleset:  *ruleset,
+		Timeout:  time.Duration(*timeout) * time.Millisecond,
+		MaxTurns: *maxTurns,
+	}
+	for _, position := range seedRange(*seed, *positions) {
+		move, score, err := bestOpening(book, opts, snakes, position, *games)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("position %d: %s (%.2f)\n", position, move, score)
+	}
+
+	data, err := book.JSON()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\n%d positions in %s\n", len(book.Moves), *path)
+	return ioutil.WriteFile(*path, append(data, '\n'), 0644)
+}
+
+// readBook reads the book at path, or returns an empty one if there isn't one yet.
+func readBook(path string) (*openingbook.Book, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return openingbook.New(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return openingbook.Parse(data)
+}
+
+// bestOpening plays each first move the first snake has in the position the seed starts the game in, adds the
+// move with the best mean score to the book and returns it with its score. A win is worth 1 and a draw 0.5. The
+// other snakes make their own moves, and each game of a move uses a different seed for the food that spawns.
+func bestOpening(book *openingbook.Book, opts arena.Options, snakes []arena.SnakeConfig, position int64, games int) (string, float64, error) {
+	players := make([]rules.Battlesnake, len(snakes))
+	for i, snake := range snakes {
+		players[i] = rules.Battlesnake{ID: snake.ID, Name: snake.Name}
+	}
+	game := rules.Game{Ruleset: rules.Ruleset{Name: opts.Ruleset, Settings: rules.DefaultSettings()}, Map: rules.MapStandard}
+	start, err := rules.NewEngine(game, openingbook.Size, openingbook.Size, players, position)
+	if err != nil {
+		return "", 0, err
+	}
+	state, _ := start.StateFor(snakes[0].ID)
+	if !openingbook.Covers(state) {
+		return "", 0, fmt.Errorf("opening books don't cover %s games", opts.Ruleset)
+	}
+
+	runner := arena.NewRunner()
+	bestMove, bestScore := "", -1.0
+	for _, move := range rules.Moves {
+		next := state.You.Head.Neighbour(move)
+		if opts.Ruleset != rules.RulesetWrapped && (next.X < 0 || next.Y < 0 || next.X >= openingbook.Size || next.Y >= openingbook.Size) {
+			continue
+		}
+		total := 0.0
+		for i := 0; i < games; i++ {
+			o := opts
+			o.Board = &start.Board
+			o.Seed = position*int64(games) + int64(i)
+			o.Opening = map[string][]string{snakes[0].ID: {move}}
+			result, err := runner.Play(context.Background(), o, snakes)
+			if err != nil {
+				return "", 0, err
+			}
+			switch {
+			case !result.IsDraw && result.WinnerID == snakes[0].ID:
+				total++
+			case result.IsDraw || result.Snakes[0].Place == 1:
+				total += 0.5
+			}
+		}
+		if score := total / float64(games); score > bestScore {
+			bestMove, bestScore = move, score
+		}
+	}
+	return bestMove, bestScore, book.Add(state, bestMove)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/transcript"
+)
+
+// runConvert rewrites a recording or game export in the battlesnake CLI's output format.
+func runConvert(args []string) error {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	output := fs.String("output", "", "file to write, default standard output")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: arena convert [flags] <game file>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("expected one game file")
+	}
+
+	in, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	t, err := transcript.Read(in)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		fmt.Fprintf(os.Stderr, "warning: %s has no result line, the output will have an empty result\n", fs.Arg(0))
+		err = nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", fs.Arg(0), err)
+	}
+
+	if *output == "" {
+		return transcript.WriteCLI(os.Stdout, t)
+	}
+	out, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := transcript.WriteCLI(out, t); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
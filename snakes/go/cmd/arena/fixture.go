@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/fixture"
+	"github.com/es-na-battlesnake/snakes/snakes/go/transcript"
+)
+
+// runFixture turns a lost game into a regression test for the snake that lost it.
+func runFixture(args []string) error {
+	defaults := fixture.DefaultOptions()
+	fs := flag.NewFlagSet("fixture", flag.ExitOnError)
+	snake := fs.String("snake", "", "name or ID of the snake that lost")
+	name := fs.String("name", "", "name of the test function (default TestLoss<game><turn>)")
+	appendTo := fs.String("append", "", "append the test to this file instead of printing it")
+	lookahead := fs.Int("lookahead", defaults.Lookahead, "turns past the real elimination a move must survive")
+	depth := fs.Int("depth", defaults.MaxDepth, "most turns searched from any one position")
+	back := fs.Int("back", defaults.MaxTurnsBack, "how many turns before the elimination to look for the mistake")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: arena fixture --snake <name> [flags] <game file>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 || *snake == "" {
+		fs.Usage()
+		return errors.New("expected --snake and one game file")
+	}
+
+	in, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	t, err := transcript.Read(in)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("reading %s: %w", fs.Arg(0), err)
+	}
+
+	f, err := fixture.Find(t, *snake, fixture.Options{Lookahead: *lookahead, MaxDepth: *depth, MaxTurnsBack: *back})
+	if err != nil {
+		return err
+	}
+	played := f.Played
+	if played == "" {
+		played = "?"
+	}
+	fmt.Fprintf(os.Stderr, "turn %d: %s played %s and was eliminated on turn %d, %s would have survived\n",
+		f.State.Turn, f.State.You.Name, played, f.EliminatedOn, strings.Join(f.Safe, " or "))
+
+	if *name == "" {
+		*name = fixture.TestName(f)
+	}
+	if *appendTo == "" {
+		return fixture.Write(os.Stdout, f, *name)
+	}
+	out, err := os.OpenFile(*appendTo, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	if err := fixture.Write(out, f, *name); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "added %s to %s\n", *name, *appendTo)
+	return nil
+}
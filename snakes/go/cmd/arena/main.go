@@ -0,0 +1,74 @@
+// Command arena runs local Battlesnake games and tools built on top of them.
+//
+// Usage:
+//
+//	arena <command> [flags]
+//
+// Run `arena <command> -h` for the flags of each command.
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// A command is one arena subcommand. It receives the arguments after its name.
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+var commands = map[string]command{
+	"play":     {"play games against snake URLs using the local rules engine", runPlay},
+	"book":     {"add pathy-snake's best first moves to its opening book by self-play", runBook},
+	"convert":  {"write a recording or official game export in the battlesnake CLI output format", runConvert},
+	"fixture":  {"turn a lost game into a regression test for the snake that lost it", runFixture},
+	"optimise": {"tune pathy-snake cost weights by self-play against an opponent pool", runOptimise},
+	"ratings":  {"print the Elo leaderboard or rating history from a ledger", runRatings},
+	"view":     {"step through a recorded game in the terminal", runView},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "arena: unknown command %q\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "arena %s: %s\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(os.Stderr, "Usage: arena <command> [flags]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].summary)
+	}
+}
+
+// stringList is a flag that can be given more than once, like the battlesnake CLI's --name and --url.
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/arena"
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+	"github.com/es-na-battlesnake/snakes/snakes/go/tuning"
+)
+
+// pathySpace is the parameter vector the optimiser tunes. The names are the JSON keys of pathy's Config in
+// snakes/go/pathy-snake/config.go and the starting values are pathy's defaults.
+var pathySpace = tuning.Space{
+	{Name: "largerHeadCost", Min: 1, Max: 20, Start: 5},
+	{Name: "smallerHeadCost", Min: 0.1, Max: 5, Start: 1},
+	{Name: "foodCost", Min: 0.05, Max: 2, Start: 0.5},
+	{Name: "hazardCost", Min: 1, Max: 30, Start: 5},
+	{Name: "hungryHealth", Min: 10, Max: 100, Start: 85, Integer: true},
+}
+
+// runOptimise tunes pathy's cost weights by self-play against a fixed pool of opponents.
+func runOptimise(args []string) error {
+	fs := flag.NewFlagSet("optimise", flag.ExitOnError)
+	var opponents stringList
+	fs.Var(&opponents, "opponent", "an opponent as name=url, repeat for each snake in the pool")
+	pathyDir := fs.String("pathy", "snakes/go/pathy-snake", "directory of the pathy snake to build and tune")
+	out := fs.String("out", "pathy-tuned.json", "where to write the best config")
+	report := fs.String("report", "", "also write the confidence report to this file")
+	seeds := fs.Int("seeds", 10, "games per opponent used to score each candidate")
+	validate := fs.Int("validate", 30, "games per opponent on fresh seeds used for the final confidence report")
+	seed := fs.Int64("seed", 1, "first game seed; candidates all play the same seeds")
+	generations := fs.Int("generations", 10, "number of generations to run")
+	lambda := fs.Int("lambda", 4, "children per generation")
+	sigma := fs.Float64("sigma", 0.2, "starting mutation size as a fraction of each parameter's range")
+	ruleset := fs.String("g", rules.RulesetStandard, "ruleset to tune for")
+	port := fs.Int("port", 9100, "port to run candidate snakes on")
+	timeout := fs.Int("t", 500, "move timeout in milliseconds")
+	maxTurns := fs.Int("max-turns", 1000, "stop a game as a draw after this many turns")
+	fs.Parse(args)
+
+	pool, err := opponentPool(opponents)
+	if err != nil {
+		return err
+	}
+	binary, cleanup, err := buildPathy(*pathyDir)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	eval := &candidateEvaluator{
+		binary:    binary,
+		port:      *port,
+		opponents: pool,
+		opts: arena.Options{
+			Ruleset:  *ruleset,
+			Timeout:  time.Duration(*timeout) * time.Millisecond,
+			MaxTurns: *maxTurns,
+		},
+	}
+
+	trainingSeeds := seedRange(*seed, *seeds)
+	o := tuning.Optimiser{
+		Space:  pathySpace,
+		Lambda: *lambda,
+		Sigma:  *sigma,
+		Rand:   rand.New(rand.NewSource(*seed)),
+		Evaluate: func(v tuning.Vector) (float64, error) {
+			scores, err := eval.scores(v, trainingSeeds)
+			return tuning.Summarise(scores).Mean, err
+		},
+	}
+	best, fitness, err := o.Run(*generations, func(g tuning.Generation) {
+		marker := ""
+		if g.Improved {
+			marker = " (improved)"
+		}
+		fmt.Printf("generation %2d: fitness %.3f sigma %.3f %s%s\n", g.Index, g.Fitness, g.Sigma, formatVector(g.Best), marker)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writePathyConfig(*out, best); err != nil {
+		return err
+	}
+	fmt.Printf("\nBest config (training fitness %.3f) written to %s\n\n", fitness, *out)
+
+	// Check the result on games neither candidate was tuned on, so the report isn't flattered by overfitting.
+	validationSeeds := seedRange(*seed+1000000, *validate)
+	tunedScores, err := eval.scores(best, validationSeeds)
+	if err != nil {
+		return err
+	}
+	defaultScores, err := eval.scores(pathySpace.Start(), validationSeeds)
+	if err != nil {
+		return err
+	}
+	text := confidenceReport(best, tunedScores, defaultScores)
+	fmt.Print(text)
+	if *report != "" {
+		return ioutil.WriteFile(*report, []byte(text), 0644)
+	}
+	return nil
+}
+
+// candidateEvaluator plays a candidate config against every opponent in the pool on a fixed set of seeds.
+type candidateEvaluator struct {
+	binary    string
+	port      int
+	opponents []arena.SnakeConfig
+	opts      arena.Options
+}
+
+// scores returns one score per game in a stable order: for each seed, one game against each opponent.
+// A win is worth 1 and a draw 0.5. Losses earn a small bonus for turns survived, which separates candidates
+// that lose every game without changing how wins and draws compare.
+func (e *candidateEvaluator) scores(v tuning.Vector, seeds []int64) ([]float64, error) {
+	config, err := ioutil.TempFile("", "pathy-candidate-*.json")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(config.Name())
+	config.Close()
+	if err := writePathyConfig(config.Name(), v); err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/", e.port)
+	stop, err := startSnake(e.binary, url, e.port, config.Name())
+	if err != nil {
+		return nil, err
+	}
+	defer stop()
+
+	runner := arena.NewRunner()
+	var scores []float64
+	for _, seed := range seeds {
+		for _, opponent := range e.opponents {
+			opts := e.opts
+			opts.Seed = seed
+			result, err := runner.Play(context.Background(), opts, players(opts.Ruleset, arena.SnakeConfig{Name: "candidate", URL: url}, opponent))
+			if err != nil {
+				return nil, err
+			}
+			candidate := result.Snakes[0]
+			var score float64
+			switch {
+			case !result.IsDraw && result.WinnerName == "candidate":
+				score = 1
+			case result.IsDraw || candidate.Place == 1:
+				score = 0.5
+			default:
+				score = 0.01 * math.Min(float64(candidate.Survived)/float64(opts.MaxTurns), 1)
+			}
+			scores = append(scores, score)
+		}
+	}
+	return scores, nil
+}
+
+// players lines up the candidate against one opponent. Squad games are a pair of each, one squad per snake, with
+// the candidate first.
+func players(ruleset string, candidate, opponent arena.SnakeConfig) []arena.SnakeConfig {
+	if ruleset != rules.RulesetSquad {
+		return []arena.SnakeConfig{candidate, opponent}
+	}
+	candidate.Squad = "candidate"
+	opponent.Squad = "opponent"
+	return []arena.SnakeConfig{candidate, candidate, opponent, opponent}
+}
+
+func confidenceReport(best tuning.Vector, tuned, defaults []float64) string {
+	var b strings.Builder
+	t := tuning.Summarise(tuned)
+	d := tuning.Summarise(defaults)
+	c := tuning.Compare(tuned, defaults)
+	fmt.Fprintf(&b, "Confidence report (%d validation games)\n", t.N)
+	fmt.Fprintf(&b, "  tuned    %s\n", formatVector(best))
+	fmt.Fprintf(&b, "  default  %s\n", formatVector(pathySpace.Start()))
+	fmt.Fprintf(&b, "  tuned score    %.3f ± %.3f\n", t.Mean, t.CI95)
+	fmt.Fprintf(&b, "  default score  %.3f ± %.3f\n", d.Mean, d.CI95)
+	fmt.Fprintf(&b, "  difference     %+.3f ± %.3f (%d better, %d worse, %d same)\n", c.Difference.Mean, c.Difference.CI95, c.Wins, c.Losses, c.Ties)
+	if c.Better() {
+		fmt.Fprintf(&b, "  The tuned config is better than the defaults with ~95%% confidence.\n")
+	} else {
+		fmt.Fprintf(&b, "  Not enough evidence that the tuned config beats the defaults; try more --validate games.\n")
+	}
+	return b.String()
+}
+
+func writePathyConfig(path string, v tuning.Vector) error {
+	data, err := json.MarshalIndent(pathySpace.Values(v), "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func formatVector(v tuning.Vector) string {
+	parts := make([]string, len(v))
+	for i, p := range pathySpace {
+		parts[i] = fmt.Sprintf("%s=%.3g", p.Name, v[i])
+	}
+	return strings.Join(parts, " ")
+}
+
+func opponentPool(opponents []string) ([]arena.SnakeConfig, error) {
+	if len(opponents) == 0 {
+		return nil, errors.New("at least one --opponent name=url is required")
+	}
+	var pool []arena.SnakeConfig
+	for _, o := range opponents {
+		i := strings.Index(o, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("opponent %q should be name=url", o)
+		}
+		pool = append(pool, arena.SnakeConfig{Name: o[:i], URL: o[i+1:]})
+	}
+	return pool, nil
+}
+
+func seedRange(first int64, n int) []int64 {
+	seeds := make([]int64, n)
+	for i := range seeds {
+		seeds[i] = first + int64(i)
+	}
+	return seeds
+}
+
+// buildPathy compiles the snake once so every candidate starts instantly.
+func buildPathy(dir string) (string, func(), error) {
+	tmp, err := ioutil.TempDir("", "arena-optimise")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(tmp) }
+	binary := filepath.Join(tmp, "pathy-snake")
+	cmd := exec.Command("go", "build", "-o", binary, ".")
+	cmd.Dir = dir
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("building %s: %w", dir, err)
+	}
+	return binary, cleanup, nil
+}
+
+// startSnake runs a snake binary with a config and waits until it answers on url.
+func startSnake(binary, url string, port int, config string) (func(), error) {
+	cmd := exec.Command(binary)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("PORT=%d", port), "PATHY_CONFIG="+config)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	stop := func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}
+
+	client := arena.NewClient()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		_, err := client.Info(ctx, url)
+		cancel()
+		if err == nil {
+			return stop, nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	stop()
+	log.Printf("ERROR: %s did not start on %s", binary, url)
+	return nil, fmt.Errorf("snake did not start on %s", url)
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/arena"
+	"github.com/es-na-battlesnake/snakes/snakes/go/rating"
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// runPlay plays one or more games and prints a line per game in the same "winner: <name>" form the simulation
+// scripts already grep for, followed by a summary.
+func runPlay(args []string) error {
+	fs := flag.NewFlagSet("play", flag.ExitOnError)
+	var names, urls, configs, squads stringList
+	fs.Var(&names, "name", "name of a snake, repeat once per snake")
+	fs.Var(&urls, "url", "base URL of a snake, repeat once per snake")
+	fs.Var(&configs, "config", "config file a snake is running with, repeat once per snake in the same order as --url (use \"\" for none)")
+	fs.Var(&squads, "squad", "squad of a snake in squad games, repeat once per snake in the same order as --url")
+	width := fs.Int("W", 11, "board width")
+	height := fs.Int("H", 11, "board height")
+	ruleset := fs.String("g", rules.RulesetStandard, "ruleset: standard, wrapped, royale, solo, constrictor or squad")
+	gameMap := fs.String("m", rules.MapStandard, "map: standard, empty or hz_islands_bridges")
+	timeout := fs.Int("t", 500, "move timeout in milliseconds")
+	seed := fs.Int64("seed", time.Now().UnixNano(), "seed for the first game; later games use seed+1, seed+2, ...")
+	runs := fs.Int("runs", 1, "number of games to play")
+	maxTurns := fs.Int("max-turns", 2000, "stop a game as a draw after this many turns")
+	output := fs.String("output", "", "write a JSONL recording of each game to this file (numbered when runs > 1)")
+	foodSpawnChance := fs.Int("foodSpawnChance", 15, "percentage chance of spawning food each turn")
+	minimumFood := fs.Int("minimumFood", 1, "minimum food on the board")
+	hazardDamage := fs.Int("hazardDamagePerTurn", 14, "health lost per turn on a hazard")
+	shrinkEvery := fs.Int("shrinkEveryNTurns", 25, "turns between royale hazard shrinks")
+	ledgerPath := fs.String("ledger", "", "update the Elo ratings in this ledger file after every game")
+	revision := fs.String("revision", "", "git revision the snakes were built from (default: the current checkout)")
+	fs.Parse(args)
+
+	snakes, err := snakeConfigs(names, urls, squads)
+	if err != nil {
+		return err
+	}
+	var ledger *rating.Ledger
+	var identities []rating.Identity
+	if *ledgerPath != "" {
+		if ledger, err = rating.Load(*ledgerPath); err != nil {
+			return err
+		}
+		if identities, err = snakeIdentities(snakes, configs, *revision); err != nil {
+			return err
+		}
+	}
+	opts := arena.Options{
+		Width:   *width,
+		Height:  *height,
+		Ruleset: *ruleset,
+		Map:     *gameMap,
+		Settings: rules.Settings{
+			FoodSpawnChance:     int32(*foodSpawnChance),
+			MinimumFood:         int32(*minimumFood),
+			HazardDamagePerTurn: int32(*hazardDamage),
+			Royale:              rules.Royale{ShrinkEveryNTurns: int32(*shrinkEvery)},
+			Squad:               rules.DefaultSettings().Squad,
+		},
+		Timeout:  time.Duration(*timeout) * time.Millisecond,
+		MaxTurns: *maxTurns,
+	}
+
+	wins := make(map[string]int)
+	draws := 0
+	for run := 0; run < *runs; run++ {
+		opts.Seed = *seed + int64(run)
+		result, err := playOne(opts, snakes, recordingPath(*output, run, *runs))
+		if err != nil {
+			return err
+		}
+		if result.IsDraw {
+			draws++
+			fmt.Printf("game %d (seed %d): winner: draw after %d turns\n", run+1, opts.Seed, result.Turns)
+		} else {
+			wins[result.WinnerName]++
+			fmt.Printf("game %d (seed %d): winner: %s after %d turns\n", run+1, opts.Seed, result.WinnerName, result.Turns)
+		}
+		for _, snake := range result.Snakes {
+			fmt.Printf("  %-20s place %d, survived %d turns, latency mean %dms max %dms, %d timeouts %s\n",
+				snake.Name, snake.Place, snake.Survived, snake.MeanMillis, snake.MaxMillis, snake.Timeouts, snake.Cause)
+		}
+		if ledger != nil {
+			if err := recordRatings(ledger, *ledgerPath, identities, result, opts.Seed); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Println("")
+	fmt.Printf("Summary of %d games:\n", *runs)
+	for _, snake := range snakes {
+		fmt.Printf("  %-20s %d wins\n", snake.Name, wins[snake.Name])
+	}
+	fmt.Printf("  %-20s %d\n", "draws", draws)
+	return nil
+}
+
+func playOne(opts arena.Options, snakes []arena.SnakeConfig, output string) (arena.Result, error) {
+	runner := arena.NewRunner()
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return arena.Result{}, err
+		}
+		defer f.Close()
+		runner.Recorder = arena.NewRecorder(f)
+	}
+	return runner.Play(context.Background(), opts, snakes)
+}
+
+// snakeConfigs pairs up --name, --url and --squad flags. Names are optional; unnamed snakes are named after their
+// URL.
+func snakeConfigs(names, urls, squads []string) ([]arena.SnakeConfig, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("at least one --url is required")
+	}
+	if len(names) > len(urls) {
+		return nil, fmt.Errorf("got %d names for %d urls", len(names), len(urls))
+	}
+	if len(squads) > len(urls) {
+		return nil, fmt.Errorf("got %d squads for %d urls", len(squads), len(urls))
+	}
+	var snakes []arena.SnakeConfig
+	for i, url := range urls {
+		name := url
+		if i < len(names) {
+			name = names[i]
+		}
+		snake := arena.SnakeConfig{Name: name, URL: url}
+		if i < len(squads) {
+			snake.Squad = squads[i]
+		}
+		snakes = append(snakes, snake)
+	}
+	return snakes, nil
+}
+
+// recordingPath numbers the output file for each game when more than one game is played,
+// e.g. game.jsonl becomes game-1.jsonl, game-2.jsonl, ...
+func recordingPath(output string, run, runs int) string {
+	if output == "" || runs == 1 {
+		return output
+	}
+	ext := filepath.Ext(output)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(output, ext), run+1, ext)
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/arena"
+	"github.com/es-na-battlesnake/snakes/snakes/go/rating"
+	"github.com/es-na-battlesnake/snakes/snakes/go/transcript"
+)
+
+// runRatings prints the leaderboard of a ledger, or the rating history of the snakes matching --history. Game
+// files given as arguments are rated into the ledger first, so games played by CI or `battlesnake play --output`
+// count too.
+func runRatings(args []string) error {
+	fs := flag.NewFlagSet("ratings", flag.ExitOnError)
+	ledgerPath := fs.String("ledger", "ratings.json", "ledger file written by arena play --ledger")
+	history := fs.String("history", "", "show the rating history of every version of this snake name, or of one ledger key")
+	top := fs.Int("top", 0, "only show the best N entries")
+	revision := fs.String("revision", "", "git revision the snakes in the game files were built from (default: the current checkout)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: arena ratings [flags] [game files]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	ledger, err := rating.Load(*ledgerPath)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		if err := rateGames(ledger, *ledgerPath, fs.Args(), *revision); err != nil {
+			return err
+		}
+	}
+	if len(ledger.Entries) == 0 {
+		return fmt.Errorf("no ratings in %s yet, play some games with arena play --ledger %s or pass game files", *ledgerPath, *ledgerPath)
+	}
+	if *history != "" {
+		return printHistory(ledger, *history)
+	}
+
+	fmt.Printf("%4s  %-40s %7s %6s %6s\n", "rank", "snake", "rating", "games", "wins")
+	for i, entry := range ledger.Leaderboard() {
+		if *top > 0 && i >= *top {
+			break
+		}
+		fmt.Printf("%4d  %-40s %7.1f %6d %6d\n", i+1, entry.Key(), entry.Rating, entry.Games, entry.Wins)
+	}
+	return nil
+}
+
+// rateGames adds finished games from arena recordings, battlesnake CLI output or official game exports to the
+// ledger. Each snake is rated as its name at the given revision. Games already in the ledger, such as ones arena
+// play rated as it went, are skipped, so the same files can be passed again.
+func rateGames(ledger *rating.Ledger, path string, files []string, revision string) error {
+	if revision == "" {
+		var err error
+		if revision, err = gitRevision(); err != nil {
+			return fmt.Errorf("could not work out the git revision, pass --revision: %w", err)
+		}
+	}
+	for _, file := range files {
+		t, err := readGame(file)
+		if err != nil {
+			return err
+		}
+		if t.Game.ID == "" {
+			return fmt.Errorf("%s has no game ID to record it under", file)
+		}
+		if ledger.HasGame(t.Game.ID) {
+			fmt.Fprintf(os.Stderr, "skipping %s, game %s is already in the ledger\n", file, t.Game.ID)
+			continue
+		}
+		if len(t.Turns) == 0 {
+			return fmt.Errorf("%s has no turns", file)
+		}
+		places := t.Places()
+		var standings []rating.Standing
+		var squads []string
+		for _, snake := range t.Turns[0].Board.Snakes {
+			name := snake.Name
+			if name == "" {
+				name = snake.ID
+			}
+			standings = append(standings, rating.Standing{Identity: rating.Identity{Name: name, Revision: revision}, Place: places[snake.ID]})
+			squads = append(squads, snake.Squad)
+		}
+		if err := ledger.Record(t.Game.ID, time.Now().UTC(), squadStandings(standings, squads)); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if err := ledger.Save(path); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "rated game %s from %s\n", t.Game.ID, file)
+	}
+	return nil
+}
+
+// readGame reads a finished game. Unlike arena view, a game without its result line is an error, since who won
+// it isn't known.
+func readGame(path string) (transcript.Transcript, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return transcript.Transcript{}, err
+	}
+	defer f.Close()
+	t, err := transcript.Read(f)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return t, fmt.Errorf("%s has no result line, only finished games can be rated", path)
+	}
+	if err != nil {
+		return t, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return t, nil
+}
+
+func printHistory(ledger *rating.Ledger, match string) error {
+	found := false
+	for _, entry := range ledger.Leaderboard() {
+		if entry.Name != match && entry.Key() != match {
+			continue
+		}
+		found = true
+		fmt.Printf("%s (now %.1f)\n", entry.Key(), entry.Rating)
+		for _, point := range entry.History {
+			fmt.Printf("  %s  %-20s place %d/%d  %7.1f\n", point.Time.Format(time.RFC3339), point.Game, point.Place, point.Field, point.Rating)
+		}
+	}
+	if !found {
+		return fmt.Errorf("no snake called %q in the ledger", match)
+	}
+	return nil
+}
+
+// recordRatings adds a finished game to the ledger and saves it straight away, so an interrupted batch of
+// simulations still keeps every game it finished.
+func recordRatings(ledger *rating.Ledger, path string, identities []rating.Identity, result arena.Result, seed int64) error {
+	standings := make([]rating.Standing, len(result.Snakes))
+	squads := make([]string, len(result.Snakes))
+	for i, snake := range result.Snakes {
+		standings[i] = rating.Standing{Identity: identities[i], Place: snake.Place}
+		squads[i] = snake.Squad
+	}
+	if err := ledger.Record(fmt.Sprintf("arena-%d", seed), time.Now().UTC(), squadStandings(standings, squads)); err != nil {
+		return err
+	}
+	return ledger.Save(path)
+}
+
+// squadStandings rates a squad game as a game between squads, so teammates with the same identity, such as two
+// copies of one snake, count once at the squad's best place. squads holds the squad of each standing, empty
+// outside squad games.
+func squadStandings(standings []rating.Standing, squads []string) []rating.Standing {
+	var merged []rating.Standing
+	index := make(map[string]int)
+	for i, standing := range standings {
+		if squads[i] == "" {
+			merged = append(merged, standing)
+			continue
+		}
+		key := squads[i] + "/" + standing.Identity.Key()
+		if j, ok := index[key]; ok {
+			if standing.Place < merged[j].Place {
+				merged[j].Place = standing.Place
+			}
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, standing)
+	}
+	return merged
+}
+
+// snakeIdentities works out the ledger identity of each snake from its name, the git revision and the config
+// file it was given. Two snakes with the same identity can't be rated against each other, so that is an error
+// before any game is played rather than after the first, unless they are in the same squad.
+func snakeIdentities(snakes []arena.SnakeConfig, configs []string, revision string) ([]rating.Identity, error) {
+	if len(configs) > len(snakes) {
+		return nil, fmt.Errorf("got %d configs for %d snakes", len(configs), len(snakes))
+	}
+	if revision == "" {
+		var err error
+		if revision, err = gitRevision(); err != nil {
+			return nil, fmt.Errorf("could not work out the git revision, pass --revision: %w", err)
+		}
+	}
+	identities := make([]rating.Identity, len(snakes))
+	for i, snake := range snakes {
+		identities[i] = rating.Identity{Name: snake.Name, Revision: revision}
+		if i < len(configs) && configs[i] != "" {
+			data, err := ioutil.ReadFile(configs[i])
+			if err != nil {
+				return nil, err
+			}
+			identities[i].ConfigHash = rating.ConfigHash(data)
+		}
+		for j, earlier := range identities[:i] {
+			if earlier == identities[i] && (snake.Squad == "" || snake.Squad != snakes[j].Squad) {
+				return nil, fmt.Errorf("two snakes are both %s, give them different names or configs to rate them", earlier.Key())
+			}
+		}
+	}
+	return identities, nil
+}
+
+// gitRevision returns the short hash of HEAD, with a -dirty suffix when there are uncommitted changes.
+func gitRevision() (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return "", err
+	}
+	revision := strings.TrimSpace(string(out))
+	if revision == "" {
+		return "", errors.New("git returned no revision")
+	}
+	status, err := exec.Command("git", "status", "--porcelain", "--untracked-files=no").Output()
+	if err != nil {
+		return "", err
+	}
+	if len(strings.TrimSpace(string(status))) > 0 {
+		revision += "-dirty"
+	}
+	return revision, nil
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/replay"
+	"github.com/es-na-battlesnake/snakes/snakes/go/transcript"
+)
+
+// runView replays a recorded game in the terminal.
+func runView(args []string) error {
+	fs := flag.NewFlagSet("view", flag.ExitOnError)
+	turn := fs.Int("turn", -1, "print this turn and exit instead of starting the interactive viewer")
+	all := fs.Bool("all", false, "print every turn one after another and exit")
+	noColour := fs.Bool("no-color", false, "draw without ANSI colours")
+	delay := fs.Duration("delay", 200*time.Millisecond, "time between turns while playing")
+	play := fs.Bool("play", false, "start playing straight away")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: arena view [flags] <game file>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("expected one game file")
+	}
+
+	game, err := loadReplay(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if len(game.Turns) == 0 {
+		return fmt.Errorf("%s has no turns", fs.Arg(0))
+	}
+	opts := replay.RenderOptions{Colour: !*noColour && isTerminal(os.Stdout)}
+
+	switch {
+	case *all:
+		for i := range game.Turns {
+			replay.Render(os.Stdout, &game, i, opts)
+			fmt.Println()
+		}
+		return nil
+	case *turn >= 0:
+		v := replay.Viewer{Game: &game}
+		if !v.JumpTo(*turn) {
+			return fmt.Errorf("turn %d is not in the recording", *turn)
+		}
+		replay.Render(os.Stdout, &game, v.Index, opts)
+		return nil
+	}
+	return interactive(&game, opts, *delay, *play)
+}
+
+// loadReplay reads an arena recording, battlesnake CLI output or official game export. A game that was cut short,
+// for example because arena was interrupted, is still worth watching, so a missing result line is only a warning.
+func loadReplay(path string) (replay.Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return replay.Game{}, err
+	}
+	defer f.Close()
+	t, err := transcript.Read(f)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		fmt.Fprintf(os.Stderr, "warning: %s has no result line, showing the %d turns it has\n", path, len(t.Turns))
+		err = nil
+	}
+	if err != nil {
+		return replay.Game{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return replay.FromTranscript(t), nil
+}
+
+// interactive runs the viewer with the terminal in raw mode so single key presses are read straight away.
+func interactive(game *replay.Game, opts replay.RenderOptions, delay time.Duration, play bool) error {
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return fmt.Errorf("the interactive viewer needs a terminal, use --turn or --all instead: %w", err)
+	}
+	defer tty.Close()
+	restore, err := rawMode(tty)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	keys := make(chan []byte)
+	go func() {
+		buf := make([]byte, 16)
+		for {
+			n, err := tty.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- append([]byte{}, buf[:n]...)
+		}
+	}()
+	ticker := time.NewTicker(delay)
+	defer ticker.Stop()
+
+	v := replay.Viewer{Game: game, Playing: play}
+	jumping := false
+	jump := ""
+	status := ""
+	for {
+		draw(game, v.Index, opts, jumping, jump, status)
+		status = ""
+		select {
+		case <-ticker.C:
+			if !v.Playing {
+				continue
+			}
+			v.Tick()
+		case input, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			if jumping {
+				jumping, jump, status = readJump(&v, input, jump)
+				continue
+			}
+			for _, action := range replay.ParseKeys(input) {
+				if action == replay.ActionJump {
+					v.Playing = false
+					jumping = true
+					jump = ""
+					continue
+				}
+				if !v.Do(action) {
+					fmt.Print("\r\n")
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// readJump collects the digits of a turn number until enter is pressed, or gives up on escape.
+func readJump(v *replay.Viewer, input []byte, jump string) (bool, string, string) {
+	for _, b := range input {
+		switch {
+		case b >= '0' && b <= '9':
+			jump += string(b)
+		case b == 127 || b == 8:
+			if len(jump) > 0 {
+				jump = jump[:len(jump)-1]
+			}
+		case b == '\r' || b == '\n':
+			turn, err := strconv.Atoi(jump)
+			if err != nil {
+				return false, "", ""
+			}
+			if !v.JumpTo(turn) {
+				return false, "", fmt.Sprintf("no turn %d, showing the closest", turn)
+			}
+			return false, "", ""
+		case b == 0x1b || b == 'q':
+			return false, "", ""
+		}
+	}
+	return true, jump, ""
+}
+
+func draw(game *replay.Game, index int, opts replay.RenderOptions, jumping bool, jump, status string) {
+	var buf bytes.Buffer
+	replay.Render(&buf, game, index, opts)
+	fmt.Fprintln(&buf)
+	switch {
+	case jumping:
+		fmt.Fprintf(&buf, "jump to turn: %s", jump)
+	case status != "":
+		fmt.Fprintln(&buf, status)
+		fmt.Fprint(&buf, replay.Help)
+	default:
+		fmt.Fprint(&buf, replay.Help)
+	}
+	// Raw mode turns off the terminal's newline translation, so every line needs its own carriage return.
+	fmt.Print("\033[H\033[2J" + strings.ReplaceAll(buf.String(), "\n", "\r\n"))
+}
+
+// rawMode switches the terminal to raw mode with stty and returns a function that puts it back.
+func rawMode(tty *os.File) (func(), error) {
+	saved, err := stty(tty, "-g")
+	if err != nil {
+		return nil, fmt.Errorf("reading terminal settings: %w", err)
+	}
+	if _, err := stty(tty, "raw", "-echo"); err != nil {
+		return nil, fmt.Errorf("setting raw mode: %w", err)
+	}
+	fmt.Print("\033[?25l")
+	return func() {
+		fmt.Print("\033[?25h")
+		stty(tty, strings.TrimSpace(saved))
+	}, nil
+}
+
+func stty(tty *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = tty
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,165 @@
+/*
+Package conformance is a table of positions that every snake should get right, grouped by capability, and a
+runner that plays a snake through them. A snake runs the whole suite from its tests with one line, converting
+the state to its own types and back:
+
+	func TestConformance(t *testing.T) {
+		conformance.Run(t, conformance.StrategyFunc(func(state rules.GameState) string {
+			return move(fromRules(t, state)).Move
+		}), conformance.BasicSafety)
+	}
+
+Scenarios in the capabilities passed to Run must pass. The rest are still played and show up in the scorecard
+that Run logs (see it with go test -v), so a new snake can see what it can already do and a capability can be
+made required once it passes.
+*/
+package conformance
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/asciiboard"
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// Strategy is anything that can choose a move for a game state.
+type Strategy interface {
+	Move(state rules.GameState) string
+}
+
+// StrategyFunc lets a plain function be used as a Strategy.
+type StrategyFunc func(state rules.GameState) string
+
+// Move calls f.
+func (f StrategyFunc) Move(state rules.GameState) string {
+	return f(state)
+}
+
+// Seeds is how many games each scenario is played as. Snakes seed their random choices from the game ID, so
+// every game gets a different ID and the same IDs are used on every run.
+const Seeds = 100
+
+// Result is how a strategy did in one scenario.
+type Result struct {
+	Scenario Scenario
+	Played   int
+	// Failed counts the games where the move was forbidden, not expected, or the strategy panicked.
+	Failed int
+	// Example is the first bad move, or the panic.
+	Example string
+}
+
+// Passed reports whether every game of the scenario went right.
+func (r Result) Passed() bool {
+	return r.Failed == 0
+}
+
+// Play runs a strategy through one scenario.
+func Play(s Strategy, scenario Scenario) Result {
+	state := asciiboard.MustParse(scenario.Board)
+	result := Result{Scenario: scenario}
+	for seed := 0; seed < Seeds; seed++ {
+		state.Game.ID = fmt.Sprintf("conformance-%s-%d", scenario.Name, seed)
+		result.Played++
+		if problem := playOnce(s, state, scenario); problem != "" {
+			result.Failed++
+			if result.Example == "" {
+				result.Example = problem
+			}
+		}
+	}
+	return result
+}
+
+// playOnce returns what went wrong with one game, or "" if nothing did.
+func playOnce(s Strategy, state rules.GameState, scenario Scenario) (problem string) {
+	defer func() {
+		if r := recover(); r != nil {
+			problem = fmt.Sprintf("panic: %v", r)
+		}
+	}()
+	move := s.Move(state)
+	if !scenario.allows(move) {
+		return fmt.Sprintf("moved %q", move)
+	}
+	return ""
+}
+
+// Scorecard is how a strategy did in every scenario, in table order.
+type Scorecard []Result
+
+// Score plays a strategy through every scenario.
+func Score(s Strategy) Scorecard {
+	var card Scorecard
+	for _, scenario := range Scenarios {
+		card = append(card, Play(s, scenario))
+	}
+	return card
+}
+
+// Passed returns how many scenarios of a capability passed, out of how many there are.
+func (card Scorecard) Passed(capability Capability) (int, int) {
+	passed, total := 0, 0
+	for _, r := range card {
+		if r.Scenario.Capability != capability {
+			continue
+		}
+		total++
+		if r.Passed() {
+			passed++
+		}
+	}
+	return passed, total
+}
+
+// String lists each capability with its score and the scenarios that failed.
+func (card Scorecard) String() string {
+	var b strings.Builder
+	for _, capability := range Capabilities {
+		passed, total := card.Passed(capability)
+		fmt.Fprintf(&b, "%-14s %d/%d", capability, passed, total)
+		var failed []string
+		for _, r := range card {
+			if r.Scenario.Capability == capability && !r.Passed() {
+				failed = append(failed, r.Scenario.Name)
+			}
+		}
+		sort.Strings(failed)
+		if len(failed) > 0 {
+			fmt.Fprintf(&b, "  failed: %s", strings.Join(failed, ", "))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Run plays a strategy through every scenario as a subtest. Scenarios in the required capabilities fail the
+// test when they go wrong; the others are only reported. The scorecard is logged at the end.
+func Run(t *testing.T, s Strategy, required ...Capability) {
+	t.Helper()
+	must := make(map[Capability]bool)
+	for _, capability := range required {
+		must[capability] = true
+	}
+	var card Scorecard
+	for _, scenario := range Scenarios {
+		scenario := scenario
+		t.Run(scenario.Name, func(t *testing.T) {
+			r := Play(s, scenario)
+			card = append(card, r)
+			if r.Passed() {
+				return
+			}
+			report := fmt.Sprintf("%s: %s in %d of %d games, %s\n%s", scenario.Capability, r.Example, r.Failed, r.Played, scenario.describe(), asciiboard.Format(asciiboard.MustParse(scenario.Board)))
+			if must[scenario.Capability] {
+				t.Error(report)
+			} else {
+				t.Log("not required, " + report)
+			}
+		})
+	}
+	t.Logf("conformance scorecard:\n%s", card)
+}
@@ -0,0 +1,77 @@
+package conformance
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/asciiboard"
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// Test that every scenario parses and has at least one acceptable move that is also safe.
+func TestScenarios(t *testing.T) {
+	names := make(map[string]bool)
+	for _, s := range Scenarios {
+		if names[s.Name] {
+			t.Errorf("%s: duplicate name", s.Name)
+		}
+		names[s.Name] = true
+		state, err := asciiboard.Parse(s.Board)
+		if err != nil {
+			t.Errorf("%s: %v", s.Name, err)
+			continue
+		}
+		if len(state.You.Body) == 0 {
+			t.Errorf("%s: no snake to move", s.Name)
+		}
+		acceptable := 0
+		for _, move := range rules.Moves {
+			if s.allows(move) {
+				acceptable++
+			}
+		}
+		if acceptable == 0 {
+			t.Errorf("%s: no move is acceptable", s.Name)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	// Arrange
+	// Picks the first acceptable move, so passes everything.
+	perfect := StrategyFunc(func(state rules.GameState) string {
+		for _, s := range Scenarios {
+			if strings.HasPrefix(state.Game.ID, "conformance-"+s.Name+"-") {
+				for _, move := range rules.Moves {
+					if s.allows(move) {
+						return move
+					}
+				}
+			}
+		}
+		return ""
+	})
+	panics := StrategyFunc(func(state rules.GameState) string {
+		panic("no moves today")
+	})
+
+	// Act
+	good := Score(perfect)
+	bad := Score(panics)
+
+	// Assert
+	for _, capability := range Capabilities {
+		if passed, total := good.Passed(capability); passed != total || total == 0 {
+			t.Errorf("%s: expected a perfect strategy to pass all scenarios, got %d/%d", capability, passed, total)
+		}
+		if passed, _ := bad.Passed(capability); passed != 0 {
+			t.Errorf("%s: expected a panicking strategy to pass nothing, got %d", capability, passed)
+		}
+	}
+	if bad[0].Failed != Seeds || bad[0].Example != "panic: no moves today" {
+		t.Errorf("unexpected result %+v", bad[0])
+	}
+	if text := bad.String(); !strings.Contains(text, "basic safety   0/6  failed: moving-tail, neck,") {
+		t.Errorf("unexpected scorecard:\n%s", text)
+	}
+}
@@ -0,0 +1,408 @@
+package conformance
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// Capability is a group of scenarios that test one thing a snake can do.
+type Capability string
+
+const (
+	// BasicSafety is not moving onto your neck, into walls or into bodies, and taking the only way out.
+	BasicSafety Capability = "basic safety"
+	// WrappedSeams is knowing the edges of a wrapped board join up, both as a danger and as a way out.
+	WrappedSeams Capability = "wrapped seams"
+	// Hazards is staying out of hazards when there is somewhere else, and preferring them to walls.
+	Hazards Capability = "hazards"
+	// HeadToHead is not moving where a snake at least as long as you can move too.
+	HeadToHead Capability = "head-to-head"
+	// Traps is not moving into a pocket you can't get back out of.
+	Traps Capability = "traps"
+)
+
+// Capabilities lists every capability in the order scorecards show them.
+var Capabilities = []Capability{BasicSafety, WrappedSeams, Hazards, HeadToHead, Traps}
+
+// Scenario is one position and what a snake should do in it. The board is in the snakes/go/asciiboard format
+// and the snake to move is A unless the board says otherwise.
+type Scenario struct {
+	Name        string
+	Capability  Capability
+	Description string
+	Board       string
+	// Expected, if not empty, lists the only acceptable moves.
+	Expected []string
+	// Forbidden lists moves that are never acceptable.
+	Forbidden []string
+}
+
+func (s Scenario) allows(move string) bool {
+	if !rules.IsValidMove(move) {
+		return false
+	}
+	for _, m := range s.Forbidden {
+		if m == move {
+			return false
+		}
+	}
+	if len(s.Expected) == 0 {
+		return true
+	}
+	for _, m := range s.Expected {
+		if m == move {
+			return true
+		}
+	}
+	return false
+}
+
+func (s Scenario) describe() string {
+	var parts []string
+	if len(s.Expected) > 0 {
+		parts = append(parts, "expected "+strings.Join(s.Expected, " or "))
+	}
+	if len(s.Forbidden) > 0 {
+		parts = append(parts, "never "+strings.Join(s.Forbidden, " or "))
+	}
+	return fmt.Sprintf("%s (%s)", s.Description, strings.Join(parts, ", "))
+}
+
+// Scenarios is the conformance table, grouped by capability.
+var Scenarios = []Scenario{
+	{
+		Name:        "neck",
+		Capability:  BasicSafety,
+		Description: "don't move back onto your own neck",
+		Board: `
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			a a A . . . . . . . .
+		`,
+		Forbidden: []string{rules.MoveLeft},
+	},
+	{
+		Name:        "own-body",
+		Capability:  BasicSafety,
+		Description: "don't move into your own body",
+		Board: `
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . a . . . . . . .
+			. . A a . . . . . . .
+			. . a a . . . . . . .
+		`,
+		Forbidden: []string{rules.MoveRight},
+	},
+	{
+		Name:        "other-body",
+		Capability:  BasicSafety,
+		Description: "don't move into another snake's body",
+		Board: `
+			. . . . . . .
+			. . . . . . .
+			. . . b b b .
+			a a A b . b .
+			. . . B . b .
+			. . . . . . .
+			. . . . . . .
+		`,
+		Forbidden: []string{rules.MoveRight},
+	},
+	{
+		Name:        "wall",
+		Capability:  BasicSafety,
+		Description: "don't move off the edge of a board that doesn't wrap",
+		Board: `
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . a a a A
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+		`,
+		Forbidden: []string{rules.MoveRight},
+	},
+	{
+		Name:        "only-move",
+		Capability:  BasicSafety,
+		Description: "take the only way out of a corner",
+		Board: `
+			A a a . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+		`,
+		Expected: []string{rules.MoveDown},
+	},
+	{
+		Name:        "moving-tail",
+		Capability:  BasicSafety,
+		Description: "follow your own tail when it is the only free cell, since it moves out of the way",
+		Board: `
+			turn: 10
+
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			a3 a2 . . . . . . . . .
+			A a1 . . . . . . . . .
+		`,
+		Expected: []string{rules.MoveUp},
+	},
+	{
+		Name:        "seam-own-body",
+		Capability:  WrappedSeams,
+		Description: "don't wrap across the edge into your own body",
+		Board: `
+			ruleset: wrapped
+
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			a . . . . . . . . . .
+			a . . . . . . . . . A
+			a . . . . . . . . . a
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+		`,
+		Forbidden: []string{rules.MoveRight},
+	},
+	{
+		Name:        "seam-other-body",
+		Capability:  WrappedSeams,
+		Description: "don't wrap across the edge into another snake",
+		Board: `
+			ruleset: wrapped
+
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			b . . . . . . . . . .
+			b . . . . . . . . . .
+			b . . . . . . . . . .
+			B . . . . . . a a a A
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+		`,
+		Forbidden: []string{rules.MoveRight},
+	},
+	{
+		Name:        "seam-escape",
+		Capability:  WrappedSeams,
+		Description: "wrap across the edge when that is the only way out",
+		Board: `
+			ruleset: wrapped
+
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . a a
+			. . . . . . . . . a A
+			. . . . . . . . . a a
+			. . . . . . . . . . a
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+		`,
+		Expected: []string{rules.MoveRight},
+	},
+	{
+		Name:        "seam-corner",
+		Capability:  WrappedSeams,
+		Description: "don't wrap into a corner pocket closed off on the far side of the board",
+		Board: `
+			ruleset: wrapped
+
+			. . . . . . . . . c c
+			. . . . . . . . . . c
+			. . . . . . . . . . C
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . b
+			. . . . . . . . . b b
+			A a a a . . . . B b .
+		`,
+		Forbidden: []string{rules.MoveLeft},
+	},
+	{
+		Name:        "hazard-sides",
+		Capability:  Hazards,
+		Description: "stay out of hazards when there is a clear cell",
+		Board: `
+			ruleset: royale
+
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . x A x . . . .
+			. . . . . a . . . . .
+			. . . . . a . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+		`,
+		Expected: []string{rules.MoveUp},
+	},
+	{
+		Name:        "hazard-over-wall",
+		Capability:  Hazards,
+		Description: "move into a hazard rather than a wall",
+		Board: `
+			ruleset: royale
+
+			A x . . . . . . . . .
+			a . . . . . . . . . .
+			a . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+		`,
+		Expected: []string{rules.MoveRight},
+	},
+	{
+		Name:        "longer-head",
+		Capability:  HeadToHead,
+		Description: "don't move next to the head of a longer snake",
+		Board: `
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . B . . . .
+			. . . . . A b . . . .
+			. . . . . a b . . . .
+			. . . . . a b b . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+		`,
+		Forbidden: []string{rules.MoveUp},
+	},
+	{
+		Name:        "equal-head",
+		Capability:  HeadToHead,
+		Description: "don't move next to the head of a snake as long as you, since a tie kills both",
+		Board: `
+			. . . . . . . . . . .
+			. . . . . b . . . . .
+			. . . . . b . . . . .
+			. . . . . B . . . . .
+			. . . . . . . . . . .
+			. . . . . A . . . . .
+			. . . . . a . . . . .
+			. . . . . a . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+		`,
+		Forbidden: []string{rules.MoveUp},
+	},
+	{
+		Name:        "own-pocket",
+		Capability:  Traps,
+		Description: "don't move into a pocket closed off by your own body",
+		Board: `
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			a5 a6 a7 . . . . . . . .
+			a4 . A . . . . . . . .
+			a3 a2 a1 . . . . . . . .
+		`,
+		Forbidden: []string{rules.MoveLeft},
+	},
+	{
+		Name:        "other-pocket",
+		Capability:  Traps,
+		Description: "don't move into a pocket closed off by another snake",
+		Board: `
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. b b . . . . . . . .
+			A . b . . . . . . . .
+			a b b . . . . . . . .
+			a B . . . . . . . . .
+		`,
+		Forbidden: []string{rules.MoveRight},
+	},
+	{
+		Name:        "corner",
+		Capability:  Traps,
+		Description: "don't move into a corner your body has closed off",
+		Board: `
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			. . . . . . . . . . .
+			A a . . . . . . . . .
+			. a a a . . . . . . .
+		`,
+		Forbidden: []string{rules.MoveDown},
+	},
+}
@@ -0,0 +1,194 @@
+/*
+Package fixture turns lost games into regression tests. Find replays a transcript with the rules engine to find
+the last turn on which the losing snake still had a way to survive, and Write emits a Go test for that position
+in the same style as the hand-written tests in pathy-snake's logic_test.go.
+*/
+package fixture
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+	"github.com/es-na-battlesnake/snakes/snakes/go/transcript"
+)
+
+// Options controls how hard Find searches.
+type Options struct {
+	// Lookahead is how many turns past its actual elimination a move must keep the snake alive to count as
+	// survivable.
+	Lookahead int
+	// MaxDepth caps the number of turns searched from any one position, since every extra turn multiplies the
+	// work by up to four.
+	MaxDepth int
+	// MaxTurnsBack is how many turns before the elimination Find looks for a mistake.
+	MaxTurnsBack int
+}
+
+// DefaultOptions are the options the arena command uses.
+func DefaultOptions() Options {
+	return Options{Lookahead: 3, MaxDepth: 7, MaxTurnsBack: 20}
+}
+
+// Fixture is a position from a lost game where the snake could have survived but didn't.
+type Fixture struct {
+	GameID string
+	// State is the request the snake was sent on the turn it went wrong.
+	State rules.GameState
+	// Played is the move it made, empty if the transcript doesn't say. battlesnake CLI files don't for a snake
+	// eliminated on the turn, whose move must then have been one of Fatal.
+	Played string
+	// Safe are the moves that would have kept it alive and Fatal the ones that wouldn't.
+	Safe  []string
+	Fatal []string
+	// EliminatedOn is the turn the snake was eliminated on and Cause why, if known.
+	EliminatedOn int
+	Cause        string
+}
+
+// ErrSurvived is returned by Find when the snake was still on the board at the end of the transcript.
+var ErrSurvived = errors.New("the snake was not eliminated")
+
+// ErrNoEscape is returned by Find when no searched turn had a survivable move the snake didn't take.
+var ErrNoEscape = errors.New("no survivable move found before the elimination")
+
+// Find looks for the last turn before snake was eliminated on which one of its moves would have kept it alive
+// and the move it made wouldn't. snake is a snake ID or name.
+//
+// Moves are checked by brute force: every line of the snake's own moves is tried while the other snakes repeat
+// the moves they made in the game. A move is survivable if some line keeps the snake alive until Lookahead
+// turns after it was really eliminated, or until the game ends with it still on the board. No food is spawned
+// while searching, as it would be different in every line anyway.
+func Find(t transcript.Transcript, snake string, opts Options) (Fixture, error) {
+	id, err := snakeID(t, snake)
+	if err != nil {
+		return Fixture{}, err
+	}
+	last := -1
+	for i, turn := range t.Turns {
+		if onBoard(turn.Board, id) {
+			last = i
+		}
+	}
+	if last == len(t.Turns)-1 {
+		return Fixture{}, ErrSurvived
+	}
+	eliminatedOn := t.Turns[last+1].Turn
+	cause := ""
+	for _, elimination := range t.Turns[last].Eliminated {
+		if elimination.ID == id {
+			cause = elimination.Cause
+		}
+	}
+
+	game := t.Game
+	if game.Ruleset.Name == "" {
+		game.Ruleset.Name = rules.RulesetStandard
+	}
+	// The map only decides where food and hazards spawn, and searching spawns neither.
+	game.Map = rules.MapEmpty
+	game.Ruleset.Settings.FoodSpawnChance = 0
+	game.Ruleset.Settings.MinimumFood = 0
+
+	s := search{id: id, moves: make(map[int]map[string]string)}
+	for _, turn := range t.Turns {
+		s.moves[turn.Turn] = turn.Moves
+	}
+	for i := last; i >= 0 && i >= last-opts.MaxTurnsBack; i-- {
+		turn := t.Turns[i]
+		if !onBoard(turn.Board, id) {
+			continue
+		}
+		e, err := rules.ResumeEngine(game, turn.Turn, turn.Board, int64(turn.Turn))
+		if err != nil {
+			return Fixture{}, err
+		}
+		target := eliminatedOn + opts.Lookahead
+		if target > turn.Turn+opts.MaxDepth {
+			target = turn.Turn + opts.MaxDepth
+		}
+
+		var safe, fatal []string
+		for _, move := range rules.Moves {
+			if s.survives(e, move, target) {
+				safe = append(safe, move)
+			} else {
+				fatal = append(fatal, move)
+			}
+		}
+		played := turn.Moves[id]
+		if len(safe) == 0 || contains(safe, played) {
+			continue
+		}
+		state, _ := rules.StateFor(t.Game, turn.Turn, turn.Board, id)
+		return Fixture{
+			GameID:       t.Game.ID,
+			State:        state,
+			Played:       played,
+			Safe:         safe,
+			Fatal:        fatal,
+			EliminatedOn: eliminatedOn,
+			Cause:        cause,
+		}, nil
+	}
+	return Fixture{}, ErrNoEscape
+}
+
+type search struct {
+	id    string
+	moves map[int]map[string]string
+}
+
+// survives reports whether the snake can make move from e and stay alive until target.
+func (s *search) survives(e *rules.Engine, move string, target int) bool {
+	moves := make(map[string]string)
+	for id, m := range s.moves[e.Turn] {
+		moves[id] = m
+	}
+	moves[s.id] = move
+
+	next := e.Clone()
+	next.Step(moves)
+	if !onBoard(next.Board, s.id) {
+		return false
+	}
+	if next.Turn >= target || next.IsOver() {
+		return true
+	}
+	for _, m := range rules.Moves {
+		if s.survives(next, m, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// snakeID finds the ID of the snake with the given ID or name.
+func snakeID(t transcript.Transcript, snake string) (string, error) {
+	for _, turn := range t.Turns {
+		for _, s := range turn.Board.Snakes {
+			if s.ID == snake || s.Name == snake {
+				return s.ID, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no snake %q in the game", snake)
+}
+
+func onBoard(board rules.Board, id string) bool {
+	for _, snake := range board.Snakes {
+		if snake.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(moves []string, move string) bool {
+	for _, m := range moves {
+		if m == move {
+			return true
+		}
+	}
+	return false
+}
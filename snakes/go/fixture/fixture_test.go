@@ -0,0 +1,152 @@
+package fixture
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+	"github.com/es-na-battlesnake/snakes/snakes/go/transcript"
+)
+
+// cornerGame is a game where "me" walks into the bottom left corner and then into the wall, when it could have
+// turned up.
+func cornerGame() transcript.Transcript {
+	snake := func(id string, body ...rules.Coord) rules.Battlesnake {
+		return rules.Battlesnake{ID: id, Name: id, Health: 90, Body: body, Head: body[0], Length: int32(len(body))}
+	}
+	board := func(snakes ...rules.Battlesnake) rules.Board {
+		return rules.Board{Width: 7, Height: 7, Food: []rules.Coord{}, Hazards: []rules.Coord{}, Snakes: snakes}
+	}
+	return transcript.Transcript{
+		Game: rules.Game{ID: "corner-1", Ruleset: rules.Ruleset{Name: rules.RulesetStandard}, Map: rules.MapStandard},
+		Turns: []transcript.Turn{
+			{
+				Turn:  0,
+				Board: board(snake("me", rules.Coord{X: 1, Y: 0}, rules.Coord{X: 2, Y: 0}, rules.Coord{X: 3, Y: 0}), snake("other", rules.Coord{X: 5, Y: 5}, rules.Coord{X: 6, Y: 5}, rules.Coord{X: 6, Y: 4})),
+				Moves: map[string]string{"me": rules.MoveLeft, "other": rules.MoveLeft},
+			},
+			{
+				Turn:       1,
+				Board:      board(snake("me", rules.Coord{X: 0, Y: 0}, rules.Coord{X: 1, Y: 0}, rules.Coord{X: 2, Y: 0}), snake("other", rules.Coord{X: 4, Y: 5}, rules.Coord{X: 5, Y: 5}, rules.Coord{X: 6, Y: 5})),
+				Moves:      map[string]string{"me": rules.MoveDown, "other": rules.MoveLeft},
+				Eliminated: []rules.Elimination{{ID: "me", Cause: rules.EliminatedByOutOfBounds, Turn: 2}},
+			},
+			{
+				Turn:  2,
+				Board: board(snake("other", rules.Coord{X: 3, Y: 5}, rules.Coord{X: 4, Y: 5}, rules.Coord{X: 5, Y: 5})),
+			},
+		},
+		Result: transcript.Result{WinnerID: "other", WinnerName: "other"},
+	}
+}
+
+func TestFind(t *testing.T) {
+	// Act
+	f, err := Find(cornerGame(), "me", DefaultOptions())
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.State.Turn != 1 || f.Played != rules.MoveDown || f.EliminatedOn != 2 || f.Cause != rules.EliminatedByOutOfBounds {
+		t.Errorf("unexpected fixture %+v", f)
+	}
+	if len(f.Safe) != 1 || f.Safe[0] != rules.MoveUp {
+		t.Errorf("expected only up to be safe, got %v", f.Safe)
+	}
+	if f.State.You.ID != "me" {
+		t.Errorf("expected the state to be from me's point of view, got %s", f.State.You.ID)
+	}
+}
+
+func TestFindWinner(t *testing.T) {
+	// Act
+	_, err := Find(cornerGame(), "other", DefaultOptions())
+
+	// Assert
+	if err != ErrSurvived {
+		t.Errorf("expected ErrSurvived, got %v", err)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	// Arrange
+	f, err := Find(cornerGame(), "me", DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+
+	// Act
+	err = Write(&out, f, TestName(f))
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"func TestLossCorner1Turn1(t *testing.T) {",
+		"// Regression test from game corner-1, turn 1: me played down and was eliminated by wall-collision on turn 2.",
+		"state := parseBoard(t, `",
+		"\t\tmap: standard\n",
+		"\t\tA a< a< . . . .\n",
+		"nextMove := move(state)",
+		`if nextMove.Move == "down" || nextMove.Move == "left" || nextMove.Move == "right" {`,
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, out.String())
+		}
+	}
+}
+
+// Test that a snake eliminated on the turn still gets a fixture from a battlesnake CLI file, which doesn't record
+// its last move.
+func TestWriteFromCLI(t *testing.T) {
+	// Arrange
+	var file bytes.Buffer
+	if err := transcript.WriteCLI(&file, cornerGame()); err != nil {
+		t.Fatal(err)
+	}
+	game, err := transcript.ReadCLI(&file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := Find(game, "me", DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+
+	// Act
+	err = Write(&out, f, TestName(f))
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.State.Turn != 1 || f.Played != "" {
+		t.Errorf("unexpected fixture %+v", f)
+	}
+	if expected := "me played one of down, left, right and was eliminated on turn 2."; !strings.Contains(out.String(), expected) {
+		t.Errorf("expected %q in:\n%s", expected, out.String())
+	}
+}
+
+func TestWriteNoFatal(t *testing.T) {
+	// Arrange
+	f, err := Find(cornerGame(), "me", DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Fatal = nil
+	var out bytes.Buffer
+
+	// Act
+	err = Write(&out, f, TestName(f))
+
+	// Assert
+	if err != ErrNoEscape || out.Len() != 0 {
+		t.Errorf("expected ErrNoEscape and no output, got %v and:\n%s", err, out.String())
+	}
+}
@@ -0,0 +1,88 @@
+package fixture
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/asciiboard"
+)
+
+// TestName makes a test function name from the game ID and turn, like TestLossArena12Turn57.
+func TestName(f Fixture) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range f.GameID {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+		if b.Len() >= 16 {
+			break
+		}
+	}
+	return fmt.Sprintf("TestLoss%sTurn%d", b.String(), f.State.Turn)
+}
+
+// Write writes f as a Go test function called name. The position is drawn with asciiboard.Format and the test
+// uses the parseBoard and move functions from pathy-snake's tests, so the output is meant to be appended to
+// pathy-snake's logic_test.go. A fixture with no fatal moves has nothing to assert and gets ErrNoEscape.
+func Write(w io.Writer, f Fixture, name string) error {
+	if len(f.Fatal) == 0 {
+		return ErrNoEscape
+	}
+	board := asciiboard.Format(f.State)
+	if strings.Contains(board, "`") {
+		return fmt.Errorf("can't write a board with a backquote in it:\n%s", board)
+	}
+
+	var b bytes.Buffer
+	played := f.Played
+	if played == "" {
+		played = "one of " + strings.Join(f.Fatal, ", ")
+	}
+	fmt.Fprintf(&b, "\n// Regression test from game %s, turn %d: %s played %s", f.GameID, f.State.Turn, f.State.You.Name, played)
+	if f.Cause != "" {
+		fmt.Fprintf(&b, " and was eliminated by %s on turn %d", f.Cause, f.EliminatedOn)
+	} else {
+		fmt.Fprintf(&b, " and was eliminated on turn %d", f.EliminatedOn)
+	}
+	fmt.Fprintf(&b, ".\n// Only %s could have survived. Generated by `arena fixture`.\n", strings.Join(f.Safe, " or "))
+	fmt.Fprintf(&b, "func %s(t *testing.T) {\n", name)
+	fmt.Fprintf(&b, "// Arrange\n")
+	fmt.Fprintf(&b, "state := parseBoard(t, `\n")
+	for _, line := range strings.Split(strings.TrimSuffix(board, "\n"), "\n") {
+		if line != "" {
+			line = "\t\t" + line
+		}
+		fmt.Fprintf(&b, "%s\n", line)
+	}
+	fmt.Fprintf(&b, "\t`)\n\n")
+
+	var conditions []string
+	for _, move := range f.Fatal {
+		conditions = append(conditions, fmt.Sprintf("nextMove.Move == %q", move))
+	}
+	fmt.Fprintf(&b, "// Act\n")
+	fmt.Fprintf(&b, "nextMove := move(state)\n\n")
+	fmt.Fprintf(&b, "// Assert\n")
+	fmt.Fprintf(&b, "if %s {\n", strings.Join(conditions, " || "))
+	fmt.Fprintf(&b, "t.Errorf(\"snake made a move it could not survive, %%s\", nextMove.Move)\n")
+	fmt.Fprintf(&b, "}\n")
+	fmt.Fprintf(&b, "}\n")
+
+	source, err := format.Source(b.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(source)
+	return err
+}
@@ -0,0 +1,201 @@
+/*
+Package openingbook holds recommended first moves for standard and wrapped 11x11 games. Positions are keyed with
+the board's symmetry taken out: a position and its rotations and reflections share one key, and the move is
+stored as it is in the orientation that gives the key, so one entry covers all eight.
+
+Books are versioned JSON files generated offline by self-play, see the book command of snakes/go/cmd/arena.
+*/
+package openingbook
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+const (
+	// Version is the book format this package reads and writes. Keys change with the format, so books of
+	// another version are rejected rather than silently never matching.
+	Version = 1
+	// Size is the width and height of the boards books cover.
+	Size = 11
+)
+
+// Book maps positions to the move to make in them.
+type Book struct {
+	Version int `json:"version"`
+	// Moves maps the key of a position to its move, as it is in the orientation the key was made in.
+	Moves map[string]string `json:"moves"`
+}
+
+// New returns an empty book.
+func New() *Book {
+	return &Book{Version: Version, Moves: make(map[string]string)}
+}
+
+// Parse reads a book written by JSON.
+func Parse(data []byte) (*Book, error) {
+	var b Book
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, err
+	}
+	if b.Version != Version {
+		return nil, fmt.Errorf("opening book is version %d, expected %d", b.Version, Version)
+	}
+	if b.Moves == nil {
+		b.Moves = make(map[string]string)
+	}
+	for key, move := range b.Moves {
+		if !rules.IsValidMove(move) {
+			return nil, fmt.Errorf("opening book has move %q for %s", move, key)
+		}
+	}
+	return &b, nil
+}
+
+// JSON returns the book as indented JSON, with the positions in a stable order so books diff well.
+func (b *Book) JSON() ([]byte, error) {
+	return json.MarshalIndent(b, "", "  ")
+}
+
+// Covers reports whether a game is one books are for: a standard or wrapped ruleset on an 11x11 standard map.
+func Covers(state rules.GameState) bool {
+	switch {
+	case state.Board.Width != Size || state.Board.Height != Size:
+		return false
+	case state.Game.Map != "" && state.Game.Map != rules.MapStandard:
+		return false
+	}
+	return state.Game.Ruleset.Name == rules.RulesetStandard || state.Game.Ruleset.Name == rules.RulesetWrapped
+}
+
+// Lookup returns the book's move for the snake the state is for, if the book has the position.
+func (b *Book) Lookup(state rules.GameState) (string, bool) {
+	if !Covers(state) {
+		return "", false
+	}
+	key, s := normalise(state)
+	move, ok := b.Moves[key]
+	if !ok {
+		return "", false
+	}
+	return s.inverse().move(move), true
+}
+
+// Add records the move to make in a position, for the snake the state is for.
+func (b *Book) Add(state rules.GameState, move string) error {
+	if !Covers(state) {
+		return fmt.Errorf("opening books don't cover %dx%d %s games", state.Board.Width, state.Board.Height, state.Game.Ruleset.Name)
+	}
+	if !rules.IsValidMove(move) {
+		return fmt.Errorf("invalid move %q", move)
+	}
+	key, s := normalise(state)
+	b.Moves[key] = s.move(move)
+	return nil
+}
+
+// Key returns the key of the position the state is in, from the point of view of the snake it is for.
+func Key(state rules.GameState) string {
+	key, _ := normalise(state)
+	return key
+}
+
+// symmetry is one of the eight ways of rotating or reflecting a square board onto itself.
+type symmetry int
+
+const symmetries = 8
+
+// apply returns where the symmetry takes a cell.
+func (s symmetry) apply(c rules.Coord) rules.Coord {
+	n := Size - 1
+	switch s {
+	case 1:
+		return rules.Coord{X: n - c.X, Y: c.Y}
+	case 2:
+		return rules.Coord{X: c.X, Y: n - c.Y}
+	case 3:
+		return rules.Coord{X: n - c.X, Y: n - c.Y}
+	case 4:
+		return rules.Coord{X: c.Y, Y: c.X}
+	case 5:
+		return rules.Coord{X: n - c.Y, Y: c.X}
+	case 6:
+		return rules.Coord{X: c.Y, Y: n - c.X}
+	case 7:
+		return rules.Coord{X: n - c.Y, Y: n - c.X}
+	}
+	return c
+}
+
+// inverse returns the symmetry that undoes this one. Only the two quarter turns aren't their own inverse.
+func (s symmetry) inverse() symmetry {
+	switch s {
+	case 5:
+		return 6
+	case 6:
+		return 5
+	}
+	return s
+}
+
+// move returns the direction the symmetry turns a move into.
+func (s symmetry) move(move string) string {
+	centre := rules.Coord{X: Size / 2, Y: Size / 2}
+	from, to := s.apply(centre), s.apply(centre.Neighbour(move))
+	for _, m := range rules.Moves {
+		if from.Neighbour(m) == to {
+			return m
+		}
+	}
+	return move
+}
+
+// normalise returns the key of the position and the symmetry it was made with: of the encodings of all eight
+// orientations of the board, the one that sorts first.
+func normalise(state rules.GameState) (string, symmetry) {
+	best, bestSymmetry := "", symmetry(0)
+	for s := symmetry(0); s < symmetries; s++ {
+		if key := encode(state, s); s == 0 || key < best {
+			best, bestSymmetry = key, s
+		}
+	}
+	return best, bestSymmetry
+}
+
+// encode writes out the position as the symmetry turns it: the ruleset and turn, our body, every other body
+// and the food and hazards. Other snakes, food and hazards are sorted, so their order in the request doesn't
+// matter.
+func encode(state rules.GameState, s symmetry) string {
+	var others []string
+	for _, snake := range state.Board.Snakes {
+		if snake.ID != state.You.ID {
+			others = append(others, coords(snake.Body, s, false))
+		}
+	}
+	sort.Strings(others)
+	return strings.Join([]string{
+		state.Game.Ruleset.Name,
+		fmt.Sprint(state.Turn),
+		"you " + coords(state.You.Body, s, false),
+		"snakes " + strings.Join(others, " / "),
+		"food " + coords(state.Board.Food, s, true),
+		"hazards " + coords(state.Board.Hazards, s, true),
+	}, "; ")
+}
+
+// coords writes out the cells as the symmetry turns them, sorted if their order doesn't matter.
+func coords(cells []rules.Coord, s symmetry, sorted bool) string {
+	out := make([]string, len(cells))
+	for i, c := range cells {
+		c = s.apply(c)
+		out[i] = fmt.Sprintf("%d,%d", c.X, c.Y)
+	}
+	if sorted {
+		sort.Strings(out)
+	}
+	return strings.Join(out, " ")
+}
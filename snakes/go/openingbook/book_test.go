@@ -0,0 +1,102 @@
+package openingbook
+
+import (
+	"testing"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// start returns a turn 0 position on an 11x11 board with one snake in the bottom left corner and one on the
+// right edge, turned by the symmetry.
+func start(s symmetry) rules.GameState {
+	me := []rules.Coord{{X: 1, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 1}}
+	other := []rules.Coord{{X: 9, Y: 5}, {X: 9, Y: 5}, {X: 9, Y: 5}}
+	food := []rules.Coord{{X: 0, Y: 2}, {X: 10, Y: 6}, {X: 5, Y: 5}}
+	turn := func(cells []rules.Coord) []rules.Coord {
+		turned := make([]rules.Coord, len(cells))
+		for i, c := range cells {
+			turned[i] = s.apply(c)
+		}
+		return turned
+	}
+	you := rules.Battlesnake{ID: "me", Body: turn(me), Head: s.apply(me[0])}
+	return rules.GameState{
+		Game: rules.Game{Ruleset: rules.Ruleset{Name: rules.RulesetStandard}, Map: rules.MapStandard},
+		Board: rules.Board{
+			Width: Size, Height: Size, Food: turn(food),
+			Snakes: []rules.Battlesnake{you, {ID: "other", Body: turn(other), Head: s.apply(other[0])}},
+		},
+		You: you,
+	}
+}
+
+func TestSymmetricPositionsShareAnEntry(t *testing.T) {
+	// Arrange
+	book := New()
+	if err := book.Add(start(0), rules.MoveUp); err != nil {
+		t.Fatal(err)
+	}
+
+	for s := symmetry(0); s < symmetries; s++ {
+		// Act
+		move, ok := book.Lookup(start(s))
+
+		// Assert the move comes back turned the same way as the board.
+		if !ok || move != s.move(rules.MoveUp) {
+			t.Errorf("symmetry %d: expected %s, got %q (found %v)", s, s.move(rules.MoveUp), move, ok)
+		}
+	}
+	if len(book.Moves) != 1 {
+		t.Errorf("expected one entry, got %d", len(book.Moves))
+	}
+}
+
+func TestBookCoverage(t *testing.T) {
+	// Arrange
+	book := New()
+	book.Add(start(0), rules.MoveUp)
+	royale, small, moved := start(0), start(0), start(0)
+	royale.Game.Ruleset.Name = rules.RulesetRoyale
+	small.Board.Width, small.Board.Height = 7, 7
+	moved.Turn = 1
+
+	// Act
+	_, royaleOK := book.Lookup(royale)
+	_, smallOK := book.Lookup(small)
+	_, movedOK := book.Lookup(moved)
+	addErr := book.Add(royale, rules.MoveUp)
+
+	// Assert
+	if royaleOK || smallOK || movedOK {
+		t.Errorf("expected only the position added to be in the book, got royale %v 7x7 %v turn 1 %v", royaleOK, smallOK, movedOK)
+	}
+	if addErr == nil {
+		t.Errorf("expected a royale position not to be added")
+	}
+}
+
+func TestParse(t *testing.T) {
+	// Arrange
+	book := New()
+	book.Add(start(0), rules.MoveLeft)
+	data, err := book.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	parsed, err := Parse(data)
+	_, versionErr := Parse([]byte(`{"version": 2, "moves": {}}`))
+	_, moveErr := Parse([]byte(`{"version": 1, "moves": {"x": "sideways"}}`))
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if move, ok := parsed.Lookup(start(0)); !ok || move != rules.MoveLeft {
+		t.Errorf("expected the parsed book to have the move, got %q", move)
+	}
+	if versionErr == nil || moveErr == nil {
+		t.Errorf("expected another version and an invalid move to be rejected, got %v and %v", versionErr, moveErr)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	_ "embed"
+	"log"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/openingbook"
+)
+
+// Opening book: the first moves of standard and wrapped 11x11 games are looked up in openings.json, which is built
+// into the snake. The book is generated offline by self-play with `arena book`, see snakes/go/cmd/arena, and keyed
+// on the position with rotations and reflections of the board taken out, so one entry covers all eight ways a
+// start can be turned. Once the game leaves the book the rest of the pipeline plays as normal.
+
+//go:embed openings.json
+var openingsJSON []byte
+
+// The book in use. A book that doesn't load is logged and left empty, so the snake still plays without it.
+var openings = loadOpenings(openingsJSON)
+
+// Function that reads an opening book.
+func loadOpenings(data []byte) *openingbook.Book {
+	book, err := openingbook.Parse(data)
+	if err != nil {
+		log.Printf("ERROR: Failed to load opening book, %s", err)
+		return openingbook.New()
+	}
+	return book
+}
+
+// Function that returns the book's move for the position, or false if the book doesn't have it or the move is no
+// longer safe to make.
+func bookMove(book *openingbook.Book, state GameState) (string, bool) {
+	nextMove, ok := book.Lookup(toRulesState(state))
+	if !ok {
+		return "", false
+	}
+	if next, ok := stepCoord(state, state.You.Head, nextMove); !ok || solidNextTurn(state, next) {
+		return "", false
+	}
+	return nextMove, true
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// Environment variable pointing at a JSON config file, e.g. one written by `arena optimise`.
+const configEnv = "PATHY_CONFIG"
+
+// Config holds the weights the pathing uses to build its cost grid and pick a target.
+// The JSON keys are what `arena optimise` writes, so keep the two in step.
+type Config struct {
+	// Cost of the cells next to the head of a snake at least as long as us.
+	LargerHeadCost float64 `json:"largerHeadCost"`
+	// Cost of the cells next to the head of a snake shorter than us.
+	SmallerHeadCost float64 `json:"smallerHeadCost"`
+	// Cost of a cell with food on it.
+	FoodCost float64 `json:"foodCost"`
+	// Cost of a hazard cell on maps where hazards can be walked through.
+	HazardCost float64 `json:"hazardCost"`
+	// Health below which we go looking for food.
+	HungryHealth int32 `json:"hungryHealth"`
+	// How many turns ahead to search when only one other snake is left. 0 turns the duel search off.
+	DuelDepth int `json:"duelDepth"`
+	// How many turns the duel search may play forward per move. 0 leaves only the time limit.
+	DuelNodes int `json:"duelNodes"`
+	// How sure we have to be that a move kills a smaller snake before we hunt it. Above 1 turns hunting off.
+	HuntConfidence float64 `json:"huntConfidence"`
+	// How to move with three or more snakes: "path" or "mcts".
+	Strategy string `json:"strategy"`
+	// How many games MCTS plays forward per move. 0 leaves only the time limit.
+	MCTSPlayouts int `json:"mctsPlayouts"`
+}
+
+// The hand-picked weights the snake has always used.
+var defaultConfig = Config{
+	LargerHeadCost:  5,
+	SmallerHeadCost: 1,
+	FoodCost:        .5,
+	HazardCost:      5,
+	HungryHealth:    85,
+	DuelDepth:       4,
+	DuelNodes:       5000,
+	HuntConfidence:  .75,
+	Strategy:        strategyPath,
+	MCTSPlayouts:    1000,
+}
+
+// The config in use. main replaces it with the file from PATHY_CONFIG if one is set.
+var config = defaultConfig
+
+// Function that loads a config file. Any weight missing from the file keeps its default value.
+func loadConfig(path string) (Config, error) {
+	loaded := defaultConfig
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return loaded, err
+	}
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return loaded, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if loaded.LargerHeadCost <= 0 || loaded.SmallerHeadCost <= 0 || loaded.FoodCost <= 0 || loaded.HazardCost <= 0 {
+		return loaded, fmt.Errorf("%s: cell costs must be positive", path)
+	}
+	if loaded.DuelDepth < 0 {
+		return loaded, fmt.Errorf("%s: duel depth can't be negative", path)
+	}
+	if loaded.DuelNodes < 0 || loaded.MCTSPlayouts < 0 {
+		return loaded, fmt.Errorf("%s: search budgets can't be negative", path)
+	}
+	if loaded.HuntConfidence < 0 {
+		return loaded, fmt.Errorf("%s: hunt confidence can't be negative", path)
+	}
+	if loaded.Strategy != strategyPath && loaded.Strategy != strategyMCTS {
+		return loaded, fmt.Errorf("%s: unknown strategy %q", path, loaded.Strategy)
+	}
+	return loaded, nil
+}
+
+// Function that loads the config named by PATHY_CONFIG, or returns the defaults if it isn't set.
+func configFromEnv() (Config, error) {
+	path, ok := os.LookupEnv(configEnv)
+	if !ok || path == "" {
+		return defaultConfig, nil
+	}
+	return loadConfig(path)
+}
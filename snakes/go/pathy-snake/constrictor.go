@@ -0,0 +1,83 @@
+package main
+
+// Constrictor: every snake grows every turn and there is no food, so whoever has the most room to fill lasts the
+// longest. Rather than following a path to a target, we take the move that leaves us the most cells we reach
+// before any other snake. We look one move further than that, so a move that cuts our space in two only counts
+// the half we can still go into, and between moves that are as good we take the one with fewer open cells around
+// it, filling our space from the edges in rather than leaving gaps we can't come back for.
+
+// Function that returns the constrictor move and the number of cells it leaves us, or false if no cell next to
+// our head is walkable.
+func constrictorMove(state GameState, grid *Grid) (string, int, bool) {
+	enemies := enemyDistances(state, grid)
+	bestMove, bestTerritory, bestOpen := "", -1, 0
+	for _, move := range []string{"up", "down", "left", "right"} {
+		c, ok := stepCoord(state, state.You.Head, move)
+		if !ok {
+			continue
+		}
+		if cell := grid.Get(c.X, c.Y); cell == nil || !cell.Walkable {
+			continue
+		}
+		territory, open := 0, 0
+		for _, next := range []string{"up", "down", "left", "right"} {
+			n, ok := stepCoord(state, c, next)
+			if !ok {
+				continue
+			}
+			if cell := grid.Get(n.X, n.Y); cell == nil || !cell.Walkable {
+				continue
+			}
+			open++
+			if t := territoryFrom(state, grid, n, c, enemies); t > territory {
+				territory = t
+			}
+		}
+		if territory > bestTerritory || (territory == bestTerritory && open < bestOpen) {
+			bestMove, bestTerritory, bestOpen = move, territory, open
+		}
+	}
+	return bestMove, bestTerritory, bestMove != ""
+}
+
+// Function that counts the cells we reach before every other snake if we move to via and then start. Going
+// through via again isn't allowed, as our body will be on it. The other snakes' distances are from their heads
+// now, and ours from start are two moves behind them.
+func territoryFrom(state GameState, grid *Grid, start, via Coord, enemies map[string]map[Coord]int) int {
+	distances := map[Coord]int{start: 0, via: -1}
+	frontier := []Coord{start}
+	territory := 0
+	for step := 0; len(frontier) > 0; step++ {
+		var next []Coord
+		for _, c := range frontier {
+			if ours(c, step+2, enemies) {
+				territory++
+			}
+			for _, move := range []string{"up", "down", "left", "right"} {
+				n, ok := stepCoord(state, c, move)
+				if !ok {
+					continue
+				}
+				if _, seen := distances[n]; seen {
+					continue
+				}
+				if cell := grid.Get(n.X, n.Y); cell != nil && cell.Walkable {
+					distances[n] = step + 1
+					next = append(next, n)
+				}
+			}
+		}
+		frontier = next
+	}
+	return territory
+}
+
+// Function that reports whether we get to a cell first, arriving after the given number of moves.
+func ours(c Coord, moves int, enemies map[string]map[Coord]int) bool {
+	for _, distances := range enemies {
+		if distance, ok := distances[c]; ok && distance <= moves {
+			return false
+		}
+	}
+	return true
+}
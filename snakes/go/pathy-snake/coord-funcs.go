@@ -39,8 +39,8 @@ func (c Coord) isNextToSnakeHead(state GameState) bool {
 	right := Coord{X: c.X + 1, Y: c.Y}
 	// check if above, below, left, or right is occupied by a snake.
 	for _, snake := range state.Board.Snakes {
-		// skip if the snake is us.
-		if snake.ownSnake(state) || snake.Length < state.You.Length {
+		// skip if the snake is us or on our squad.
+		if snake.ownSnake(state) || snake.isTeammate(state) || snake.Length < state.You.Length {
 			continue
 		}
 		if above == snake.Head || below == snake.Head || left == snake.Head || right == snake.Head {
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// Duel search: when there is exactly one other snake left, look ahead with minimax instead of following a path.
+// Both snakes move at the same time, so each turn of the tree is our move followed by the reply that is worst for
+// us, as if the other snake could see our move first (paranoid). That makes the search careful rather than greedy,
+// which is what we want from a snake that usually wins on survival. The game itself is played forward with the
+// shared rules engine.
+
+// Scores for a finished game. Quicker wins and slower losses score better, see duelSearch.value.
+const (
+	duelWin  = 1e6
+	duelLoss = -1e6
+	// Both snakes dying is better than losing, but not something to aim for.
+	duelDraw = duelLoss / 2
+)
+
+// Weights of the evaluation of an unfinished game, see evaluateDuel.
+const (
+	// Per cell we can reach before the other snake.
+	duelTerritoryWeight = 1.0
+	// Per body segment we are longer by.
+	duelLengthWeight = 5.0
+	// Per point of health we are ahead by.
+	duelHealthWeight = 0.1
+	// For having less room than our own length, which usually means we are trapped.
+	duelTrappedPenalty = 200.0
+	// For not being able to reach any food before starving.
+	duelStarvingPenalty = duelWin / 10
+)
+
+// Function that picks a move by searching the duel, or returns false if this isn't a duel, the ruleset is one
+// the engine can't play, or there wasn't time to search even one turn ahead.
+func duelMove(state GameState) (string, int, bool) {
+	if config.DuelDepth <= 0 || len(state.Board.Snakes) != 2 {
+		return "", 0, false
+	}
+	var them string
+	for _, snake := range state.Board.Snakes {
+		if snake.ID != state.You.ID {
+			them = snake.ID
+		}
+	}
+	if them == "" || them == state.You.ID || len(state.Board.Snakes[0].Body) == 0 || len(state.Board.Snakes[1].Body) == 0 {
+		return "", 0, false
+	}
+
+	engine, ok := searchEngine(state)
+	if !ok {
+		return "", 0, false
+	}
+	search := duelSearch{me: state.You.ID, them: them, budget: config.DuelNodes, deadline: searchDeadline(state)}
+	return search.iterate(engine, config.DuelDepth)
+}
+
+type duelSearch struct {
+	me, them string
+	// How many turns the search may play forward, 0 for no limit. The deadline is only a safety cap, so the
+	// result depends on the budget and not on how fast the machine is.
+	budget   int
+	nodes    int
+	deadline time.Time
+	// Set once the budget runs out or the deadline passes, after which every result is thrown away.
+	aborted bool
+	// Depth of the iteration in progress, used to tell how many turns into the tree a node is.
+	depth int
+}
+
+// Function that searches one turn deeper at a time until maxDepth, the node budget or the deadline, keeping the best move of the
+// last search that finished. It also returns how deep that was.
+func (s *duelSearch) iterate(engine *rules.Engine, maxDepth int) (string, int, bool) {
+	best, reached := "", 0
+	for depth := 1; depth <= maxDepth; depth++ {
+		s.depth = depth
+		move, score := s.root(engine, depth, best)
+		if s.aborted {
+			break
+		}
+		best, reached = move, depth
+		// Nothing deeper will change a result that is already certain.
+		if score >= duelWin-float64(maxDepth) || score <= duelLoss+float64(maxDepth) {
+			break
+		}
+	}
+	return best, reached, reached > 0
+}
+
+// Function that finds our best move at the root, trying the best move of the previous depth first so the
+// pruning has a good bound early.
+func (s *duelSearch) root(engine *rules.Engine, depth int, first string) (string, float64) {
+	moves := searchMoves(engine, s.me)
+	for i, move := range moves {
+		if move == first {
+			moves[0], moves[i] = moves[i], moves[0]
+		}
+	}
+	best, bestScore := moves[0], math.Inf(-1)
+	for _, move := range moves {
+		score := s.reply(engine, move, depth, bestScore, math.Inf(1))
+		if s.aborted {
+			return "", 0
+		}
+		if score > bestScore {
+			best, bestScore = move, score
+		}
+	}
+	return best, bestScore
+}
+
+// Function that scores the position with us to move: the best of our moves.
+func (s *duelSearch) value(engine *rules.Engine, depth int, alpha, beta float64) float64 {
+	if score, over := s.finished(engine, s.depth-depth); over {
+		return score
+	}
+	if depth == 0 {
+		return evaluateDuel(engine, s.me, s.them)
+	}
+	best := math.Inf(-1)
+	for _, move := range searchMoves(engine, s.me) {
+		score := s.reply(engine, move, depth, math.Max(alpha, best), beta)
+		if s.aborted {
+			return 0
+		}
+		best = math.Max(best, score)
+		if best >= beta {
+			break
+		}
+	}
+	return best
+}
+
+// Function that scores our move by the other snake's reply that is worst for us, playing the turn out.
+func (s *duelSearch) reply(engine *rules.Engine, move string, depth int, alpha, beta float64) float64 {
+	worst := math.Inf(1)
+	for _, theirs := range searchMoves(engine, s.them) {
+		if (s.budget > 0 && s.nodes >= s.budget) || time.Now().After(s.deadline) {
+			s.aborted = true
+			return 0
+		}
+		s.nodes++
+		next := engine.Clone()
+		next.Step(map[string]string{s.me: move, s.them: theirs})
+		worst = math.Min(worst, s.value(next, depth-1, alpha, math.Min(beta, worst)))
+		if s.aborted {
+			return 0
+		}
+		if worst <= alpha {
+			break
+		}
+	}
+	return worst
+}
+
+// Function that scores a game that finished after the given number of turns, preferring quick wins and slow
+// losses.
+func (s *duelSearch) finished(engine *rules.Engine, turns int) (float64, bool) {
+	var meAlive, themAlive bool
+	for _, snake := range engine.Board.Snakes {
+		meAlive = meAlive || snake.ID == s.me
+		themAlive = themAlive || snake.ID == s.them
+	}
+	switch {
+	case meAlive && themAlive:
+		return 0, false
+	case meAlive:
+		return duelWin - float64(turns), true
+	case themAlive:
+		return duelLoss + float64(turns), true
+	}
+	return duelDraw, true
+}
+
+// Function that scores an unfinished duel from our point of view. Territory is the cells we can reach before the
+// other snake, found with a breadth first search from both heads at once. Tails that will move are free. This
+// runs at every leaf of the search, so cells are kept in slices indexed by y*width+x rather than maps.
+func evaluateDuel(engine *rules.Engine, me, them string) float64 {
+	board := engine.Board
+	width := board.Width
+	index := func(c rules.Coord) int { return c.Y*width + c.X }
+	cells := width * board.Height
+
+	var us, other rules.Battlesnake
+	blocked := make([]bool, cells)
+	for _, snake := range board.Snakes {
+		if snake.ID == me {
+			us = snake
+		} else if snake.ID == them {
+			other = snake
+		}
+		body := snake.Body
+		if n := len(body); n > 1 && body[n-1] != body[n-2] {
+			body = body[:n-1]
+		}
+		for _, c := range body {
+			blocked[index(c)] = true
+		}
+	}
+	// owner is 1 for cells we reach first, 2 for the other snake and 3 for cells both reach on the same turn,
+	// which nobody gets and nobody searches on from.
+	owner := make([]int8, cells)
+	owner[index(us.Head)], owner[index(other.Head)] = 1, 2
+	distance := make([]int, cells)
+	mine, theirs, room := 1, 1, 0
+	frontier := []rules.Coord{us.Head, other.Head}
+	var next []rules.Coord
+	for level := 1; len(frontier) > 0; level++ {
+		next = next[:0]
+		for _, c := range frontier {
+			claim := owner[index(c)]
+			for _, move := range rules.Moves {
+				n, ok := searchStep(engine, c, move)
+				if !ok || blocked[index(n)] {
+					continue
+				}
+				switch o := owner[index(n)]; {
+				case o == 0:
+					owner[index(n)], distance[index(n)] = claim, level
+					next = append(next, n)
+					if claim == 1 {
+						mine++
+					} else {
+						theirs++
+					}
+				case o != claim && o != 3 && distance[index(n)] == level:
+					// Claimed this level by the other snake too, so it is a tie.
+					owner[index(n)] = 3
+					if o == 1 {
+						mine--
+					} else {
+						theirs--
+					}
+				}
+			}
+		}
+		frontier, next = next, frontier
+	}
+	foodDistance := -1
+	for _, f := range board.Food {
+		if owner[index(f)] == 1 && (foodDistance < 0 || distance[index(f)] < foodDistance) {
+			foodDistance = distance[index(f)]
+		}
+	}
+
+	// Room is everything we could reach if the other snake weren't racing us for it.
+	seen := make([]bool, cells)
+	seen[index(us.Head)] = true
+	frontier = append(frontier[:0], us.Head)
+	for len(frontier) > 0 && room < len(us.Body) {
+		next = next[:0]
+		for _, c := range frontier {
+			for _, move := range rules.Moves {
+				n, ok := searchStep(engine, c, move)
+				if ok && !blocked[index(n)] && !seen[index(n)] {
+					seen[index(n)] = true
+					room++
+					next = append(next, n)
+				}
+			}
+		}
+		frontier, next = next, frontier
+	}
+
+	score := duelTerritoryWeight*float64(mine-theirs) +
+		duelLengthWeight*float64(len(us.Body)-len(other.Body)) +
+		duelHealthWeight*float64(us.Health-other.Health)
+	if room < len(us.Body) {
+		score -= duelTrappedPenalty
+	}
+	if len(board.Food) > 0 && (foodDistance < 0 || foodDistance > int(us.Health)) {
+		score -= duelStarvingPenalty
+	}
+	return score
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"expvar"
+	"fmt"
+	"math/rand"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/safety"
+)
+
+// Ensemble: the path planning picks our move, and the rules spring-league-2022 moves by (snakes/go/safety) can
+// veto it. The path sometimes walks into a pocket or a corner the rules see coming, while the rules alone only
+// pick at random between the moves they leave. When the path's move is vetoed we score the moves the rules allow
+// on the path's grid, by how much room they leave us and then by how cheap the cell is. The rules count every tail
+// as a body, so the path's move stands if it goes onto a tail that moves on this turn, or if none of the others
+// leave us room for our length and it leaves us more. Every disagreement goes in the move's decision trace.
+
+// Served at /debug/vars: how many moves the rules have changed.
+var ensembleVetoes = expvar.NewInt("ensemble_vetoes")
+
+// Function that returns the ensemble's move, and the trace of where the path and the rules disagreed about it,
+// which is empty if they agreed.
+func ensembleMove(state GameState, rng *rand.Rand) (string, []string) {
+	planned, grid := planMove(state, rng)
+	return vetoByRules(state, grid, planned.Move)
+}
+
+// Function that returns the planned move unless the rules veto it, in which case it returns the best of the moves
+// they allow instead, along with the trace.
+func vetoByRules(state GameState, grid *Grid, planned string) (string, []string) {
+	vetoes := safety.Check(toRulesState(state))
+	rule, vetoed := vetoes[planned]
+	if !vetoed {
+		return planned, nil
+	}
+	trace := []string{fmt.Sprintf("spring-league vetoed %s (%s)", planned, rule)}
+	planCell, _ := stepCoord(state, state.You.Head, planned)
+	if (rule == safety.RuleOwnBody || rule == safety.RuleSnake) && !solidNextTurn(state, planCell) {
+		return planned, append(trace, fmt.Sprintf("kept %s, the tail there moves on", planned))
+	}
+
+	length := len(state.You.Body)
+	bestMove, bestRoom, bestCost := "", 0, 0.0
+	for _, move := range safety.Safe(vetoes) {
+		c, ok := stepCoord(state, state.You.Head, move)
+		cell := grid.Get(c.X, c.Y)
+		if !ok || cell == nil || !cell.Walkable {
+			trace = append(trace, fmt.Sprintf("pathy finds %s blocked", move))
+			continue
+		}
+		room := roomFrom(state, grid, c, 2*length)
+		if bestMove == "" || room > bestRoom || (room == bestRoom && cell.Cost < bestCost) {
+			bestMove, bestRoom, bestCost = move, room, cell.Cost
+		}
+	}
+	if bestMove == "" {
+		return planned, append(trace, fmt.Sprintf("kept %s, nothing else is walkable", planned))
+	}
+	if bestRoom < length && roomFrom(state, grid, planCell, 2*length) > bestRoom {
+		return planned, append(trace, fmt.Sprintf("kept %s, %s leaves too little room", planned, bestMove))
+	}
+	ensembleVetoes.Add(1)
+	return bestMove, append(trace, fmt.Sprintf("took %s", bestMove))
+}
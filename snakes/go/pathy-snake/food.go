@@ -0,0 +1,76 @@
+package main
+
+// Contested food: who gets to each food first, counting moves along walkable cells rather than straight-line
+// distance. Food another snake reaches first is theirs. Food reached at the same time goes to the longer snake,
+// since the shorter one dies in the head-to-head, and nobody gets it if they are the same length. In squad games
+// we share food out with our teammates rather than race them: each food goes to whichever of us is closest, and
+// to the lowest snake ID on a tie, which every member of the squad works out the same way.
+
+// Who a food belongs to, best first.
+type foodClaim int
+
+const (
+	// We get there before every other snake, so it is in our territory.
+	foodOurs foodClaim = iota
+	// We get there at the same time as a shorter snake, and win the head-to-head.
+	foodWonTie
+	// Another snake gets there first, or at the same time and is at least as long as us.
+	foodLost
+)
+
+// Function that returns the number of moves from start to every cell reachable from it over walkable cells.
+func moveDistances(state GameState, grid *Grid, start Coord) map[Coord]int {
+	distances := map[Coord]int{start: 0}
+	frontier := []Coord{start}
+	for step := 1; len(frontier) > 0; step++ {
+		var next []Coord
+		for _, c := range frontier {
+			for _, move := range []string{"up", "down", "left", "right"} {
+				n, ok := stepCoord(state, c, move)
+				if !ok {
+					continue
+				}
+				if _, seen := distances[n]; seen {
+					continue
+				}
+				if cell := grid.Get(n.X, n.Y); cell != nil && cell.Walkable {
+					distances[n] = step
+					next = append(next, n)
+				}
+			}
+		}
+		frontier = next
+	}
+	return distances
+}
+
+// Function that returns the move distances from every other snake's head, teammates included, by snake ID.
+func enemyDistances(state GameState, grid *Grid) map[string]map[Coord]int {
+	distances := make(map[string]map[Coord]int)
+	for _, snake := range state.Board.Snakes {
+		if snake.ID != state.You.ID && len(snake.Body) > 0 {
+			distances[snake.ID] = moveDistances(state, grid, snake.Head)
+		}
+	}
+	return distances
+}
+
+// Function that returns who a food belongs to, given our distance to it and every other snake's distances.
+func claimFood(state GameState, food Coord, ours int, theirs map[string]map[Coord]int) foodClaim {
+	claim := foodOurs
+	for _, snake := range state.Board.Snakes {
+		distance, ok := theirs[snake.ID][food]
+		switch {
+		case !ok || distance > ours:
+		case snake.isTeammate(state):
+			if distance < ours || snake.ID < state.You.ID {
+				return foodLost
+			}
+		case distance < ours || len(snake.Body) >= len(state.You.Body):
+			return foodLost
+		default:
+			claim = foodWonTie
+		}
+	}
+	return claim
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/asciiboard"
+	"github.com/es-na-battlesnake/snakes/snakes/go/stategen"
+)
+
+// Fuzz move with random game states from snakes/go/stategen. Run `go test -fuzz FuzzMove` to search beyond the
+// seed corpus; a failure prints the board and can be rerun with `go test -run FuzzMove/<name>`.
+func FuzzMove(f *testing.F) {
+	for seed := int64(0); seed < 200; seed++ {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, seed int64) {
+		// Arrange
+		generated := stategen.FromSeed(seed)
+		state := fromRules(t, generated)
+
+		// Act
+		nextMove := move(state)
+
+		// Assert the move is a direction and doesn't run into a wall or body when something safer was free
+		if err := stategen.Check(generated, nextMove.Move); err != nil {
+			t.Errorf("seed %d: %v\n%s", seed, err, asciiboard.Format(generated))
+		}
+	})
+}
+
+// Fuzz start and end, which get the same requests as move and must not crash on any of them.
+func FuzzStartEnd(f *testing.F) {
+	for seed := int64(0); seed < 50; seed++ {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, seed int64) {
+		state := fromRules(t, stategen.FromSeed(seed))
+		start(state)
+		end(state)
+
+		// The final request of a game can have no snakes left at all.
+		state.Board.Snakes = nil
+		end(state)
+	})
+}
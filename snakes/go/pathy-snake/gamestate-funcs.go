@@ -4,6 +4,18 @@ func (g GameState) isWrapped() bool {
 	return g.Game.Ruleset.Name == "wrapped"
 }
 
+func (g GameState) isSquad() bool {
+	return g.Game.Ruleset.Name == "squad"
+}
+
+func (g GameState) isSolo() bool {
+	return g.Game.Ruleset.Name == "solo"
+}
+
+func (g GameState) isConstrictor() bool {
+	return g.Game.Ruleset.Name == "constrictor"
+}
+
 func (g GameState) isArcadeMaze() bool {
 	return g.Game.Ruleset.Name == "arcade-maze"
 }
@@ -0,0 +1,73 @@
+package main
+
+// Hazard exposure of targets: how much damage the path to a target takes from hazards, and whether we would live
+// to the end of it. Health goes down by one every turn, and by HazardDamagePerTurn more for every hazard on the
+// cell we move onto, unless there is food on it, which puts health back to full.
+
+// How many moves of extra distance one point of hazard damage is worth when choosing between foods at full
+// health. It grows as our health drops, see foodScore.
+const hazardExposureWeight = 0.1
+
+const maxHealth = 100
+
+// Function that returns how many times the cell appears in the board's hazards. Hazards can stack, and so does
+// their damage.
+func hazardCount(state GameState, c Coord) int {
+	count := 0
+	for _, hazard := range state.Board.Hazards {
+		if hazard == c {
+			count++
+		}
+	}
+	return count
+}
+
+// Function that follows a path from our head and returns our health at the end of it, the total hazard damage
+// taken on the way, and whether the hazards leave us alive when we get there. Running out of health without
+// hazards doesn't count, as that happens whichever way we go.
+func walkPath(state GameState, path *Path) (int, int, bool) {
+	health, damage := int(state.You.Health), 0
+	if path == nil {
+		return health, damage, true
+	}
+	for i := 1; i < path.Length(); i++ {
+		c := Coord{X: path.Get(i).X, Y: path.Get(i).Y}
+		health--
+		if containsCoord(state.Board.Food, c) {
+			health = maxHealth
+			continue
+		}
+		hit := int(state.Game.Ruleset.Settings.HazardDamagePerTurn) * hazardCount(state, c)
+		health -= hit
+		damage += hit
+		if hit > 0 && health <= 0 {
+			return 0, damage, false
+		}
+	}
+	return health, damage, true
+}
+
+// Function that returns the cheapest path from our head to dest that doesn't run us out of health on the way,
+// going around hazards or via food if it has to, and the health we will have when we get there. It returns nil
+// if every way there kills us.
+func healthyPath(state GameState, grid *Grid, dest *Cell) (*Path, int) {
+	damage := int(state.Game.Ruleset.Settings.HazardDamagePerTurn)
+	step := func(cell *Cell, health int) int {
+		c := Coord{X: cell.X, Y: cell.Y}
+		if containsCoord(state.Board.Food, c) {
+			return maxHealth
+		}
+		return health - 1 - damage*hazardCount(state, c)
+	}
+	return grid.GetHealthyPathFromCells(grid.Get(state.You.Head.X, state.You.Head.Y), dest, int(state.You.Health), step, state.isWrapped())
+}
+
+// Function that scores the path to a food, lower is better: the number of moves, plus the hazard damage taken on
+// the way weighted more heavily the less health we have.
+func foodScore(state GameState, path *Path, damage int) float64 {
+	health := int(state.You.Health)
+	if health < 1 {
+		health = 1
+	}
+	return float64(path.Length()-1) + float64(damage)*hazardExposureWeight*maxHealth/float64(health)
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"log"
+)
+
+// Head-to-head model: for each move we could make, which other snakes could move onto the same cell next turn,
+// what happens if they do, and how likely they are to. The path only sees head-to-heads as extra cost, so it can
+// still walk into one; vetoHeadToHead stops that when there is a move that is less likely to kill us.
+
+// What happens when our head and another snake's head land on the same cell.
+type headToHeadOutcome int
+
+const (
+	// We are longer, so they die.
+	headToHeadWin headToHeadOutcome = iota
+	// We are the same length, so both die.
+	headToHeadTie
+	// They are longer, so we die.
+	headToHeadLoss
+)
+
+// A snake that could move onto the cell we are moving to.
+type headToHeadThreat struct {
+	snake   Battlesnake
+	outcome headToHeadOutcome
+	// How likely the snake is to move there, from predictMoves.
+	probability float64
+}
+
+// The threats to one of our moves.
+type headToHeadRisk struct {
+	move    string
+	cell    Coord
+	threats []headToHeadThreat
+}
+
+// Function that returns how likely the move is to kill us in a head-to-head: the chance that at least one snake
+// we would lose or tie against moves onto the same cell.
+func (r headToHeadRisk) death() float64 {
+	survive := 1.0
+	for _, threat := range r.threats {
+		if threat.outcome != headToHeadWin {
+			survive *= 1 - threat.probability
+		}
+	}
+	return 1 - survive
+}
+
+// Function that returns how likely the move is to kill another snake in a head-to-head.
+func (r headToHeadRisk) kill() float64 {
+	missed := 1.0
+	for _, threat := range r.threats {
+		if threat.outcome == headToHeadWin {
+			missed *= 1 - threat.probability
+		}
+	}
+	return 1 - missed
+}
+
+// Function that lists the head-to-head risk of each move that keeps us on the board.
+func headToHeadRisks(state GameState) []headToHeadRisk {
+	var risks []headToHeadRisk
+	for _, move := range []string{"up", "down", "left", "right"} {
+		cell, ok := stepCoord(state, state.You.Head, move)
+		if !ok {
+			continue
+		}
+		risk := headToHeadRisk{move: move, cell: cell}
+		for _, snake := range state.Board.Snakes {
+			if snake.ID == state.You.ID || len(snake.Body) == 0 || snake.isPassable(state) {
+				continue
+			}
+			for theirMove, probability := range predictMoves(state, snake) {
+				if next, _ := stepCoord(state, snake.Head, theirMove); next == cell {
+					risk.threats = append(risk.threats, headToHeadThreat{snake: snake, outcome: headToHeadOutcomeAgainst(state, snake), probability: probability})
+				}
+			}
+		}
+		risks = append(risks, risk)
+	}
+	return risks
+}
+
+// Function that returns what happens if our head meets the snake's head, by comparing lengths.
+func headToHeadOutcomeAgainst(state GameState, snake Battlesnake) headToHeadOutcome {
+	switch ours, theirs := len(state.You.Body), len(snake.Body); {
+	case ours > theirs:
+		return headToHeadWin
+	case ours == theirs:
+		return headToHeadTie
+	}
+	return headToHeadLoss
+}
+
+// Function that predicts how likely a snake is to make each move that doesn't run into a wall, a body or its own
+// neck, from what we have seen of it this game, see opponents.go. A snake that isn't suicidal could pick any of
+// them, so until we know better they are equally likely. A snake with no safe move has no predictions, as it dies
+// wherever it goes.
+func predictMoves(state GameState, snake Battlesnake) map[string]float64 {
+	return opponentModelFor(state, snake.ID).predict(moveOptions(state, snake))
+}
+
+// Function that reports whether a cell will still have a snake's body on it next turn. Tails move out of the way
+// unless the snake has just eaten, or the game is constrictor.
+func solidNextTurn(state GameState, c Coord) bool {
+	for _, snake := range state.Board.Snakes {
+		body := snake.Body
+		if n := len(body); n > 1 && body[n-1] != body[n-2] && !state.isConstrictor() {
+			body = body[:n-1]
+		}
+		if containsCoord(body, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// Function that returns the cell a move leads to, wrapping on wrapped boards, or false if it is off the board.
+func stepCoord(state GameState, c Coord, move string) (Coord, bool) {
+	next := c
+	switch move {
+	case "up":
+		next.Y++
+	case "down":
+		next.Y--
+	case "left":
+		next.X--
+	case "right":
+		next.X++
+	}
+	width, height := state.Board.Width, state.Board.Height
+	if state.isWrapped() {
+		return Coord{X: (next.X + width) % width, Y: (next.Y + height) % height}, true
+	}
+	return next, next.X >= 0 && next.X < width && next.Y >= 0 && next.Y < height
+}
+
+// Function that swaps the chosen move for a walkable one if the chosen move could end in a head-to-head we lose
+// or tie and the other is less likely to. A move that leaves us less room than our own length counts as worse
+// than any head-to-head, so we don't dodge one snake only to get stuck.
+func vetoHeadToHead(state GameState, grid *Grid, chosen BattlesnakeMoveResponse) BattlesnakeMoveResponse {
+	risks := headToHeadRisks(state)
+	type option struct {
+		move    string
+		trapped bool
+		death   float64
+	}
+	var current *option
+	var alternatives []option
+	for _, risk := range risks {
+		o := option{move: risk.move, death: risk.death(), trapped: roomFrom(state, grid, risk.cell, len(state.You.Body)) < len(state.You.Body)}
+		if risk.move == chosen.Move {
+			current = &o
+			continue
+		}
+		if cell := grid.Get(risk.cell.X, risk.cell.Y); cell != nil && cell.Walkable {
+			alternatives = append(alternatives, o)
+		}
+	}
+	if current == nil || current.death == 0 {
+		return chosen
+	}
+	best := *current
+	for _, o := range alternatives {
+		if (best.trapped && !o.trapped) || (best.trapped == o.trapped && o.death < best.death) {
+			best = o
+		}
+	}
+	if best.move != chosen.Move {
+		log.Printf("%s MOVE %d: %s instead of %s, which had a %.2f chance of a head-to-head we don't win\n", sanatizeInput(state.Game.ID), isNumber(state.Turn), best.move, chosen.Move, current.death)
+	}
+	return BattlesnakeMoveResponse{Move: best.move}
+}
+
+// Function that counts the walkable cells reachable from the cell we would move to, stopping once it has found
+// limit of them.
+func roomFrom(state GameState, grid *Grid, start Coord, limit int) int {
+	if cell := grid.Get(start.X, start.Y); cell == nil || !cell.Walkable {
+		return 0
+	}
+	return areaFrom(state, grid, start, []Coord{state.You.Head}, limit)
+}
+
+// Function that counts the walkable cells reachable from start, start included, without going through the
+// blocked cells. It stops once it has found limit of them.
+func areaFrom(state GameState, grid *Grid, start Coord, blocked []Coord, limit int) int {
+	seen := map[Coord]bool{start: true}
+	for _, c := range blocked {
+		seen[c] = true
+	}
+	frontier := []Coord{start}
+	area := 1
+	for len(frontier) > 0 && area < limit {
+		var next []Coord
+		for _, c := range frontier {
+			for _, move := range []string{"up", "down", "left", "right"} {
+				n, ok := stepCoord(state, c, move)
+				if !ok || seen[n] {
+					continue
+				}
+				seen[n] = true
+				if cell := grid.Get(n.X, n.Y); cell != nil && cell.Walkable {
+					area++
+					next = append(next, n)
+				}
+			}
+		}
+		frontier = next
+	}
+	return area
+}
@@ -3,13 +3,36 @@ package main
 // This file contains helper functions for the starter-snake project.
 
 import (
+	"hash/fnv"
 	"log"
 	"strings"
 	"strconv"
 	"math/rand"
 	"math"
+	"os"
 )
 
+// Environment variable that overrides the game ID as the source of the random seed.
+// Setting it makes every game play the same way, which is handy when replaying a loss.
+const seedEnv = "BATTLESNAKE_SEED"
+
+// Function that returns the random source used for every decision on this turn.
+// It is seeded from the game ID (or BATTLESNAKE_SEED) and the turn, so the same request always gets the same move.
+func newGameRand(state GameState) *rand.Rand {
+	return rand.New(rand.NewSource(gameSeed(state)))
+}
+
+// Function that hashes the game ID, or the seed override, into a seed for the given turn.
+func gameSeed(state GameState) int64 {
+	h := fnv.New64a()
+	if seed, ok := os.LookupEnv(seedEnv); ok {
+		h.Write([]byte(seed))
+	} else {
+		h.Write([]byte(state.Game.ID))
+	}
+	return int64(h.Sum64()) + int64(state.Turn)
+}
+
 // This function helps ensure that user input is clearly marked in log entries, and that
 // a malicious user cannot cause confusion in other ways. Intended to be used with log.Printf.
 func sanatizeInput(s string) string {
@@ -74,58 +97,94 @@ func containsCoord(array []Coord, coord Coord) bool {
 }
 
 // function to choose a target cell from an array of grid cells
-func chooseTargetCell(walkableCells []*Cell) *Cell {
+func chooseTargetCell(walkableCells []*Cell, rng *rand.Rand) *Cell {
 	if len(walkableCells) > 0 {
-		return walkableCells[rand.Intn(len(walkableCells))]
+		return walkableCells[rng.Intn(len(walkableCells))]
 	}
 	log.Printf("No walkable cells or paths anywhere on board.\n")
 	return nil
 }
 
 // function to choose a random target cell that is walkable
-func chooseRandomWalkableTargetCell(grid *Grid, state GameState) *Cell {
+func chooseRandomWalkableTargetCell(grid *Grid, state GameState, rng *rand.Rand) *Cell {
 	// randomize the order of the walkable cells so we don't always choose the same one.
 	walkableCells := grid.CellsByWalkable(true)
-	rand.Shuffle(len(walkableCells), func(i, j int) { walkableCells[i], walkableCells[j] = walkableCells[j], walkableCells[i] })
+	rng.Shuffle(len(walkableCells), func(i, j int) { walkableCells[i], walkableCells[j] = walkableCells[j], walkableCells[i] })
+
+	// A hazard cell we can reach alive, in case there is nowhere else to go.
+	var hazardCell *Cell
 
 	// Iterate over all the walkableCells.
 	for _, cell := range walkableCells {
 		// Make sure there is a path to the cell we chose.
 		path := grid.GetPathFromCells(grid.Get(state.You.Head.X, state.You.Head.Y), grid.Get(cell.X, cell.Y), false, false, state.isWrapped())
 
-		if path.Length() == 0 {
+		if path == nil || path.Length() == 0 {
 			continue
 		}
 
-		// Set the target cell to be the first walkable cell that is not our head.
+		// Set the target cell to be the first walkable cell that is not our head, that we can reach without the
+		// hazards on the way killing us, and that isn't a hazard itself.
 		if cell.X != state.You.Head.X && cell.Y != state.You.Head.Y {
+			if _, _, alive := walkPath(state, path); !alive {
+				continue
+			}
+			if hazardCount(state, Coord{X: cell.X, Y: cell.Y}) > 0 {
+				if hazardCell == nil {
+					hazardCell = cell
+				}
+				continue
+			}
 			return cell
 		}
 	}
+	if hazardCell != nil {
+		return hazardCell
+	}
 	log.Printf("No walkable cells or paths anywhere on board.\n")
 	return nil
 }
 
 // function to choose a random target cell
-func chooseRandomTargetCell(grid *Grid) *Cell {
+func chooseRandomTargetCell(grid *Grid, rng *rand.Rand) *Cell {
 	// choose a random cell from the grid.
-	return grid.AllCells()[rand.Intn(len(grid.AllCells()))]
+	return grid.AllCells()[rng.Intn(len(grid.AllCells()))]
 }
 
-// function to choose nearest food
+// function to choose nearest food, counting hazards on the way as extra distance. Food we would die in hazards
+// trying to reach, or that another snake would get to first, is never chosen, and food in our own territory is
+// preferred to food we only win by being longer.
 func chooseNearestFood(grid *Grid, state GameState) *Cell {
 	var closestFoodCell *Cell
-	closestDistance := math.MaxInt32
+	closestScore := math.Inf(1)
+	closestClaim := foodLost
+	ours := moveDistances(state, grid, state.You.Head)
+	theirs := enemyDistances(state, grid)
 
 	for _, food := range state.Board.Food {
-		if !grid.Get(food.X, food.Y).Walkable {
+		if !grid.Get(food.X, food.Y).Walkable || food.isNextToSnakeHead(state) || food.Surrounded(state) {
+			continue
+		}
+		distance, reachable := ours[food]
+		if !reachable {
+			continue
+		}
+		claim := claimFood(state, food, distance, theirs)
+		if claim == foodLost || claim > closestClaim {
 			continue
 		}
 
-		distance := abs(food.X - state.You.Head.X) + abs(food.Y - state.You.Head.Y)
+		path := grid.GetPathFromCells(grid.Get(state.You.Head.X, state.You.Head.Y), grid.Get(food.X, food.Y), false, false, state.isWrapped())
+		if path == nil || path.Length() < 2 {
+			continue
+		}
+		_, damage, alive := walkPath(state, path)
+		if !alive {
+			continue
+		}
 
-		if distance < closestDistance && !food.isNextToSnakeHead(state) && !food.Surrounded(state) {
-			closestDistance = distance
+		if score := foodScore(state, path, damage); claim < closestClaim || score < closestScore {
+			closestScore, closestClaim = score, claim
 			closestFoodCell = grid.Get(food.X, food.Y)
 		}
 	}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"log"
+)
+
+// Hunting: when a smaller snake is close and pinned against a wall or our body, look for a move that kills it.
+// A move works against one of its replies if the reply runs into our head, which we win, or leaves it less room
+// than its own length. The confidence of a move is how likely, by predictMoves, the snake is to make a reply the
+// move works against.
+
+// How close, in moves, a smaller snake's head has to be before we think about hunting it.
+const huntRange = 3
+
+// Function that returns the cell next to our head to move to when hunting, or nil if no move is confident enough.
+func huntTarget(state GameState, grid *Grid) *Cell {
+	var best *Cell
+	var bestPrey Battlesnake
+	var bestMove string
+	bestConfidence := config.HuntConfidence
+	for _, prey := range state.Board.Snakes {
+		if !isHuntable(state, prey) {
+			continue
+		}
+		for _, move := range []string{"up", "down", "left", "right"} {
+			c, ok := stepCoord(state, state.You.Head, move)
+			if !ok {
+				continue
+			}
+			// Never hunt into somewhere we can't get out of ourselves.
+			if roomFrom(state, grid, c, len(state.You.Body)) < len(state.You.Body) {
+				continue
+			}
+			cell := grid.Get(c.X, c.Y)
+			if confidence := huntConfidence(state, grid, prey, c); confidence >= bestConfidence {
+				best, bestPrey, bestMove, bestConfidence = cell, prey, move, confidence
+			}
+		}
+	}
+	if best != nil {
+		log.Printf("%s MOVE %d: hunting %s by moving %s, %.2f confident\n", sanatizeInput(state.Game.ID), isNumber(state.Turn), sanatizeInput(bestPrey.Name), bestMove, bestConfidence)
+	}
+	return best
+}
+
+// Function that reports whether a snake is worth hunting: smaller than us, close by, and with its head against a
+// wall or next to our body so it has few ways out.
+func isHuntable(state GameState, prey Battlesnake) bool {
+	if !prey.isEnemySnake(state) || len(prey.Body) == 0 || len(prey.Body) >= len(state.You.Body) {
+		return false
+	}
+	if distance(state, state.You.Head, prey.Head) > huntRange {
+		return false
+	}
+	if !state.isWrapped() && prey.onEdge(state) {
+		return true
+	}
+	for _, move := range []string{"up", "down", "left", "right"} {
+		if c, ok := stepCoord(state, prey.Head, move); ok && containsCoord(state.You.Body, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// Function that returns how likely the prey is to die if we move to c: it either moves onto c too and loses the
+// head-to-head, or moves somewhere it can't fit in.
+func huntConfidence(state GameState, grid *Grid, prey Battlesnake, c Coord) float64 {
+	confidence := 0.0
+	for move, probability := range predictMoves(state, prey) {
+		next, _ := stepCoord(state, prey.Head, move)
+		if next == c || areaFrom(state, grid, next, []Coord{state.You.Head, c}, len(prey.Body)) < len(prey.Body) {
+			confidence += probability
+		}
+	}
+	return confidence
+}
+
+// Function that returns the number of moves between two cells, going across the edges of wrapped boards.
+func distance(state GameState, a, b Coord) int {
+	dx, dy := abs(a.X-b.X), abs(a.Y-b.Y)
+	if state.isWrapped() {
+		if state.Board.Width-dx < dx {
+			dx = state.Board.Width - dx
+		}
+		if state.Board.Height-dy < dy {
+			dy = state.Board.Height - dy
+		}
+	}
+	return dx + dy
+}
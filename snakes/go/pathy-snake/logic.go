@@ -23,21 +23,35 @@ func info() BattlesnakeInfoResponse {
 // This function is called everytime the Battlesnake is entered into a game.
 // The provided GameState contains information about the game that's about to be played.
 // It's purely for informational purposes, we don't have to make any decisions here.
+// We choose the strategy for the game here, from its ruleset and map, and keep it in the game's session.
 func start(state GameState) {
-	log.Printf("%s START\n", sanatizeInput(state.Game.ID))
+	s := startSession(state)
+	log.Printf("%s START (%s)\n", sanatizeInput(state.Game.ID), s.strategy.Name())
 }
 
 // This function is called when a game the Battlesnake was in has ended.
 // It's purely for informational purposes, we don't have to make any decisions here.
 func end(state GameState) {
+	endSession(state)
+	if state.isSolo() {
+		recordSoloGame(state)
+	}
 	log.Printf("%s END\n\n", sanatizeInput(state.Game.ID))
 	log.Printf("%s After %d turns\n", sanatizeInput(state.Game.ID), isNumber(state.Turn))
-	log.Printf("%s WINNER: %s\n", sanatizeInput(state.Game.ID), sanatizeInput(state.Board.Snakes[0].Name))
+	if len(state.Board.Snakes) > 0 {
+		log.Printf("%s WINNER: %s\n", sanatizeInput(state.Game.ID), sanatizeInput(state.Board.Snakes[0].Name))
+	}
 }
 
 func move(state GameState) BattlesnakeMoveResponse {
-	nextMove := createSnakeMap(state).Move
-	log.Printf("%s MOVE %d: %s\n", sanatizeInput(state.Game.ID), isNumber(state.Turn), nextMove)
+	// The game's strategy logs the move it makes. Every pipeline ends in a strategy that always has a move, but if
+	// one doesn't, fall back to following a path.
+	s := sessionFor(state)
+	s.observe(state)
+	nextMove, ok := s.strategy.Move(state)
+	if !ok {
+		nextMove, _ = pathStrategy{}.Move(state)
+	}
 
 	return BattlesnakeMoveResponse{Move: nextMove}
 }
@@ -1,70 +1,81 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 	"log"
 	"io/ioutil"
+	"math"
 	"os"
+	"sync"
+	"time"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/asciiboard"
+	"github.com/es-na-battlesnake/snakes/snakes/go/conformance"
+	"github.com/es-na-battlesnake/snakes/snakes/go/openingbook"
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
 )
 
 // Ignore log output when testing.
 // Comment this function out to see log output when testing.
 func TestMain(m *testing.M) {
 		log.SetOutput(ioutil.Discard)
+		// Fix how far the searches look, so they don't depend on how fast the machine is or how busy it is.
+		config.DuelNodes = testDuelNodes
+		config.MCTSPlayouts = testMCTSPlayouts
 		os.Exit(m.Run())
 }
 
-func TestNeckAvoidance(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		// Length 3, facing right
-		Head: Coord{X: 2, Y: 0},
-		Body: []Coord{{X: 2, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 0}},
-	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me},
-			Height: 11,
-			Width:  11,
-		},
-		You: me,
-	}
+// Search budgets for the tests, small enough to keep them quick.
+const (
+	testDuelNodes    = 600
+	testMCTSPlayouts = 500
+)
 
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move left
-		if nextMove.Move == "left" {
-			t.Errorf("snake moved onto its own neck, %s", nextMove.Move)
-		}
-	}
+// Seeds for the games each test position is played in. The snake seeds its random choices from the game ID, so
+// a few games exercise different choices while every run of a test stays exactly the same.
+var testSeeds = []int{0, 1, 2, 3, 5, 8, 13, 21}
+
+// Returns a copy of state with a game ID made from seed.
+func seededGame(state GameState, seed int) GameState {
+	state.Game.ID = fmt.Sprintf("test-game-%d", seed)
+	return state
 }
 
-// Test body avoidance.
-func TestBodyAvoidance(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		// Length 4, facing up (U shapped)
-		Head: Coord{X: 2, Y: 1},
-		Body: []Coord{{X: 2, Y: 1}, {X: 2, Y: 0}, {X: 3, Y: 0}, {X: 3, Y: 1}, {X: 3, Y: 2}},
-	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me},
-			Height: 11,
-			Width:  11,
-		},
-		You: me,
+// Returns the move the snake makes in state in each of the testSeeds games.
+func seededMoves(state GameState) []string {
+	var moves []string
+	for _, seed := range testSeeds {
+		moves = append(moves, move(seededGame(state, seed)).Move)
 	}
+	return moves
+}
 
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move right
-		if nextMove.Move == "right" {
-			t.Errorf("snake moved onto its own body, %s", nextMove.Move)
-		}
-	}
+// Parses an ASCII board (see snakes/go/asciiboard for the format) into a GameState for this snake.
+func parseBoard(t *testing.T, board string) GameState {
+	t.Helper()
+	parsed, err := asciiboard.Parse(board)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fromRules(t, parsed)
+}
+
+// Converts a GameState from the shared rules package into this snake's own type. They have the same JSON, so
+// a round trip does it.
+func fromRules(t *testing.T, state rules.GameState) GameState {
+	t.Helper()
+	data, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var converted GameState
+	if err := json.Unmarshal(data, &converted); err != nil {
+		t.Fatal(err)
+	}
+	return converted
 }
 
 // Test that we go towards nearest food.
@@ -84,12 +95,11 @@ func TestFoodEating1(t *testing.T) {
 		},
 		You: me,
 	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
+	// Act
+	for _, nextMove := range seededMoves(state) {
 		// Assert never move left
-		if nextMove.Move != "left" {
-			t.Errorf("snake didn't move towards closest food, %s", nextMove.Move)
+		if nextMove != "left" {
+			t.Errorf("snake didn't move towards closest food, %s", nextMove)
 		}
 	}
 }
@@ -115,12 +125,11 @@ func TestFoodEating2(t *testing.T) {
 		},
 		You: me,
 	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
+	// Act
+	for _, nextMove := range seededMoves(state) {
 		// Assert never move left
-		if nextMove.Move == "left" {
-			t.Errorf("snake didn't move towards second closest food, %s", nextMove.Move)
+		if nextMove == "left" {
+			t.Errorf("snake didn't move towards second closest food, %s", nextMove)
 		}
 	}
 }
@@ -150,12 +159,11 @@ func TestFoodEating3(t *testing.T) {
 		},
 		You: me,
 	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
+	// Act
+	for _, nextMove := range seededMoves(state) {
 		// Assert never move left
-		if nextMove.Move != "up" {
-			t.Errorf("snake moved to a food next to bigger snake, %s", nextMove.Move)
+		if nextMove != "up" {
+			t.Errorf("snake moved to a food next to bigger snake, %s", nextMove)
 		}
 	}
 }
@@ -177,12 +185,11 @@ func TestFoodEating4(t *testing.T) {
 		},
 		You: me,
 	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
+	// Act
+	for _, nextMove := range seededMoves(state) {
 		// Assert never move left
-		if nextMove.Move == "left" {
-			t.Errorf("snake trapped itself trying to eat food, %s", nextMove.Move)
+		if nextMove == "left" {
+			t.Errorf("snake trapped itself trying to eat food, %s", nextMove)
 		}
 	}
 }
@@ -217,12 +224,11 @@ func TestFoodEating5(t *testing.T) {
 			},
 		},
 	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
+	// Act
+	for _, nextMove := range seededMoves(state) {
 		// Assert never move left
-		if nextMove.Move == "right" {
-			t.Errorf("snake moved to a food next to bigger snake, %s", nextMove.Move)
+		if nextMove == "right" {
+			t.Errorf("snake moved to a food next to bigger snake, %s", nextMove)
 		}
 	}
 }
@@ -257,317 +263,18 @@ func TestFoodEating6(t *testing.T) {
 			},
 		},
 	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move left
-		if nextMove.Move == "left" {
-			t.Errorf("snake moved to a food next to bigger snake, %s", nextMove.Move)
-		}
-	}
-}
-		
-// Test that we do not wrap around into our own body.
-func TestBodyWrap1(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		Head: Coord{X: 10, Y: 5},
-		Body: []Coord{{X: 10, Y: 5}, {X: 9, Y: 5}, {X: 8, Y: 5}, {X: 0, Y: 5}, {X: 1, Y: 5}},
-	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me},
-			Height: 11,
-			Width:  11,
-		},
-		You: me,
-		Game: Game{
-			Ruleset: Ruleset{
-				Name: "wrapped",
-			},
-		},
-	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move right
-		if nextMove.Move == "right" {
-			t.Errorf("snake wrapped into its own body, %s", nextMove.Move)
-		}
-	}
-}
-
-// Test that we do not wrap around the board into another snake.
-func TestBodyWrap2(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		Head: Coord{X: 10, Y: 5},
-		Body: []Coord{{X: 10, Y: 5}, {X: 9, Y: 5}, {X: 8, Y: 5}, {X: 7, Y: 5}},
-	}
-	other := Battlesnake{
-		Head: Coord{X: 0, Y: 5},
-		Body: []Coord{{X: 0, Y: 5}, {X: 0, Y: 6}, {X: 0, Y: 7}, {X: 0, Y: 8}},
-	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me, other},
-			Height: 11,
-			Width:  11,
-		},
-		You: me,
-		Game: Game{
-			Ruleset: Ruleset{
-				Name: "wrapped",
-			},
-		},
-	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move right
-		if nextMove.Move == "right" {
-			t.Errorf("snake wrapped into another snake, %s", nextMove.Move)
-		}
-	}
-}
-
-// Test that will force a snake to wrap around the board. It is basically testing that wrapping works.
-func TestBodyWrap3(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		Head: Coord{X: 10, Y: 5},
-		Body: []Coord{{X: 10, Y: 5}, {X: 10, Y: 6}, {X: 9, Y: 6}, {X: 9, Y: 5}, {X: 9, Y: 4}, {X: 10, Y: 4}, {X: 10, Y: 3}},
-		Health: 100,
-	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me},
-			Height: 11,
-			Width:  11,
-		},
-		You: me,
-		Game: Game{
-			Ruleset: Ruleset{
-				Name: "wrapped",
-			},
-		},
-	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move right
-		if nextMove.Move != "right" {
-			t.Errorf("snake wrapped into another snake, %s", nextMove.Move)
-		}
-	}
-}
-
-
-// Test that we avoid walls when not in wrapped mode.
-func TestWallAvoidance(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		Head: Coord{X: 10, Y: 5},
-		Body: []Coord{{X: 10, Y: 5}, {X: 9, Y: 5}, {X: 8, Y: 5}, {X: 7, Y: 5}},
-	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me},
-			Height: 11,
-			Width:  11,
-		},
-		You: me,
-	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move right
-		if nextMove.Move == "right" {
-			t.Errorf("snake moved into wall, %s", nextMove.Move)
-		}
-	}
-}
-
-// Test that we avoid traping ourselves in our own body.
-func TestBodyTrap1(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		Head: Coord{X: 2, Y: 1},
-		Body: []Coord{{X: 2, Y: 1}, {X: 2, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 0},
-			{X: 0, Y: 1}, {X: 0, Y: 2}, {X: 1, Y: 2}, {X: 2, Y: 2}},
-	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me},
-			Height: 11,
-			Width:  11,
-		},
-		You: me,
-	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move left
-		if nextMove.Move == "left" {
-			t.Errorf("snake trapped in own body, %s", nextMove.Move)
-		}
-	}
-}
-
-// Test that we avoid traping ourselves in another snakes body.
-func TestBodyTrap2(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		Head: Coord{X: 0, Y: 2},
-		Body: []Coord{{X: 0, Y: 2}, {X: 0, Y: 1}, {X: 0, Y: 0}},
-	}
-	other := Battlesnake{
-		Head: Coord{X: 1, Y: 0},
-		Body: []Coord{{X: 1, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 1}, {X: 2, Y: 2}, {X: 2, Y: 3}, {X: 1, Y: 3}},
-	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me, other},
-			Height: 11,
-			Width:  11,
-		},
-		You: me,
-	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move right
-		if nextMove.Move == "right" {
-			t.Errorf("snake trapped in another snake body, %s", nextMove.Move)
-		}
-	}
-}
-
-// Test that we avoid traping ourselves in a corner.
-func TestCornerTrap1(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		Head: Coord{X: 0, Y: 1},
-		Body: []Coord{{X: 0, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}},
-	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me},
-			Height: 11,
-			Width:  11,
-		},
-		You: me,
-	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move down
-		if nextMove.Move == "down" {
-			t.Errorf("snake trapped in corner, %s", nextMove.Move)
-		}
-	}
-}
-
-// Test that we avoid traping ourselves in a corner. Just another variation of the previous test.
-func TestCornerTrap2(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		Head: Coord{X: 0, Y: 9},
-		Body: []Coord{{X: 0, Y: 9}, {X: 1, Y: 9}, {X: 1, Y: 10}, {X: 2, Y: 10}, {X: 3, Y: 10}},
-		Health: 100,
-	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me},
-			Height: 11,
-			Width:  11,
-			Food:   []Coord{{X: 3, Y: 10}},
-		},
-		You: me,
-	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move up
-		if nextMove.Move == "up" {
-			t.Errorf("snake trapped in corner, %s", nextMove.Move)
-		}
-	}
-}
-
-// Test that we avoid traping ourselves in a corner when we wrap.
-func TestCornerTrap3(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		Head: Coord{X: 0, Y: 0},
-		Body: []Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}, {X: 9, Y: 0}, {X: 9, Y: 1}, {X: 10, Y: 1}, {X: 10, Y: 2}},
-		Health: 100,
-	}
-	other := Battlesnake{
-		Head: Coord{X: 10, Y: 10},
-		Body: []Coord{{X: 10, Y: 10}, {X: 9, Y: 10}, {X: 8, Y: 10}, {X: 7, Y: 10}},
-	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me, other},
-			Height: 11,
-			Width:  11,
-		},
-		You: me,
-		Game: Game{
-			Ruleset: Ruleset{
-				Name: "wrapped",
-			},
-		},
-	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move left
-		if nextMove.Move == "left" {
-			t.Errorf("snake trapped in corner while wrapping, %s", nextMove.Move)
-		}
-	}
-}
-
-// Test that we avoid traping ourselves in a corner when we wrap.
-// Same test as previous test, but testing that we don't trap in another snake.
-func TestCornerTrap4(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		Head: Coord{X: 0, Y: 0},
-		Body: []Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}},
-	}
-	other := Battlesnake{
-		Head: Coord{X: 8, Y: 0},
-		Body: []Coord{{X: 8, Y: 0}, {X: 9, Y: 0}, {X: 9, Y: 1}, {X: 10, Y: 1}, {X: 10, Y: 2}, {X: 10, Y: 10}},
-	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me, other},
-			Height: 11,
-			Width:  11,
-		},
-		You: me,
-		Game: Game{
-			Ruleset: Ruleset{
-				Name: "wrapped",
-			},
-		},
-	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
+	// Act
+	for _, nextMove := range seededMoves(state) {
 		// Assert never move left
-		if nextMove.Move == "left" {
-			t.Errorf("snake trapped in corner while wrapping, %s", nextMove.Move)
+		if nextMove == "left" {
+			t.Errorf("snake moved to a food next to bigger snake, %s", nextMove)
 		}
 	}
 }
 
 // Test that we are setting our own tail as walkable.
 func TestTailWalkable1(t *testing.T) {
-	for i := 0; i < 1000; i++ {
+	for _, seed := range testSeeds {
 		for i := 0; i < 1; i++ {
 			// Arrange
 			me := Battlesnake{
@@ -586,7 +293,7 @@ func TestTailWalkable1(t *testing.T) {
 				Turn: 0,
 				You: me,
 			}
-			nextMove := move(state)
+			nextMove := move(seededGame(state, seed))
 			// Assert never move up
 			if nextMove.Move == "down" {
 				t.Errorf("Walked on tail to early, %s", nextMove.Move)
@@ -615,7 +322,7 @@ func TestTailWalkable1(t *testing.T) {
 				Turn: 9999999,
 				You: me,
 			}
-			nextMove := move(state)
+			nextMove := move(seededGame(state, seed))
 			// Assert never move up
 			if nextMove.Move != "down" {
 				t.Errorf("Tail is not getting set as walkable, %s", nextMove.Move)
@@ -627,7 +334,7 @@ func TestTailWalkable1(t *testing.T) {
 // Test that we are setting a neighboring snake tail as walkable.
 // Multi turn test to make sure we are setting snake tails as walkable correctly.
 func TestTailWalkable2(t *testing.T) {
-	for i := 0; i < 1000; i++ {
+	for _, seed := range testSeeds {
 		for i := 0; i < 1; i++ {	
 			// Arrange
 			me := Battlesnake{
@@ -652,7 +359,7 @@ func TestTailWalkable2(t *testing.T) {
 				Turn: 0,
 				You: me,
 			}
-			nextMove := move(state)
+			nextMove := move(seededGame(state, seed))
 			// Assert never move up
 			if nextMove.Move != "right" {
 				t.Errorf("Tail is not getting set as walkable, %s", nextMove.Move)
@@ -677,12 +384,13 @@ func TestTailWalkable2(t *testing.T) {
 					Snakes: []Battlesnake{me, other},
 					Height: 11,
 					Width:  11,
-					Food:   []Coord{{X: 3, Y: 10}},
+					// Close enough that we get there no later than the other snake, which we are longer than.
+					Food:   []Coord{{X: 5, Y: 6}},
 				},
 				Turn: 9999999,
 				You: me,
 			}
-			nextMove := move(state)
+			nextMove := move(seededGame(state, seed))
 			// Assert never move up
 			if nextMove.Move != "up" {
 				t.Errorf("Tail is not getting set as walkable, %s", nextMove.Move)
@@ -694,7 +402,7 @@ func TestTailWalkable2(t *testing.T) {
 // Test that we don't set a tail next to a head as walkable.
 // Multi turn test to make sure we are setting snake tails as walkable correctly.
 func TestTailWalkable3(t *testing.T) {
-	for i := 0; i < 1000; i++ {
+	for _, seed := range testSeeds {
 		for i := 0; i < 1; i++ {	
 			// Arrange
 			me := Battlesnake{
@@ -719,7 +427,7 @@ func TestTailWalkable3(t *testing.T) {
 				Turn: 0,
 				You: me,
 			}
-			nextMove := move(state)
+			nextMove := move(seededGame(state, seed))
 			// Assert never move up
 			if nextMove.Move == "down" {
 				t.Errorf("Tail incorrectly set as walkable, %s", nextMove.Move)
@@ -749,7 +457,7 @@ func TestTailWalkable3(t *testing.T) {
 				Turn: 9999999,
 				You: me,
 			}
-			nextMove := move(state)
+			nextMove := move(seededGame(state, seed))
 			// Assert never move up
 			if nextMove.Move == "down" {
 				t.Errorf("Tail incorrectly set as walkable, %s", nextMove.Move)
@@ -761,7 +469,7 @@ func TestTailWalkable3(t *testing.T) {
 // Test that we don't walk on a tail if snake eats twice in a row. 
 // Multi turn test to make sure we are setting snake tails as walkable correctly.
 func TestTailWalkable4(t *testing.T) {
-	for i := 0; i < 1000; i++ {
+	for _, seed := range testSeeds {
 		for i := 0; i < 1; i++ {	
 			// Arrange
 			me := Battlesnake{
@@ -786,7 +494,7 @@ func TestTailWalkable4(t *testing.T) {
 				Turn: 0,
 				You: me,
 			}
-			nextMove := move(state)
+			nextMove := move(seededGame(state, seed))
 			// Assert never move up
 			if nextMove.Move != "down" {
 				t.Errorf("Tail incorrectly set as walkable, %s", nextMove.Move)
@@ -816,7 +524,7 @@ func TestTailWalkable4(t *testing.T) {
 				Turn: 9999999,
 				You: me,
 			}
-			nextMove := move(state)
+			nextMove := move(seededGame(state, seed))
 			// Assert never move up
 			if nextMove.Move != "down" {
 				t.Errorf("Tail incorrectly set as walkable, %s", nextMove.Move)
@@ -824,3 +532,1192 @@ func TestTailWalkable4(t *testing.T) {
 		}
 	}
 }
+
+// Test that a game is exactly reproducible from its ID, and that the seed override replaces the ID.
+func TestSeededGameIsReproducible(t *testing.T) {
+	// Arrange
+	me := Battlesnake{
+		Head:   Coord{X: 5, Y: 5},
+		Body:   []Coord{{X: 5, Y: 5}, {X: 5, Y: 4}, {X: 5, Y: 3}},
+		Health: 100,
+		ID:     "me",
+	}
+	state := GameState{
+		Game: Game{ID: "reproducible"},
+		Board: Board{
+			Snakes: []Battlesnake{me},
+			Height: 11,
+			Width:  11,
+		},
+		You: me,
+	}
+
+	// Act
+	first := move(state)
+	second := move(state)
+
+	// Assert
+	if first.Move != second.Move {
+		t.Errorf("same game and turn gave different moves, %s and %s", first.Move, second.Move)
+	}
+	if gameSeed(state) == gameSeed(seededGame(state, 1)) {
+		t.Errorf("different games should get different seeds")
+	}
+	os.Setenv(seedEnv, "pinned")
+	defer os.Unsetenv(seedEnv)
+	if gameSeed(state) != gameSeed(seededGame(state, 1)) {
+		t.Errorf("%s should override the game ID", seedEnv)
+	}
+}
+
+// Test that a config file only overrides the weights it sets, and that bad weights are rejected.
+func TestLoadConfig(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	partial := dir + "/partial.json"
+	invalid := dir + "/invalid.json"
+	ioutil.WriteFile(partial, []byte(`{"foodCost": 0.25, "hungryHealth": 50}`), 0644)
+	negativeDepth := dir + "/negative-depth.json"
+	unknownStrategy := dir + "/unknown-strategy.json"
+	negativeBudget := dir + "/negative-budget.json"
+	ioutil.WriteFile(invalid, []byte(`{"hazardCost": -1}`), 0644)
+	ioutil.WriteFile(negativeDepth, []byte(`{"duelDepth": -1}`), 0644)
+	ioutil.WriteFile(unknownStrategy, []byte(`{"strategy": "guess"}`), 0644)
+	ioutil.WriteFile(negativeBudget, []byte(`{"duelNodes": -1}`), 0644)
+
+	// Act
+	loaded, err := loadConfig(partial)
+	_, invalidErr := loadConfig(invalid)
+	_, negativeDepthErr := loadConfig(negativeDepth)
+	_, unknownStrategyErr := loadConfig(unknownStrategy)
+	_, negativeBudgetErr := loadConfig(negativeBudget)
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.FoodCost != 0.25 || loaded.HungryHealth != 50 || loaded.LargerHeadCost != defaultConfig.LargerHeadCost {
+		t.Errorf("config did not merge with the defaults, %+v", loaded)
+	}
+	if invalidErr == nil {
+		t.Errorf("expected a negative cost to be rejected")
+	}
+	if negativeDepthErr == nil {
+		t.Errorf("expected a negative duel depth to be rejected")
+	}
+	if unknownStrategyErr == nil {
+		t.Errorf("expected an unknown strategy to be rejected")
+	}
+	if negativeBudgetErr == nil {
+		t.Errorf("expected a negative search budget to be rejected")
+	}
+}
+
+// Test that the duel search takes a head-to-head it is sure to win when the other snake has nowhere else to go.
+func TestDuelTakesWin(t *testing.T) {
+	// Arrange
+	state := parseBoard(t, `
+		B . . . . . . . . . .
+		b A . . . . . . . . .
+		b a . . . . . . . . .
+		. a . . . . . . . . .
+		. a . . . . . . . . .
+		. . . . . . . . . . .
+		. . . . . . . . . . .
+		. . . . . . . . . . .
+		. . . . . . . . . . .
+		. . . . . . . . . . .
+		. . . . . . . . . . .
+	`)
+
+	// Act
+	nextMove, depth, ok := duelMove(state)
+
+	// Assert
+	if !ok || nextMove != "up" {
+		t.Errorf("expected the search to move up onto the only cell the shorter snake can reach, got %s (ok %v)", nextMove, ok)
+	}
+	if depth != 1 {
+		t.Errorf("expected the search to stop once the win was certain, searched %d turns", depth)
+	}
+}
+
+// Test that the duel search only runs with exactly one other snake, when it is turned on, and gives up if it
+// runs out of time so move falls back to the path.
+func TestDuelFallsBack(t *testing.T) {
+	// Arrange
+	duel := parseBoard(t, `
+		. . . . .
+		. a A . .
+		. . . . .
+		. b B . .
+		. . . . .
+	`)
+	crowded := parseBoard(t, `
+		. . . . .
+		. a A . .
+		. . . . .
+		. b B . .
+		. c C . .
+	`)
+	defer func(depth int) { config.DuelDepth = depth }(config.DuelDepth)
+	engine, err := rules.ResumeEngine(rules.Game{Ruleset: rules.Ruleset{Name: rules.RulesetStandard}}, 0, toRulesBoard(duel.Board), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	late := duelSearch{me: "A", them: "B", deadline: time.Now().Add(-time.Second)}
+
+	// Act
+	_, _, duelOK := duelMove(duel)
+	_, _, crowdedOK := duelMove(crowded)
+	_, _, lateOK := late.iterate(engine, 4)
+	config.DuelDepth = 0
+	_, _, offOK := duelMove(duel)
+
+	// Assert
+	if !duelOK {
+		t.Errorf("expected the search to run with one other snake")
+	}
+	if crowdedOK {
+		t.Errorf("expected the search not to run with two other snakes")
+	}
+	if lateOK {
+		t.Errorf("expected the search to give up after the deadline")
+	}
+	if offOK {
+		t.Errorf("expected a duel depth of 0 to turn the search off")
+	}
+}
+
+// Test that the node budget, not the clock, decides how deep the duel search gets, so the same board always gets
+// the same answer.
+func TestDuelNodeBudget(t *testing.T) {
+	// Arrange
+	state := parseBoard(t, `
+		. . . . . . .
+		. a A . . . .
+		. . . . . . .
+		. . . . . . .
+		. . . . . . .
+		. b B . . . .
+		. . . . . . .
+	`)
+	engine, err := rules.ResumeEngine(rules.Game{Ruleset: rules.Ruleset{Name: rules.RulesetStandard}}, 0, toRulesBoard(state.Board), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	search := func(budget int) (duelSearch, string, int) {
+		s := duelSearch{me: "A", them: "B", budget: budget, deadline: time.Now().Add(time.Minute)}
+		nextMove, depth, _ := s.iterate(engine, 6)
+		return s, nextMove, depth
+	}
+
+	// Act
+	small, smallMove, smallDepth := search(50)
+	again, againMove, againDepth := search(50)
+	_, _, largeDepth := search(5000)
+
+	// Assert
+	if small.nodes > 50 {
+		t.Errorf("expected the search to stop at 50 nodes, played %d", small.nodes)
+	}
+	if smallMove != againMove || smallDepth != againDepth || small.nodes != again.nodes {
+		t.Errorf("expected the same budget to give the same search, got %s at %d and %s at %d", smallMove, smallDepth, againMove, againDepth)
+	}
+	if smallDepth == 0 || smallDepth >= largeDepth {
+		t.Errorf("expected a bigger budget to search deeper, got %d turns and %d turns", smallDepth, largeDepth)
+	}
+}
+
+// Test the shared conformance scenarios. Run with -v to see the scorecard for every capability.
+func TestConformance(t *testing.T) {
+	conformance.Run(t, conformance.StrategyFunc(func(state rules.GameState) string { return move(fromRules(t, state)).Move }),
+		conformance.BasicSafety, conformance.WrappedSeams, conformance.HeadToHead, conformance.Traps)
+}
+
+// Test that MCTS stays off the side of a wrapped board that leads into a pocket another snake has closed off.
+func TestMCTSAvoidsPocket(t *testing.T) {
+	// Arrange
+	state := parseBoard(t, `
+		ruleset: wrapped
+
+		. . . . . . . . . c c
+		. . . . . . . . . . c
+		. . . . . . . . . . C
+		. . . . . . . . . . .
+		. . . . . . . . . . .
+		. . . . . . . . . . .
+		. . . . . . . . . . .
+		. . . . . . . . . . .
+		. . . . . . . . . . b
+		. . . . . . . . . b b
+		A a a a . . . . B b .
+	`)
+	engine, ok := searchEngine(state)
+	if !ok {
+		t.Fatal("expected the engine to load the board")
+	}
+
+	for i := 0; i < 5; i++ {
+		// Act
+		search := newMCTS(engine, state.You.ID, newGameRand(seededGame(state, i)))
+		search.run(time.Now().Add(time.Minute), 2000)
+		nextMove, ok := search.best()
+
+		// Assert
+		if !ok || nextMove == "left" {
+			t.Errorf("expected MCTS not to wrap left into the pocket, got %s (ok %v)", nextMove, ok)
+		}
+	}
+}
+
+// Test that MCTS only runs when the config selects it and there are at least three snakes, and that it counts
+// its playouts.
+func TestMCTSMove(t *testing.T) {
+	// Arrange
+	crowded := parseBoard(t, `
+		. . . . . . .
+		. a A . . . .
+		. . . . . . .
+		. b B . . . .
+		. . . . . . .
+		. c C . . . .
+		. . . . . . .
+	`)
+	duel := parseBoard(t, `
+		. . . . .
+		. a A . .
+		. . . . .
+		. b B . .
+		. . . . .
+	`)
+	defer func(strategy string) { config.Strategy = strategy }(config.Strategy)
+	before := mctsPlayouts.Value()
+
+	// Act
+	_, _, pathOK := mctsMove(crowded)
+	config.Strategy = strategyMCTS
+	nextMove, playouts, crowdedOK := mctsMove(crowded)
+	_, _, duelOK := mctsMove(duel)
+
+	// Assert
+	if pathOK {
+		t.Errorf("expected MCTS not to run unless the config selects it")
+	}
+	if !crowdedOK || !rules.IsValidMove(nextMove) || playouts == 0 {
+		t.Errorf("expected MCTS to pick a move with three snakes, got %q after %d playouts", nextMove, playouts)
+	}
+	if duelOK {
+		t.Errorf("expected MCTS to leave a duel to the duel search")
+	}
+	if mctsPlayouts.Value() != before+int64(playouts) {
+		t.Errorf("expected the metrics to count %d playouts, went from %d to %d", playouts, before, mctsPlayouts.Value())
+	}
+}
+
+// Test that the head-to-head model finds which snakes can reach each of our moves, whether we would win, tie or
+// lose, and how likely they are to go there.
+func TestHeadToHeadRisks(t *testing.T) {
+	// Arrange
+	// B is as long as us and can only go down or left. C is shorter and can go up, left or right.
+	state := parseBoard(t, `
+		. . . B b b b
+		. . . . . . .
+		. . . A . C .
+		. . . a . c .
+		. . . a . . .
+		. . . a . . .
+		. . . . . . .
+	`)
+
+	// Act
+	risks := make(map[string]headToHeadRisk)
+	for _, risk := range headToHeadRisks(state) {
+		risks[risk.move] = risk
+	}
+
+	// Assert
+	if len(risks) != 4 {
+		t.Fatalf("expected a risk for every move, got %v", risks)
+	}
+	if up := risks["up"]; len(up.threats) != 1 || up.threats[0].outcome != headToHeadTie || up.death() != 0.5 {
+		t.Errorf("expected an even chance of a tie with B above us, got %+v", up.threats)
+	}
+	if right := risks["right"]; len(right.threats) != 1 || right.threats[0].outcome != headToHeadWin || right.death() != 0 || math.Abs(right.kill()-1.0/3) > 1e-9 {
+		t.Errorf("expected a one in three chance of beating C to the right, got %+v", right.threats)
+	}
+	if len(risks["down"].threats) != 0 || len(risks["left"].threats) != 0 {
+		t.Errorf("expected nobody to reach down or left, got %+v and %+v", risks["down"].threats, risks["left"].threats)
+	}
+}
+
+// Test that the path isn't followed into a likely tie when there is another way, even when it leads to food.
+func TestHeadToHeadVeto(t *testing.T) {
+	// Arrange
+	state := parseBoard(t, `
+		health: A=10
+
+		. . . . . . .
+		. . . b . . .
+		. . . b . . .
+		. . . B . . .
+		. . . * . . .
+		. . . A . . .
+		. . . a a . .
+	`)
+
+	for i := 0; i < 100; i++ {
+		// Act
+		nextMove := createSnakeMap(seededGame(state, i), newGameRand(seededGame(state, i)))
+
+		// Assert
+		if nextMove.Move == "up" {
+			t.Errorf("snake moved where an equal snake can move too, %s", nextMove.Move)
+		}
+	}
+}
+
+// Test that we go for the kill when a smaller snake is pinned in a corner: moving down leaves it one cell.
+func TestHuntCornered(t *testing.T) {
+	// Arrange
+	state := parseBoard(t, `
+		. . . . . . .
+		. . . . . . .
+		. . . . . . .
+		b . . . . . .
+		b a a a . . .
+		B A . . . . .
+		. . . . . . .
+	`)
+
+	// Act once for each of 100 seeded games so every random choice is exercised reproducibly
+	for i := 0; i < 100; i++ {
+		nextMove := createSnakeMap(seededGame(state, i), newGameRand(seededGame(state, i)))
+		// Assert always move down to close the corner off
+		if nextMove.Move != "down" {
+			t.Errorf("snake didn't cut off the cornered snake, %s", nextMove.Move)
+		}
+	}
+}
+
+// Test that we only hunt snakes smaller than us, and that hunting can be turned off.
+func TestHuntTarget(t *testing.T) {
+	// Arrange
+	cornered := parseBoard(t, `
+		. . . . . . .
+		. . . . . . .
+		. . . . . . .
+		b . . . . . .
+		b a a a . . .
+		B A . . . . .
+		. . . . . . .
+	`)
+	larger := parseBoard(t, `
+		. . . . . . .
+		. . . . . . .
+		b . . . . . .
+		b . . . . . .
+		b a a . . . .
+		B A . . . . .
+		. . . . . . .
+	`)
+	grid := func(state GameState) *Grid {
+		grid := NewGrid(state.Board.Width, state.Board.Height, 0, 0)
+		addSnakesToGrid(state, grid)
+		return grid
+	}
+	defer func(confidence float64) { config.HuntConfidence = confidence }(config.HuntConfidence)
+
+	// Act
+	target := huntTarget(cornered, grid(cornered))
+	largerTarget := huntTarget(larger, grid(larger))
+	config.HuntConfidence = 1.1
+	offTarget := huntTarget(cornered, grid(cornered))
+
+	// Assert
+	if target == nil || target.X != 1 || target.Y != 0 {
+		t.Errorf("expected to hunt by moving below our head, got %v", target)
+	}
+	if largerTarget != nil {
+		t.Errorf("expected not to hunt a snake longer than us, got %v", largerTarget)
+	}
+	if offTarget != nil {
+		t.Errorf("expected a confidence above 1 to turn hunting off, got %v", offTarget)
+	}
+}
+
+// Test that we don't go for food the hazards on the way would kill us before we got to, even when it is closest.
+func TestHazardFoodOutOfReach(t *testing.T) {
+	// Arrange
+	state := parseBoard(t, `
+		ruleset: royale
+		health: A=20
+
+		. . . . . . .
+		. . . . . . .
+		x x x x x . .
+		x x * x x . .
+		x x x x x . .
+		A a a . . . .
+		. . . . . . *
+	`)
+	grid := NewGrid(state.Board.Width, state.Board.Height, 0, 0)
+	addSnakesToGrid(state, grid)
+	addFoodToGrid(state, grid)
+	changeHazardsCost(state, grid)
+
+	// Act
+	target := chooseNearestFood(grid, state)
+
+	// Assert
+	if target == nil || target.X != 6 || target.Y != 0 {
+		t.Errorf("expected to go for the food outside the hazards, got %v", target)
+	}
+}
+
+// Test that a random target is never a hazard cell while there is somewhere else to go.
+func TestRandomTargetAvoidsHazards(t *testing.T) {
+	// Arrange
+	state := parseBoard(t, `
+		ruleset: royale
+
+		x x x x x
+		x x x x x
+		x x x . .
+		x x x . .
+		a a A . .
+	`)
+	grid := NewGrid(state.Board.Width, state.Board.Height, 0, 0)
+	addSnakesToGrid(state, grid)
+	changeHazardsCost(state, grid)
+
+	for i := 0; i < 100; i++ {
+		// Act
+		target := chooseRandomWalkableTargetCell(grid, state, newGameRand(seededGame(state, i)))
+
+		// Assert
+		if target == nil || hazardCount(state, Coord{X: target.X, Y: target.Y}) > 0 {
+			t.Errorf("expected a target outside the hazards, got %v", target)
+		}
+	}
+}
+
+// Test that we leave food another snake gets to no later than us, and win ties for food only by being longer.
+func TestContestedFood(t *testing.T) {
+	// Arrange
+	board := `
+		health: A=20
+
+		b . . . . . .
+		b . . . . . .
+		B . . . . . .
+		. . * . . * .
+		. . . . . . .
+		. . . A a a .
+		. . . . . . .
+	`
+	state := parseBoard(t, board)
+	longer := parseBoard(t, "length: A=4\n"+board)
+	contested, ours := Coord{X: 2, Y: 3}, Coord{X: 5, Y: 3}
+	claims := func(state GameState) (*Cell, foodClaim, foodClaim) {
+		grid := NewGrid(state.Board.Width, state.Board.Height, 0, 0)
+		addSnakesToGrid(state, grid)
+		addFoodToGrid(state, grid)
+		distances, theirs := moveDistances(state, grid, state.You.Head), enemyDistances(state, grid)
+		return chooseNearestFood(grid, state), claimFood(state, contested, distances[contested], theirs), claimFood(state, ours, distances[ours], theirs)
+	}
+
+	// Act
+	target, contestedClaim, oursClaim := claims(state)
+	_, longerContestedClaim, _ := claims(longer)
+
+	// Assert
+	if target == nil || target.X != ours.X || target.Y != ours.Y {
+		t.Errorf("expected to go for the food only we can reach first, got %v", target)
+	}
+	if contestedClaim != foodLost || oursClaim != foodOurs {
+		t.Errorf("expected the closer food to be lost to a tie and the other to be ours, got %v and %v", contestedClaim, oursClaim)
+	}
+	if longerContestedClaim != foodWonTie {
+		t.Errorf("expected to win the tie for the closer food once we are longer, got %v", longerContestedClaim)
+	}
+}
+
+// Test that the route goes around stacked hazards that would kill us even when going through them is cheapest,
+// and that food on the way counts as a refill.
+func TestHealthyPath(t *testing.T) {
+	// Arrange
+	sauce := parseBoard(t, `
+		ruleset: royale
+		health: A=20
+
+		. . . . .
+		. . . . .
+		. . . . .
+		. . . . .
+		A xx xx . .
+	`)
+	starving := parseBoard(t, `
+		health: A=2
+
+		. . . . .
+		. . . . .
+		. . . . .
+		. . . . .
+		A * . . .
+	`)
+	// Hazards cost the same as any other cell, so only health keeps the route out of them.
+	grid := func(state GameState) *Grid {
+		grid := NewGrid(state.Board.Width, state.Board.Height, 0, 0)
+		addSnakesToGrid(state, grid)
+		return grid
+	}
+	sauceGrid, starvingGrid := grid(sauce), grid(starving)
+
+	// Act
+	cheapest := sauceGrid.GetPathFromCells(sauceGrid.Get(0, 0), sauceGrid.Get(3, 0), false, false, false)
+	path, health := healthyPath(sauce, sauceGrid, sauceGrid.Get(3, 0))
+	refilled, refilledHealth := healthyPath(starving, starvingGrid, starvingGrid.Get(4, 0))
+
+	// Assert
+	if cheapest.Length() != 4 {
+		t.Fatalf("expected the cheapest path to go straight through the hazards, got %d cells", cheapest.Length())
+	}
+	if path == nil || path.Length() != 6 || health != 15 {
+		t.Fatalf("expected a five move detour arriving with 15 health, got %v arriving with %d", path, health)
+	}
+	for _, cell := range path.Cells {
+		if hazardCount(sauce, Coord{X: cell.X, Y: cell.Y}) > 0 {
+			t.Errorf("expected the route to stay out of the hazards, went through %v", cell)
+		}
+	}
+	if refilled == nil || refilledHealth != 97 {
+		t.Errorf("expected to eat on the way and arrive with 97 health, got %v arriving with %d", refilled, refilledHealth)
+	}
+}
+
+// Test that a healthy path never goes back through a cell it has already been on, even though eating on the way
+// would leave enough health to get to the target from there.
+func TestHealthyPathNoLoops(t *testing.T) {
+	// Arrange
+	state := parseBoard(t, `
+		health: A=2
+
+		* A . . . . .
+	`)
+	grid := NewGrid(state.Board.Width, state.Board.Height, 0, 0)
+	addSnakesToGrid(state, grid)
+
+	// Act
+	path, health := healthyPath(state, grid, grid.Get(6, 0))
+
+	// Assert
+	if path != nil {
+		t.Errorf("expected no path, the only one eats and comes back through our head, got %v arriving with %d", path.Cells, health)
+	}
+}
+
+// Test that with barely room for us we stay where we can reach our tail, rather than go for the food in the
+// pocket above.
+func TestSurvivalMode(t *testing.T) {
+	// Arrange
+	state := parseBoard(t, `
+		turn: 10
+		health: A=50
+
+		* a3 a4 a5 a6
+		. a2 . . a7
+		A a1 . . a8
+		. . . . a9
+		. . . . .
+	`)
+	// A game of its own, so what other tests saw of snake A's health doesn't decide whether its tail is free.
+	state.Game.ID = "survival-mode"
+	grid := NewGrid(state.Board.Width, state.Board.Height, 0, 0)
+	addSnakesToGrid(state, grid)
+	tight, room := inTightSpace(state, grid)
+
+	// Act once for each of 100 seeded games so every random choice is exercised reproducibly
+	for i := 0; i < 100; i++ {
+		nextMove := move(seededGame(state, i))
+		// Assert always move down, towards the tail
+		if nextMove.Move != "down" {
+			t.Errorf("snake moved into a pocket it can't reach its tail from, %s", nextMove.Move)
+		}
+	}
+	if !tight || room != 16 {
+		t.Errorf("expected 16 cells of room to be a tight space for a length of 10, got %d (tight %v)", room, tight)
+	}
+}
+
+// Test that survival mode starts when the room we can reach is small for our length, whatever our health, and
+// gives way to the food we can reach once our health only just gets us there.
+func TestSurvivalModeTarget(t *testing.T) {
+	// Arrange
+	tight := `
+		* a3 a4 a5 a6
+		. a2 . . a7
+		A a1 . . a8
+		. . . . a9
+		. . . . .
+	`
+	noFood := `
+		. a3 a4 a5 a6
+		. a2 . . a7
+		A a1 . . a8
+		. . . . a9
+		. . . . .
+	`
+	roomy := `
+		* . . . .
+		. . . . .
+		A a1 a2 . .
+		. . . . .
+		. . . . .
+	`
+	positions := []struct {
+		name   string
+		board  string
+		health string
+		target *Coord
+	}{
+		{"tight with health to spare", tight, "A=50", &Coord{X: 0, Y: 1}},
+		{"tight with only just the health to reach the food", tight, "A=4", nil},
+		{"tight with no food in reach and little health", noFood, "A=5", &Coord{X: 0, Y: 1}},
+		{"room for our length however little health we have", roomy, "A=5", nil},
+		{"room for our length with more health than room", roomy, "A=100", nil},
+	}
+
+	for i, p := range positions {
+		state := parseBoard(t, "turn: 10\nhealth: "+p.health+"\n"+p.board)
+		state.Game.ID = fmt.Sprintf("survival-mode-target-%d", i)
+		grid := NewGrid(state.Board.Width, state.Board.Height, 0, 0)
+		addSnakesToGrid(state, grid)
+
+		// Act
+		target := survivalModeTarget(state, grid)
+
+		// Assert
+		switch {
+		case p.target == nil && target != nil:
+			t.Errorf("%s: expected no survival target, got %d,%d", p.name, target.X, target.Y)
+		case p.target != nil && (target == nil || target.X != p.target.X || target.Y != p.target.Y):
+			t.Errorf("%s: expected survival target %v, got %v", p.name, *p.target, target)
+		}
+	}
+}
+
+func TestStrategyFor(t *testing.T) {
+	// Arrange
+	games := []struct {
+		ruleset, gameMap, expected string
+	}{
+		{"standard", "standard", "book+duel+mcts+ensemble"},
+		{"wrapped", "", "book+duel+mcts+ensemble"},
+		{"solo", "", "solo+path"},
+		{"constrictor", "", "duel+mcts+constrictor+path"},
+		{"arcade-maze", "", "path"},
+		{"wrapped", "hz_rivers_bridges", "path"},
+		{"something-new", "", "duel+mcts+ensemble"},
+	}
+
+	for _, game := range games {
+		state := GameState{Game: Game{Ruleset: Ruleset{Name: game.ruleset}, Map: game.gameMap}}
+
+		// Act
+		name := strategyFor(state).Name()
+
+		// Assert
+		if name != game.expected {
+			t.Errorf("%s/%s: expected %s, got %s", game.ruleset, game.gameMap, game.expected, name)
+		}
+	}
+}
+
+func TestSessionStrategy(t *testing.T) {
+	// Arrange
+	state := GameState{Game: Game{ID: "session-strategy", Ruleset: Ruleset{Name: "solo"}}}
+
+	// Act
+	start(state)
+	started := sessionFor(state)
+	end(state)
+	restarted := sessionFor(state)
+	endSession(state)
+
+	// Assert
+	if started.strategy.Name() != "solo+path" {
+		t.Errorf("expected the solo game to be played with the solo cycle, got %s", started.strategy.Name())
+	}
+	if restarted == started {
+		t.Errorf("expected the session to be forgotten when the game ended")
+	}
+}
+
+// Test that squad teammates played by this server keep separate sessions for the same game, so their requests
+// can be handled at the same time. Run with -race to check that they don't share anything.
+func TestSquadSessions(t *testing.T) {
+	// Arrange
+	board := inSquads(parseBoard(t, `
+		. . . . . . .
+		. A a a . . .
+		. . . . . . .
+		. B b b . . .
+		. . . . . . .
+		. C c c . . .
+		. . . . . . .
+	`), map[string]string{"A": "red", "B": "red", "C": "blue"}, true)
+	board.Game.ID = "squad-sessions"
+	teammates := []GameState{board, board}
+	teammates[1].You = board.Board.Snakes[1]
+
+	// Act
+	var wg sync.WaitGroup
+	for _, state := range teammates {
+		wg.Add(1)
+		go func(state GameState) {
+			defer wg.Done()
+			start(state)
+			for turn := 1; turn <= 3; turn++ {
+				state.Turn = turn
+				move(state)
+			}
+		}(state)
+	}
+	wg.Wait()
+	first, second := sessionFor(teammates[0]), sessionFor(teammates[1])
+	endSession(teammates[0])
+	endSession(teammates[1])
+
+	// Assert
+	if first == second {
+		t.Errorf("expected each teammate to have its own session")
+	}
+	if first.previous == nil || second.previous == nil || first.previous.You.ID != "A" || second.previous.You.ID != "B" {
+		t.Errorf("expected each session to remember its own snake's last turn")
+	}
+}
+
+// Test that two requests for the same snake in the same game can run at once, as when one that timed out is
+// still running as the next arrives. Run with -race to check that they don't change the session under each other.
+func TestOverlappingMoves(t *testing.T) {
+	// Arrange
+	board := parseBoard(t, `
+		ruleset: wrapped
+
+		. . . . . . . . . . . . . . . . . . .
+		. A a a . . . . . . . . . . . . . . .
+		. . . . . . . . . . . . . . . . . . .
+		b b B . . . . . . . . . . . . . . . .
+		. . . . . . . . . . . . . . . . . . .
+		. C c c . . . . . . . . . . . . . . .
+		. . . . . . . . . . . . . . . . . . .
+	`)
+	board.Game.ID = "overlapping-moves"
+	// B moves right every turn, round the board. C is gone after turn 8, so with the duel search off the later turns
+	// only follow paths, which is quick next to the MCTS search of turn 8.
+	turn := func(n int) GameState {
+		state := board
+		state.Turn = n
+		state.Board.Snakes = nil
+		for _, snake := range board.Board.Snakes {
+			if snake.ID == "C" && n > 8 {
+				continue
+			}
+			if snake.ID == "B" {
+				body := make([]Coord, len(snake.Body))
+				for j, c := range snake.Body {
+					body[j] = Coord{X: (c.X + n) % board.Board.Width, Y: c.Y}
+				}
+				snake.Body, snake.Head = body, body[0]
+			}
+			state.Board.Snakes = append(state.Board.Snakes, snake)
+		}
+		return state
+	}
+	defer func(strategy string) { config.Strategy = strategy }(config.Strategy)
+	config.Strategy = strategyMCTS
+	start(board)
+	defer endSession(board)
+
+	// Learn enough of B's moves for the search to play it by its model.
+	for n := 1; n <= 7; n++ {
+		move(turn(n))
+	}
+
+	// Act: the request for turn 8 searches for as long as it is allowed, and is still searching while the later
+	// turns arrive and learn from B's moves
+	defer func(playouts, depth int) { config.MCTSPlayouts, config.DuelDepth = playouts, depth }(config.MCTSPlayouts, config.DuelDepth)
+	config.MCTSPlayouts, config.DuelDepth = 0, 0
+	searched := make(chan struct{})
+	go func() {
+		defer close(searched)
+		move(turn(8))
+	}()
+	last := 8
+	for searching := true; searching; {
+		select {
+		case <-searched:
+			searching = false
+		default:
+			last++
+			move(turn(last))
+		}
+	}
+
+	// Assert
+	if model := opponentModelFor(board, "B"); model == nil || model.moves < 5 {
+		t.Errorf("expected the session to have learnt from B's moves, got %+v", model)
+	}
+}
+
+func TestConstrictorTails(t *testing.T) {
+	// Arrange
+	board := `
+		turn: 10
+
+		. . . . .
+		A a1 a2 . .
+		. . . . .
+		B b1 b2 . .
+		. . . . .
+	`
+	standard := parseBoard(t, board)
+	constrictor := parseBoard(t, "ruleset: constrictor\n"+board)
+	// Games of their own, so what other tests saw of snake B's health doesn't decide whether its tail is free.
+	standard.Game.ID = "constrictor-tails-standard"
+	constrictor.Game.ID = "constrictor-tails"
+	standardGrid := NewGrid(standard.Board.Width, standard.Board.Height, 0, 0)
+	constrictorGrid := NewGrid(constrictor.Board.Width, constrictor.Board.Height, 0, 0)
+
+	// Act
+	addSnakesToGrid(standard, standardGrid)
+	addSnakesToGrid(constrictor, constrictorGrid)
+
+	// Assert
+	if !standardGrid.Get(2, 1).Walkable {
+		t.Errorf("expected the tail of a snake that didn't eat to be walkable in standard")
+	}
+	if constrictorGrid.Get(2, 1).Walkable {
+		t.Errorf("expected tails never to be walkable in constrictor")
+	}
+}
+
+func TestConstrictorMove(t *testing.T) {
+	// Arrange
+	state := parseBoard(t, `
+		ruleset: constrictor
+		turn: 10
+
+		. . a3 a4 a5 a6 a7
+		. . a2 . . . .
+		. . a1 . . . .
+		. . A . . . .
+		. . b2 . . . .
+		. . b1 . . . .
+		. . B . . . .
+	`)
+	state.Game.ID = "constrictor-move"
+	grid := NewGrid(state.Board.Width, state.Board.Height, 0, 0)
+	addSnakesToGrid(state, grid)
+
+	// Act
+	nextMove, territory, ok := constrictorMove(state, grid)
+
+	// Assert
+	if !ok || nextMove != "right" {
+		t.Errorf("expected to move into the larger space on the right, got %s", nextMove)
+	}
+	// B gets to the bottom of the right side first.
+	if territory < 12 {
+		t.Errorf("expected the top of the right side to be ours, got %d cells", territory)
+	}
+}
+
+func TestHamiltonianCycle(t *testing.T) {
+	// Arrange
+	boards := []struct{ width, height, cells int }{
+		{4, 4, 16}, {6, 5, 30}, {5, 6, 30}, {7, 7, 48}, {11, 11, 120}, {19, 19, 360},
+	}
+
+	for _, board := range boards {
+		// Act
+		cycle := hamiltonianCycle(board.width, board.height)
+
+		// Assert every cell is visited once and each step, including back to the start, is to a neighbour.
+		seen := make(map[Coord]bool)
+		for i, c := range cycle {
+			next := cycle[(i+1)%len(cycle)]
+			if seen[c] || c.X < 0 || c.X >= board.width || c.Y < 0 || c.Y >= board.height || abs(c.X-next.X)+abs(c.Y-next.Y) != 1 {
+				t.Errorf("%dx%d: bad step from %v to %v", board.width, board.height, c, next)
+				break
+			}
+			seen[c] = true
+		}
+		if len(cycle) != board.cells {
+			t.Errorf("%dx%d: expected %d cells on the cycle, got %d", board.width, board.height, board.cells, len(cycle))
+		}
+	}
+}
+
+func TestSoloSurvival(t *testing.T) {
+	// Arrange
+	game := rules.Game{ID: "solo-survival", Ruleset: rules.Ruleset{Name: rules.RulesetSolo, Settings: rules.DefaultSettings()}}
+	engine, err := rules.NewEngine(game, 7, 7, []rules.Battlesnake{{ID: "pathy", Name: "pathy"}}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const turns = 1000
+
+	// Act
+	for !engine.IsOver() && engine.Turn < turns {
+		state, _ := engine.StateFor("pathy")
+		engine.Step(map[string]string{"pathy": move(fromRules(t, state)).Move})
+	}
+
+	// Assert food keeps spawning until there is nowhere left to go, so the game ends once the snake fills the
+	// board, but it should get a long way towards that.
+	t.Logf("survived %d turns on a 7x7 board", engine.Turn)
+	if engine.Turn < 300 {
+		t.Errorf("expected to survive at least 300 turns on a 7x7 board, died on turn %d (%v)", engine.Turn, engine.Eliminated)
+	}
+}
+
+// Puts the snakes of a parsed board into squads, by snake ID, and makes the game a squad game.
+func inSquads(state GameState, squads map[string]string, allowBodyCollisions bool) GameState {
+	state.Game.Ruleset.Name = "squad"
+	state.Game.Ruleset.Settings.Squad.AllowBodyCollisions = allowBodyCollisions
+	for i := range state.Board.Snakes {
+		state.Board.Snakes[i].Squad = squads[state.Board.Snakes[i].ID]
+	}
+	state.You.Squad = squads[state.You.ID]
+	return state
+}
+
+func TestSquadGrid(t *testing.T) {
+	// Arrange
+	board := `
+		turn: 10
+
+		. . . . . .
+		. A a a . .
+		. b b B . .
+		. . . . . .
+		C c c . . .
+	`
+	squads := map[string]string{"A": "red", "B": "red", "C": "blue"}
+	passable := inSquads(parseBoard(t, board), squads, true)
+	solid := inSquads(parseBoard(t, board), squads, false)
+	passable.Game.ID, solid.Game.ID = "squad-grid-passable", "squad-grid-solid"
+	passableGrid := NewGrid(passable.Board.Width, passable.Board.Height, 0, 0)
+	solidGrid := NewGrid(solid.Board.Width, solid.Board.Height, 0, 0)
+
+	// Act
+	addSnakesToGrid(passable, passableGrid)
+	addSnakesToGrid(solid, solidGrid)
+
+	// Assert
+	if !passableGrid.Get(2, 2).Walkable || solidGrid.Get(2, 2).Walkable {
+		t.Errorf("expected a teammate's body to be walkable only when body collisions are allowed")
+	}
+	if passableGrid.Get(1, 0).Walkable {
+		t.Errorf("expected the other squad's body not to be walkable")
+	}
+	if passableGrid.Get(4, 2).Cost == config.LargerHeadCost || solidGrid.Get(4, 2).Cost == config.LargerHeadCost {
+		t.Errorf("expected no threat cost next to a teammate's head")
+	}
+	if passableGrid.Get(0, 1).Cost != config.LargerHeadCost {
+		t.Errorf("expected a threat cost next to the other squad's head, got %v", passableGrid.Get(0, 1).Cost)
+	}
+}
+
+func TestSquadFood(t *testing.T) {
+	// Arrange
+	board := `
+		A a a . .
+		. . . . .
+		* . . . .
+		. . . . .
+		B b b . .
+	`
+	squads := map[string]string{"A": "red", "B": "red"}
+	claim := func(state GameState) foodClaim {
+		grid := NewGrid(state.Board.Width, state.Board.Height, 0, 0)
+		addSnakesToGrid(state, grid)
+		return claimFood(state, Coord{X: 0, Y: 2}, moveDistances(state, grid, state.You.Head)[Coord{X: 0, Y: 2}], enemyDistances(state, grid))
+	}
+
+	// Act
+	standard := claim(parseBoard(t, board))
+	ours := claim(inSquads(parseBoard(t, board), squads, false))
+	theirs := claim(inSquads(parseBoard(t, "you: B\n"+board), squads, false))
+
+	// Assert
+	if standard != foodLost {
+		t.Errorf("expected to lose a tie for food against a snake as long as us, got %v", standard)
+	}
+	if ours != foodOurs || theirs != foodLost {
+		t.Errorf("expected exactly one teammate to take the food they tie for, got %v and %v", ours, theirs)
+	}
+}
+
+func TestOpponentModel(t *testing.T) {
+	// Arrange
+	options := map[string]moveFeatures{"up": {featureFood: true}, "left": {}, "right": {featureWall: true}}
+	model := &opponentModel{}
+
+	// Act
+	var unready map[string]float64
+	for i := 0; i < opponentModelMinMoves; i++ {
+		unready = model.predict(options)
+		model.record(options, "up")
+	}
+	ready := model.predict(options)
+
+	// Assert
+	for move, probability := range unready {
+		if math.Abs(probability-1.0/3) > 1e-9 {
+			t.Errorf("expected even odds before the model is ready, got %.2f for %s", probability, move)
+		}
+	}
+	if ready["up"] <= 0.5 || ready["left"] <= ready["right"] {
+		t.Errorf("expected a snake that always goes for food to be predicted to go for it again, got %v", ready)
+	}
+}
+
+func TestObserveOpponent(t *testing.T) {
+	// Arrange B goes straight up towards the food on every turn.
+	turn := func(n int) GameState {
+		b := Battlesnake{ID: "B", Name: "B", Health: 100, Body: []Coord{{3, n + 2}, {3, n + 1}, {3, n}}}
+		a := Battlesnake{ID: "A", Name: "A", Health: 100, Body: []Coord{{9, 2}, {9, 1}, {9, 0}, {9, 0}}}
+		b.Head, a.Head = b.Body[0], a.Body[0]
+		b.Length, a.Length = 3, 4
+		return GameState{
+			Game:  Game{ID: "observe-opponent", Ruleset: Ruleset{Name: "standard"}},
+			Turn:  n,
+			Board: Board{Width: 11, Height: 11, Food: []Coord{{3, 10}}, Snakes: []Battlesnake{a, b}},
+			You:   a,
+		}
+	}
+	defer endSession(turn(0))
+
+	// Act
+	for n := 0; n <= opponentModelMinMoves; n++ {
+		sessionFor(turn(n)).observe(turn(n))
+	}
+	state := turn(opponentModelMinMoves)
+	predictions := predictMoves(state, state.Board.Snakes[1])
+	grid := NewGrid(state.Board.Width, state.Board.Height, 0, 0)
+	addSnakesToGrid(state, grid)
+
+	// Assert
+	if model := opponentModelFor(state, "B"); !model.ready() || model.moves != opponentModelMinMoves {
+		t.Fatalf("expected to have seen B make %d moves, got %+v", opponentModelMinMoves, model)
+	}
+	if predictions["up"] <= predictions["left"] || predictions["up"] <= predictions["right"] {
+		t.Errorf("expected B to be predicted to carry on up, got %v", predictions)
+	}
+	above, beside := grid.Get(3, opponentModelMinMoves+3), grid.Get(2, opponentModelMinMoves+2)
+	if above.Cost <= beside.Cost {
+		t.Errorf("expected the cell B is likely to move onto to cost more, got %v above and %v beside", above.Cost, beside.Cost)
+	}
+}
+
+func TestOpeningBook(t *testing.T) {
+	// Arrange
+	_, embeddedErr := openingbook.Parse(openingsJSON)
+	game := rules.Game{ID: "opening-book", Ruleset: rules.Ruleset{Name: rules.RulesetStandard, Settings: rules.DefaultSettings()}, Map: rules.MapStandard}
+	engine, err := rules.NewEngine(game, 11, 11, []rules.Battlesnake{{ID: "snake-1"}, {ID: "snake-2"}}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inBook, _ := engine.StateFor("snake-1")
+	cornered := inBook
+	cornered.Board.Snakes = []rules.Battlesnake{{ID: "snake-1", Health: 100, Body: []rules.Coord{{X: 0, Y: 0}, {X: 0, Y: 0}, {X: 0, Y: 0}}}}
+	cornered.You = cornered.Board.Snakes[0]
+	book := openingbook.New()
+	book.Add(inBook, "down")
+	book.Add(cornered, "left")
+
+	// Act
+	bookedMove, booked := bookMove(book, fromRules(t, inBook))
+	_, unsafe := bookMove(book, fromRules(t, cornered))
+	inBook.Turn = 1
+	_, outOfBook := bookMove(book, fromRules(t, inBook))
+
+	// Assert
+	if embeddedErr != nil {
+		t.Errorf("expected the embedded opening book to load, got %s", embeddedErr)
+	}
+	if !booked || bookedMove != "down" {
+		t.Errorf("expected the book's move down, got %q (%v)", bookedMove, booked)
+	}
+	if unsafe {
+		t.Errorf("expected a book move off the board to be left to the rest of the pipeline")
+	}
+	if outOfBook {
+		t.Errorf("expected no book move for a position the book doesn't have")
+	}
+}
+
+func TestEnsembleVeto(t *testing.T) {
+	// Arrange: above our head is a pocket in B's body, and to our right is our own tail.
+	state := parseBoard(t, `
+		turn: 10
+
+		. . . . . . .
+		. . . . . . .
+		. b4 b3 b2 . . .
+		. b5 . b1 B . .
+		. . A a3 . . .
+		. . a1 a2 . . .
+		. . . . . . .
+	`)
+	state.Game.ID = "ensemble-veto"
+	grid := NewGrid(state.Board.Width, state.Board.Height, 0, 0)
+	addSnakesToGrid(state, grid)
+	plans := []struct {
+		planned, expected, trace string
+	}{
+		{"up", "left", "spring-league vetoed up (surrounded), took left"},
+		{"right", "right", "spring-league vetoed right (own body), kept right, the tail there moves on"},
+		{"left", "left", ""},
+	}
+
+	for _, plan := range plans {
+		// Act
+		nextMove, trace := vetoByRules(state, grid, plan.planned)
+
+		// Assert
+		if nextMove != plan.expected || strings.Join(trace, ", ") != plan.trace {
+			t.Errorf("planned %s: expected %s (%q), got %s (%q)", plan.planned, plan.expected, plan.trace, nextMove, strings.Join(trace, ", "))
+		}
+	}
+	endSession(state)
+}
+
+func TestEnsembleAgrees(t *testing.T) {
+	// Arrange
+	state := parseBoard(t, `
+		health: A=30
+
+		. . . . . . .
+		. . . . . . .
+		. . . . * . .
+		. . . . . . .
+		. . A . . . .
+		. . a . . . B
+		. . a . . . b
+	`)
+	state.Game.ID = "ensemble-agrees"
+	planned := createSnakeMap(state, newGameRand(state)).Move
+
+	// Act
+	nextMove, trace := ensembleMove(state, newGameRand(state))
+	endSession(state)
+
+	// Assert
+	if nextMove != planned || len(trace) != 0 {
+		t.Errorf("expected the path's move %s with nothing to trace, got %s and %q", planned, nextMove, trace)
+	}
+}
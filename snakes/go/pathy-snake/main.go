@@ -162,6 +162,13 @@ func main() {
 		}
 	}()
 
+	// Load tuned weights if PATHY_CONFIG points at a config file.
+	loaded, err := configFromEnv()
+	if err != nil {
+		log.Fatalf("ERROR: Failed to load config, %s", err)
+	}
+	config = loaded
+
 	port := os.Getenv("PORT")
 	if len(port) == 0 {
 		port = "8081"
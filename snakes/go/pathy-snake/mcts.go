@@ -0,0 +1,263 @@
+package main
+
+import (
+	"expvar"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// Monte Carlo tree search: with three or more snakes minimax is too slow, so instead we play lots of quick games
+// forward from the current board and keep the move that the search came back to most often. Snakes move at the
+// same time, so the tree is decoupled UCT: at every node each snake picks its own move from its own statistics,
+// and the moves picked together lead to the child node. Games are played out past the tree with the rollout
+// policy below until the game ends or rolloutTurns have passed.
+
+// Names of the strategies the config can select.
+const (
+	// Follow the cheapest path to food or a random cell, see createSnakeMap.
+	strategyPath = "path"
+	// Monte Carlo tree search once there are three or more snakes, see mctsMove.
+	strategyMCTS = "mcts"
+)
+
+// How many turns a playout runs past the tree before it is scored.
+const rolloutTurns = 20
+
+// Exploration constant of UCB1. Rewards are between 0 and 1, so this is the usual square root of 2.
+const explorationWeight = math.Sqrt2
+
+// Metrics, served as JSON on /debug/vars.
+var (
+	mctsPlayouts          = expvar.NewInt("mcts_playouts")
+	mctsPlayoutsPerSecond = expvar.NewFloat("mcts_playouts_per_second")
+)
+
+// Function that picks a move with Monte Carlo tree search, or returns false if the config doesn't select it,
+// there are fewer than three snakes, the ruleset is one the engine can't play, or there wasn't time for a single
+// playout. It also returns how many playouts it ran.
+func mctsMove(state GameState) (string, int, bool) {
+	if config.Strategy != strategyMCTS || len(state.Board.Snakes) < 3 {
+		return "", 0, false
+	}
+	engine, ok := searchEngine(state)
+	if !ok {
+		return "", 0, false
+	}
+	started := time.Now()
+	search := newMCTS(engine, state.You.ID, newGameRand(state))
+	search.models = sessionFor(state).opponentModels()
+	playouts := search.run(searchDeadline(state), config.MCTSPlayouts)
+	move, ok := search.best()
+
+	mctsPlayouts.Add(int64(playouts))
+	if elapsed := time.Since(started).Seconds(); elapsed > 0 {
+		mctsPlayoutsPerSecond.Set(float64(playouts) / elapsed)
+	}
+	return move, playouts, ok && playouts > 0
+}
+
+type mcts struct {
+	me   string
+	root *mctsNode
+	rand *rand.Rand
+	// The models of the other snakes, which rollouts play them by once they are ready.
+	models map[string]*opponentModel
+}
+
+// A node is a board part way through the tree. Each snake still alive has its own statistics per move.
+type mctsNode struct {
+	engine   *rules.Engine
+	visits   int
+	moves    map[string][]string
+	stats    map[string]map[string]*mctsStat
+	children map[string]*mctsNode
+}
+
+type mctsStat struct {
+	visits int
+	reward float64
+}
+
+func newMCTS(engine *rules.Engine, me string, r *rand.Rand) *mcts {
+	return &mcts{me: me, root: newMCTSNode(engine), rand: r}
+}
+
+func newMCTSNode(engine *rules.Engine) *mctsNode {
+	node := &mctsNode{
+		engine:   engine,
+		moves:    make(map[string][]string),
+		stats:    make(map[string]map[string]*mctsStat),
+		children: make(map[string]*mctsNode),
+	}
+	for _, snake := range engine.Board.Snakes {
+		node.moves[snake.ID] = searchMoves(engine, snake.ID)
+		node.stats[snake.ID] = make(map[string]*mctsStat)
+		for _, move := range node.moves[snake.ID] {
+			node.stats[snake.ID][move] = &mctsStat{}
+		}
+	}
+	return node
+}
+
+// Function that runs playouts until the deadline, or until limit playouts if limit is more than 0, and returns
+// how many it ran.
+func (m *mcts) run(deadline time.Time, limit int) int {
+	playouts := 0
+	for (limit <= 0 || playouts < limit) && time.Now().Before(deadline) {
+		m.playout()
+		playouts++
+	}
+	return playouts
+}
+
+// Function that returns our move the search visited most.
+func (m *mcts) best() (string, bool) {
+	best, visits := "", -1
+	for _, move := range m.root.moves[m.me] {
+		if stat := m.root.stats[m.me][move]; stat.visits > visits {
+			best, visits = move, stat.visits
+		}
+	}
+	return best, best != ""
+}
+
+// Function that walks down the tree picking moves with UCB1, adds one node, plays the game out from there and
+// passes every snake's reward back up the path.
+func (m *mcts) playout() {
+	type step struct {
+		node  *mctsNode
+		moves map[string]string
+	}
+	var path []step
+	node := m.root
+	for !m.over(node.engine) {
+		moves := make(map[string]string, len(node.moves))
+		for _, snake := range node.engine.Board.Snakes {
+			moves[snake.ID] = node.choose(snake.ID)
+		}
+		path = append(path, step{node, moves})
+		key := jointMoveKey(node.engine, moves)
+		child, ok := node.children[key]
+		if !ok {
+			next := node.engine.Clone()
+			next.Step(moves)
+			child = newMCTSNode(next)
+			node.children[key] = child
+			node = child
+			break
+		}
+		node = child
+	}
+
+	rewards := m.rollout(node.engine)
+	node.visits++
+	for _, s := range path {
+		s.node.visits++
+		for id, move := range s.moves {
+			stat := s.node.stats[id][move]
+			stat.visits++
+			stat.reward += rewards[id]
+		}
+	}
+}
+
+// Function that picks a snake's move at this node: any move not tried yet, then the one with the best upper
+// confidence bound.
+func (n *mctsNode) choose(id string) string {
+	best, bestBound := "", math.Inf(-1)
+	for _, move := range n.moves[id] {
+		stat := n.stats[id][move]
+		if stat.visits == 0 {
+			return move
+		}
+		bound := stat.reward/float64(stat.visits) + explorationWeight*math.Sqrt(math.Log(float64(n.visits))/float64(stat.visits))
+		if bound > bestBound {
+			best, bestBound = move, bound
+		}
+	}
+	return best
+}
+
+// Function that reports whether a playout can stop: we are out, or the game is over, which in squad games is once
+// only one squad is left.
+func (m *mcts) over(engine *rules.Engine) bool {
+	if engine.IsOver() {
+		return true
+	}
+	for _, snake := range engine.Board.Snakes {
+		if snake.ID == m.me {
+			return false
+		}
+	}
+	return true
+}
+
+// Function that plays the game on from a copy of the engine with the rollout policy and scores it for every
+// snake: nothing for a snake that died, and an equal share of 1 for the snakes still alive at the end.
+func (m *mcts) rollout(engine *rules.Engine) map[string]float64 {
+	if !m.over(engine) {
+		engine = engine.Clone()
+		for turn := 0; turn < rolloutTurns && !m.over(engine); turn++ {
+			moves := make(map[string]string, len(engine.Board.Snakes))
+			for _, snake := range engine.Board.Snakes {
+				moves[snake.ID] = rolloutMove(engine, snake, m.models[snake.ID], m.rand)
+			}
+			engine.Step(moves)
+		}
+	}
+	rewards := make(map[string]float64)
+	for _, snake := range engine.Board.Snakes {
+		rewards[snake.ID] = 1 / float64(len(engine.Board.Snakes))
+	}
+	return rewards
+}
+
+// Function that picks a random move for a playout. It follows the same safety rules as spring-league-2022: stay
+// on the board and out of bodies, treating tails that are about to move as free. If nothing is safe any move
+// will do. Snakes whose model is ready pick among the safe moves as it predicts, and the rest at random.
+func rolloutMove(engine *rules.Engine, snake rules.Battlesnake, model *opponentModel, r *rand.Rand) string {
+	var safe []string
+	for _, move := range searchMoves(engine, snake.ID) {
+		next, _ := searchStep(engine, snake.Head, move)
+		if !solidCell(engine, next) {
+			safe = append(safe, move)
+		}
+	}
+	if len(safe) == 0 {
+		return rules.MoveUp
+	}
+	if model.ready() {
+		return modelledMove(engine, snake, safe, model, r)
+	}
+	return safe[r.Intn(len(safe))]
+}
+
+// Function that reports whether a cell is part of a snake that will still be there next turn.
+func solidCell(engine *rules.Engine, c rules.Coord) bool {
+	for _, snake := range engine.Board.Snakes {
+		body := snake.Body
+		if n := len(body); n > 1 && body[n-1] != body[n-2] {
+			body = body[:n-1]
+		}
+		for _, b := range body {
+			if b == c {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Function that turns the moves every snake picked into a key for the child node they lead to.
+func jointMoveKey(engine *rules.Engine, moves map[string]string) string {
+	var key strings.Builder
+	for _, snake := range engine.Board.Snakes {
+		key.WriteString(moves[snake.ID])
+		key.WriteByte('/')
+	}
+	return key.String()
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// Opponent modelling: each game's session keeps a model of every other snake, built from the moves we have seen it
+// make. For every move we note which of a few things about it the snake had a choice over, and what it chose:
+// going towards food, going towards another snake's head, going along a wall and going straight on. A snake that
+// goes for food whenever it can is then expected to go for food again. Until we have seen a snake make a few moves
+// the model has no opinion, and every safe move is as likely as any other.
+
+// The things about a move the model looks at.
+const (
+	// Closer to the nearest food.
+	featureFood = iota
+	// Closer to the nearest other snake's head.
+	featureAttack
+	// Onto the edge of a board that doesn't wrap.
+	featureWall
+	// The same way as the last move.
+	featureStraight
+	featureCount
+)
+
+// How many of a snake's moves we have to have seen before we use its model.
+const opponentModelMinMoves = 5
+
+type moveFeatures [featureCount]bool
+
+type opponentModel struct {
+	// How many of the snake's moves we have seen.
+	moves int
+	// For each feature, how many of those moves had a choice over it, and how many of those went with it.
+	chances [featureCount]int
+	chosen  [featureCount]int
+}
+
+// Function that reports whether we have seen enough of the snake to use its model. A snake we have no model of
+// isn't ready.
+func (m *opponentModel) ready() bool {
+	return m != nil && m.moves >= opponentModelMinMoves
+}
+
+// Function that learns from a move the snake made, given the features of every safe move it had.
+func (m *opponentModel) record(options map[string]moveFeatures, made string) {
+	m.moves++
+	for f := 0; f < featureCount; f++ {
+		with, without := false, false
+		for _, features := range options {
+			if features[f] {
+				with = true
+			} else {
+				without = true
+			}
+		}
+		if with && without {
+			m.chances[f]++
+			if options[made][f] {
+				m.chosen[f]++
+			}
+		}
+	}
+}
+
+// Function that returns how likely the snake is to make each of its safe moves. Each feature of a move counts for
+// how often the snake went with that feature when it had the choice, starting from even odds.
+func (m *opponentModel) predict(options map[string]moveFeatures) map[string]float64 {
+	probabilities := make(map[string]float64, len(options))
+	total := 0.0
+	for move, features := range options {
+		weight := 1.0
+		if m.ready() {
+			for f := 0; f < featureCount; f++ {
+				p := float64(m.chosen[f]+1) / float64(m.chances[f]+2)
+				if !features[f] {
+					p = 1 - p
+				}
+				weight *= p
+			}
+		}
+		probabilities[move] = weight
+		total += weight
+	}
+	for move := range probabilities {
+		probabilities[move] /= total
+	}
+	return probabilities
+}
+
+// Function that returns the model of a snake in this game, or nil if we haven't seen it move yet.
+func opponentModelFor(state GameState, id string) *opponentModel {
+	return sessionFor(state).opponentModel(id)
+}
+
+// Function that learns from the move every other snake made since the turn before this one, if we saw it, and
+// keeps this turn to learn from next time. Seeing the same turn twice teaches nothing.
+func (s *session) observe(state GameState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.previous != nil && s.previous.Turn == state.Turn-1 {
+		for _, snake := range state.Board.Snakes {
+			before, ok := findSnake(*s.previous, snake.ID)
+			if snake.ID == state.You.ID || !ok || len(snake.Body) == 0 || len(before.Body) == 0 {
+				continue
+			}
+			options := moveOptions(*s.previous, before)
+			for move := range options {
+				if next, _ := stepCoord(*s.previous, before.Head, move); next == snake.Head {
+					if s.opponents[snake.ID] == nil {
+						s.opponents[snake.ID] = &opponentModel{}
+					}
+					s.opponents[snake.ID].record(options, move)
+				}
+			}
+		}
+	}
+	if s.previous == nil || s.previous.Turn != state.Turn {
+		s.previous = &state
+	}
+}
+
+// Function that returns the snake with the given ID on the board.
+func findSnake(state GameState, id string) (Battlesnake, bool) {
+	for _, snake := range state.Board.Snakes {
+		if snake.ID == id {
+			return snake, true
+		}
+	}
+	return Battlesnake{}, false
+}
+
+// Function that returns the features of each move a snake could make without running into a wall, a body or its
+// own neck.
+func moveOptions(state GameState, snake Battlesnake) map[string]moveFeatures {
+	var heads []Coord
+	for _, other := range state.Board.Snakes {
+		if other.ID != snake.ID && len(other.Body) > 0 {
+			heads = append(heads, other.Head)
+		}
+	}
+	neck := snake.Head
+	if len(snake.Body) > 1 {
+		neck = snake.Body[1]
+	}
+	options := make(map[string]moveFeatures)
+	for _, move := range []string{"up", "down", "left", "right"} {
+		next, ok := stepCoord(state, snake.Head, move)
+		if ok && !solidNextTurn(state, next) && next != neck {
+			options[move] = featuresOf(state.Board.Width, state.Board.Height, state.isWrapped(), snake.Head, neck, next, state.Board.Food, heads)
+		}
+	}
+	return options
+}
+
+// Function that returns the features of a move from head to next. neck is the same as head for a snake that
+// hasn't moved yet.
+func featuresOf(width, height int, wrapped bool, head, neck, next Coord, food, heads []Coord) moveFeatures {
+	var features moveFeatures
+	features[featureFood] = nearest(width, height, wrapped, next, food) < nearest(width, height, wrapped, head, food)
+	features[featureAttack] = nearest(width, height, wrapped, next, heads) < nearest(width, height, wrapped, head, heads)
+	features[featureWall] = !wrapped && (next.X == 0 || next.Y == 0 || next.X == width-1 || next.Y == height-1)
+	straight := Coord{X: 2*head.X - neck.X, Y: 2*head.Y - neck.Y}
+	if wrapped {
+		straight = Coord{X: (straight.X + width) % width, Y: (straight.Y + height) % height}
+	}
+	features[featureStraight] = neck != head && next == straight
+	return features
+}
+
+// Function that returns the number of moves from c to the closest of the targets, ignoring what is in the way, or
+// math.MaxInt32 if there are none.
+func nearest(width, height int, wrapped bool, c Coord, targets []Coord) int {
+	closest := math.MaxInt32
+	for _, t := range targets {
+		dx, dy := abs(c.X-t.X), abs(c.Y-t.Y)
+		if wrapped {
+			if width-dx < dx {
+				dx = width - dx
+			}
+			if height-dy < dy {
+				dy = height - dy
+			}
+		}
+		if dx+dy < closest {
+			closest = dx + dy
+		}
+	}
+	return closest
+}
+
+// Function that picks a move for a snake in a playout as its model predicts, from the moves the rollout policy
+// found safe.
+func modelledMove(engine *rules.Engine, snake rules.Battlesnake, safe []string, model *opponentModel, r *rand.Rand) string {
+	food := make([]Coord, len(engine.Board.Food))
+	for i, f := range engine.Board.Food {
+		food[i] = Coord(f)
+	}
+	var heads []Coord
+	for _, other := range engine.Board.Snakes {
+		if other.ID != snake.ID {
+			heads = append(heads, Coord(other.Head))
+		}
+	}
+	neck := snake.Head
+	if len(snake.Body) > 1 {
+		neck = snake.Body[1]
+	}
+	wrapped := engine.Game.Ruleset.Name == rules.RulesetWrapped
+	options := make(map[string]moveFeatures, len(safe))
+	for _, move := range safe {
+		next, _ := searchStep(engine, snake.Head, move)
+		options[move] = featuresOf(engine.Board.Width, engine.Board.Height, wrapped, Coord(snake.Head), Coord(neck), Coord(next), food, heads)
+	}
+	probabilities := model.predict(options)
+	x := r.Float64()
+	for _, move := range safe {
+		if x -= probabilities[move]; x < 0 {
+			return move
+		}
+	}
+	return safe[len(safe)-1]
+}
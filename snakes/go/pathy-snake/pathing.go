@@ -1,10 +1,18 @@
 package main
 
-// TODO: #121 Make it so we don't pick a destination cell that is in a hazard.
-// TODO: #122 Add some aggressive snake logic. i.e. adjust the cost of cells next to snake heads differently if they are smaller than us.
+import (
+	"math/rand"
+)
 
 // function that creates a new grid from that contains all the snakes body parts as not walkable.
-func createSnakeMap(state GameState) BattlesnakeMoveResponse {
+// rng is the random source for this turn, see newGameRand.
+func createSnakeMap(state GameState, rng *rand.Rand) BattlesnakeMoveResponse {
+	nextMove, _ := planMove(state, rng)
+	return nextMove
+}
+
+// Function that returns the move along the path to our target, and the grid it was planned on.
+func planMove(state GameState, rng *rand.Rand) (BattlesnakeMoveResponse, *Grid) {
 	// Create a new grid with the size of the game board.
 	//log.Printf("Creating Snake Map")
 	grid := NewGrid(state.Board.Width, state.Board.Height, 0, 0)
@@ -17,14 +25,23 @@ func createSnakeMap(state GameState) BattlesnakeMoveResponse {
 	// Print the grid to the console. Useful for debugging.
 	// printGrid(state, grid)
 	// Get the path from the head to a destination cell.
-	path := getPath(state, grid)
-	// Return the next move (left, right, up, down) based on the path previously calculated.
-	return getNextDirection(state, path)
+	path := getPath(state, grid, rng)
+	// If there is no path to follow, take any walkable cell next to our head instead.
+	if path == nil || path.Length() < 2 {
+		return vetoHeadToHead(state, grid, escapeMove(state, grid)), grid
+	}
+	// Return the next move (left, right, up, down) based on the path previously calculated, unless it risks a
+	// head-to-head we don't win and there is a safer way.
+	return vetoHeadToHead(state, grid, getNextDirection(state, path)), grid
 }
 
 func addSnakesToGrid(state GameState, grid *Grid) {
 	// Iterate over all the snakes in the game state.
 	for _, snake := range state.Board.Snakes {
+		// Teammates we can move through don't get in our way.
+		if snake.isPassable(state) {
+			continue
+		}
 		// Iterate over all the body parts of the snake.
 		for _, bodyPart := range snake.Body {
 			// Set the body part to not be walkable.
@@ -34,10 +51,10 @@ func addSnakesToGrid(state GameState, grid *Grid) {
 
 	// Clear any snake health map that might exist between games.
 	clearSnakeHealths(state)
-	// If a snake did not eat food on the previous turn, we can make their tail walkable.
+	// If a snake's tail will move out of the way, we can make their tail walkable.
 	if state.Turn > 3 {
 		for _, otherSnake := range state.Board.Snakes {
-			if !didSnakeEatFood(otherSnake, state) {
+			if tailWillMove(otherSnake, state) {
 				// Make sure their tail is walkable.
 				grid.Get(otherSnake.Body[len(otherSnake.Body)-1].X, otherSnake.Body[len(otherSnake.Body)-1].Y).Walkable = true
 			}
@@ -46,25 +63,39 @@ func addSnakesToGrid(state GameState, grid *Grid) {
 		updateSnakeHealth(state)
 	}
 
-	// Iterrate over all the the other snakes heads.
+	// Iterrate over all the the other snakes heads, leaving out our squad, who aren't a threat.
 	for _, otherSnake := range state.Board.Snakes {
-		if otherSnake.ID != state.You.ID {
+		if otherSnake.isEnemySnake(state) {
+			// Once we know how the snake plays, cost the cells next to its head by how likely it is to move onto
+			// them. An even chance of each of its moves costs the same as the fixed costs below.
+			if opponentModelFor(state, otherSnake.ID).ready() {
+				cost := config.SmallerHeadCost
+				if otherSnake.isLargerThanUs(state) {
+					cost = config.LargerHeadCost
+				}
+				predictions := predictMoves(state, otherSnake)
+				for move, probability := range predictions {
+					c, _ := stepCoord(state, otherSnake.Head, move)
+					grid.Get(c.X, c.Y).Cost = cost * probability * float64(len(predictions))
+				}
+				continue
+			}
 			left := otherSnake.Head.cellLeft(state)
 			right := otherSnake.Head.cellRight(state)
 			above := otherSnake.Head.cellAbove(state)
 			below := otherSnake.Head.cellBelow(state)
 			if otherSnake.isLargerThanUs(state) {
-				grid.Get(left.X, left.Y).Cost = 5
-				grid.Get(right.X, right.Y).Cost = 5
-				grid.Get(above.X, above.Y).Cost = 5
-				grid.Get(below.X, below.Y).Cost = 5
+				grid.Get(left.X, left.Y).Cost = config.LargerHeadCost
+				grid.Get(right.X, right.Y).Cost = config.LargerHeadCost
+				grid.Get(above.X, above.Y).Cost = config.LargerHeadCost
+				grid.Get(below.X, below.Y).Cost = config.LargerHeadCost
 				continue
 			}
 			// If the other snake is smaller than us, we want to make the cells next to their head walkable.
-			grid.Get(left.X, left.Y).Cost = 1
-			grid.Get(right.X, right.Y).Cost = 1
-			grid.Get(above.X, above.Y).Cost = 1
-			grid.Get(below.X, below.Y).Cost = 1
+			grid.Get(left.X, left.Y).Cost = config.SmallerHeadCost
+			grid.Get(right.X, right.Y).Cost = config.SmallerHeadCost
+			grid.Get(above.X, above.Y).Cost = config.SmallerHeadCost
+			grid.Get(below.X, below.Y).Cost = config.SmallerHeadCost
 		}
 	}
 	// Make sure our own head is walkable. We need to do this because the getPath function
@@ -78,7 +109,7 @@ func addFoodToGrid(state GameState, grid *Grid) {
 	// Iterate over all the food in the game state.
 	for _, food := range state.Board.Food {
 		// Set the food cell to a lower cost.
-		grid.Get(food.X, food.Y).Cost = .5
+		grid.Get(food.X, food.Y).Cost = config.FoodCost
 	}
 }
 
@@ -91,13 +122,17 @@ func changeHazardsCost(state GameState, grid *Grid) {
 			grid.Get(hazard.X, hazard.Y).Walkable = false
 			continue
 		}
-		grid.Get(hazard.X, hazard.Y).Cost = 5
+		grid.Get(hazard.X, hazard.Y).Cost = config.HazardCost
 	}
 }
 
-func getPath(state GameState, grid *Grid) *Path {
-	targetCell := getTargetCell(state, grid)
+func getPath(state GameState, grid *Grid, rng *rand.Rand) *Path {
+	targetCell := getTargetCell(state, grid, rng)
 
+	// Take the cheapest way that keeps us alive, if there is one.
+	if path, _ := healthyPath(state, grid, targetCell); path != nil {
+		return path
+	}
 	return grid.GetPathFromCells(grid.Get(state.You.Head.X, state.You.Head.Y), grid.Get(targetCell.X, targetCell.Y), false, false, state.isWrapped())
 
 	// Print the path and related points to the console. Useful for debugging.
@@ -118,10 +153,19 @@ func getPath(state GameState, grid *Grid) *Path {
 	*/
 }
 
-func getTargetCell(state GameState, grid *Grid) *Cell {
-	var targetCell *Cell
-	// If our health is less than 85 we want to set our target cell to be the coordinates of the closest food.
-	if state.You.Health < 85 && len(state.Board.Food) > 0 {
+func getTargetCell(state GameState, grid *Grid, rng *rand.Rand) *Cell {
+	// If a smaller snake is pinned and we can cut it off or beat it to a cell, go for the kill.
+	targetCell := huntTarget(state, grid)
+	if targetCell != nil {
+		return targetCell
+	}
+	// If there is barely room for us, stall by following our tail until space opens up.
+	targetCell = survivalModeTarget(state, grid)
+	if targetCell != nil {
+		return targetCell
+	}
+	// If our health is less than config.HungryHealth (85 by default) we want to set our target cell to be the coordinates of the closest food.
+	if state.You.Health < config.HungryHealth && len(state.Board.Food) > 0 {
 		targetCell = chooseNearestFood(grid, state)
 	}
 
@@ -132,17 +176,66 @@ func getTargetCell(state GameState, grid *Grid) *Cell {
 
 	// If we still don't have a target cell, then just pick a random walkable cell.
 	if targetCell == nil {
-		targetCell = chooseRandomWalkableTargetCell(grid, state)
+		targetCell = chooseRandomWalkableTargetCell(grid, state, rng)
+	}
+
+	// If we still don't have a target cell, then keep our tail in reach.
+	if targetCell == nil {
+		targetCell = survivalTarget(state, grid)
 	}
 
 	// If we still don't have a target cell, then just pick a random cell.
 	if targetCell == nil {
-		targetCell = chooseRandomTargetCell(grid)
+		targetCell = chooseRandomTargetCell(grid, rng)
 	}
 
 	return targetCell
 }
 
+// Function that returns a move onto the cheapest walkable cell next to our head, for when there is no path to
+// the target. Walls only count as walkable on wrapped boards. If nothing is walkable we try a tail, then go up and hope.
+func escapeMove(state GameState, grid *Grid) BattlesnakeMoveResponse {
+	head := state.You.Head
+	neighbours := []struct {
+		move  string
+		coord Coord
+	}{
+		{"up", Coord{X: head.X, Y: head.Y + 1}},
+		{"down", Coord{X: head.X, Y: head.Y - 1}},
+		{"left", Coord{X: head.X - 1, Y: head.Y}},
+		{"right", Coord{X: head.X + 1, Y: head.Y}},
+	}
+	best := BattlesnakeMoveResponse{Move: "up"}
+	var bestCell *Cell
+	for _, n := range neighbours {
+		if state.isWrapped() {
+			n.coord = Coord{X: (n.coord.X + state.Board.Width) % state.Board.Width, Y: (n.coord.Y + state.Board.Height) % state.Board.Height}
+		}
+		cell := grid.Get(n.coord.X, n.coord.Y)
+		if cell == nil || !cell.Walkable {
+			continue
+		}
+		if bestCell == nil || cell.Cost < bestCell.Cost {
+			best, bestCell = BattlesnakeMoveResponse{Move: n.move}, cell
+		}
+	}
+	if bestCell != nil {
+		return best
+	}
+	// Nothing is walkable, but a tail that hasn't just eaten will have moved by the time we get there.
+	for _, n := range neighbours {
+		if state.isWrapped() {
+			n.coord = Coord{X: (n.coord.X + state.Board.Width) % state.Board.Width, Y: (n.coord.Y + state.Board.Height) % state.Board.Height}
+		}
+		for _, snake := range state.Board.Snakes {
+			if n.coord == snake.Body[len(snake.Body)-1] && tailWillMove(snake, state) {
+				return BattlesnakeMoveResponse{Move: n.move}
+			}
+		}
+	}
+	return best
+}
+
 // Function that returns the next direction to move based on the path we get from getPath.
 func getNextDirection(state GameState, path *Path) BattlesnakeMoveResponse {
 	if path.Current().Y == path.Next().Y && (path.Current().X < path.Next().X || path.Current().X > path.Next().X) {
@@ -8,6 +8,7 @@ This a copy of https://github.com/SolarLune/paths/blob/master/paths.go with some
 package main
 
 import (
+	"container/heap"
 	"fmt"
 	"math"
 	"sort"
@@ -627,3 +628,99 @@ func (p *Path) AtEnd() bool {
 func (m *Grid) HasNext(headX, headY, x, y int, wrapped bool) bool {
 	return m.GetPathFromCells(m.Get(headX, headY), m.Get(x, y), false, false, wrapped) != nil
 }
+
+// GetHealthyPathFromCells returns the cheapest Path from start to dest along which health never drops to zero, and the health
+// left on arriving at dest. step is called with each Cell entered and the health before entering it, and returns the health
+// after. Paths are compared by the total Cost of their Cells, the same as GetPathFromCells. If every Path runs out of health,
+// or there is no Path at all, it returns nil and 0.
+// Added for use with Battlesnake, where hazards cost health as well as turns.
+func (m *Grid) GetHealthyPathFromCells(start, dest *Cell, health int, step func(cell *Cell, health int) int, wrapping bool) (*Path, int) {
+
+	if !start.Walkable || !dest.Walkable || health <= 0 {
+		return nil, 0
+	}
+
+	// A label is one way of reaching a Cell. Labels are taken cheapest first, so once a Cell has been reached with some
+	// health, a later label only matters if it arrives with more. Eating puts health back up, so a label can arrive at a
+	// Cell it has already been through with more health than before; it never steps onto its own Path again.
+	open := &healthLabels{{cell: start, health: health}}
+	bestHealth := make(map[*Cell]int)
+
+	for open.Len() > 0 {
+
+		label := heap.Pop(open).(*healthLabel)
+		if best, ok := bestHealth[label.cell]; ok && label.health <= best {
+			continue
+		}
+		bestHealth[label.cell] = label.health
+
+		if label.cell == dest {
+			path := &Path{}
+			for l := label; l != nil; l = l.parent {
+				path.Cells = append([]*Cell{l.cell}, path.Cells...)
+			}
+			return path, label.health
+		}
+
+		for _, c := range m.neighbours(label.cell, wrapping) {
+			if !c.Walkable || label.visited(c) {
+				continue
+			}
+			left := step(c, label.health)
+			if best, ok := bestHealth[c]; left <= 0 || (ok && left <= best) {
+				continue
+			}
+			heap.Push(open, &healthLabel{cell: c, parent: label, cost: label.cost + c.Cost, health: left})
+		}
+
+	}
+
+	return nil, 0
+
+}
+
+// neighbours returns the Cells next to cell, across the edges of the Grid if wrapping.
+func (m *Grid) neighbours(cell *Cell, wrapping bool) []*Cell {
+	var cells []*Cell
+	for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+		x, y := cell.X+d[0], cell.Y+d[1]
+		if wrapping {
+			x, y = (x+m.Width())%m.Width(), (y+m.Height())%m.Height()
+		}
+		if c := m.Get(x, y); c != nil {
+			cells = append(cells, c)
+		}
+	}
+	return cells
+}
+
+type healthLabel struct {
+	cell   *Cell
+	parent *healthLabel
+	cost   float64
+	health int
+}
+
+// visited reports whether the Path to the label already goes through cell.
+func (l *healthLabel) visited(cell *Cell) bool {
+	for ; l != nil; l = l.parent {
+		if l.cell == cell {
+			return true
+		}
+	}
+	return false
+}
+
+// healthLabels is a priority queue of labels, cheapest first, for container/heap.
+type healthLabels []*healthLabel
+
+func (h healthLabels) Len() int            { return len(h) }
+func (h healthLabels) Less(i, j int) bool  { return h[i].cost < h[j].cost }
+func (h healthLabels) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *healthLabels) Push(x interface{}) { *h = append(*h, x.(*healthLabel)) }
+func (h *healthLabels) Pop() interface{} {
+	old := *h
+	label := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return label
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"time"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// Helpers shared by the strategies that look ahead by playing the game forward with the shared rules engine,
+// see duel.go and mcts.go.
+
+// Share of the game's move timeout a search may use at most, leaving the rest for the network.
+const searchTimeShare = 0.4
+
+// Timeout to assume if the game doesn't give one.
+const defaultTimeout = 500 * time.Millisecond
+
+// Function that loads the state into an engine to search with, or returns false if the ruleset is one the engine
+// can't play. The engine never spawns food: where it appears is luck, and a search would only guess at it.
+func searchEngine(state GameState) (*rules.Engine, bool) {
+	game := rules.Game{ID: state.Game.ID, Ruleset: toRulesRuleset(state.Game.Ruleset), Map: rules.MapEmpty}
+	engine, err := rules.ResumeEngine(game, state.Turn, toRulesBoard(state.Board), 0)
+	return engine, err == nil
+}
+
+// Function that returns when a search started now has to stop. The searches stop on their budgets in the config,
+// so their results don't depend on the machine; this is only a cap in case the budget takes too long.
+func searchDeadline(state GameState) time.Time {
+	timeout := time.Duration(state.Game.Timeout) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return time.Now().Add(time.Duration(float64(timeout) * searchTimeShare))
+}
+
+// Function that lists the moves worth searching for a snake: everything except turning back onto its own neck
+// or stepping off a board that doesn't wrap. If that leaves nothing, the snake is dead whatever it does and up
+// stands in for all of them.
+func searchMoves(engine *rules.Engine, id string) []string {
+	var snake rules.Battlesnake
+	for _, s := range engine.Board.Snakes {
+		if s.ID == id {
+			snake = s
+		}
+	}
+	var moves []string
+	for _, move := range rules.Moves {
+		next, ok := searchStep(engine, snake.Head, move)
+		if !ok || (len(snake.Body) > 1 && snake.Body[1] != snake.Head && next == snake.Body[1]) {
+			continue
+		}
+		moves = append(moves, move)
+	}
+	if len(moves) == 0 {
+		moves = append(moves, rules.MoveUp)
+	}
+	return moves
+}
+
+// Function that returns the cell a move leads to, wrapping on wrapped boards, or false if it is off the board.
+func searchStep(engine *rules.Engine, c rules.Coord, move string) (rules.Coord, bool) {
+	next := c.Neighbour(move)
+	width, height := engine.Board.Width, engine.Board.Height
+	if engine.Game.Ruleset.Name == rules.RulesetWrapped {
+		return rules.Coord{X: (next.X + width) % width, Y: (next.Y + height) % height}, true
+	}
+	return next, next.X >= 0 && next.X < width && next.Y >= 0 && next.Y < height
+}
+
+// Functions that copy this snake's request types into the shared rules types the engine uses.
+func toRulesRuleset(r Ruleset) rules.Ruleset {
+	return rules.Ruleset{
+		Name:    r.Name,
+		Version: r.Version,
+		Settings: rules.Settings{
+			FoodSpawnChance:     r.Settings.FoodSpawnChance,
+			MinimumFood:         r.Settings.MinimumFood,
+			HazardDamagePerTurn: r.Settings.HazardDamagePerTurn,
+			Royale:              rules.Royale{ShrinkEveryNTurns: r.Settings.Royale.ShrinkEveryNTurns},
+			Squad:               rules.Squad(r.Settings.Squad),
+		},
+	}
+}
+
+// Function that converts the state into the shared rules package's types.
+func toRulesState(state GameState) rules.GameState {
+	return rules.GameState{
+		Game:  rules.Game{ID: state.Game.ID, Ruleset: toRulesRuleset(state.Game.Ruleset), Timeout: state.Game.Timeout, Map: state.Game.Map},
+		Turn:  state.Turn,
+		Board: toRulesBoard(state.Board),
+		You:   toRulesSnake(state.You),
+	}
+}
+
+func toRulesBoard(b Board) rules.Board {
+	board := rules.Board{Width: b.Width, Height: b.Height, Food: toRulesCoords(b.Food), Hazards: toRulesCoords(b.Hazards)}
+	for _, snake := range b.Snakes {
+		board.Snakes = append(board.Snakes, toRulesSnake(snake))
+	}
+	return board
+}
+
+func toRulesSnake(snake Battlesnake) rules.Battlesnake {
+	return rules.Battlesnake{
+		ID:      snake.ID,
+		Name:    snake.Name,
+		Health:  snake.Health,
+		Body:    toRulesCoords(snake.Body),
+		Head:    rules.Coord(snake.Head),
+		Length:  snake.Length,
+		Latency: snake.Latency,
+		Shout:   snake.Shout,
+		Squad:   snake.Squad,
+	}
+}
+
+func toRulesCoords(coords []Coord) []rules.Coord {
+	converted := make([]rules.Coord, len(coords))
+	for i, c := range coords {
+		converted[i] = rules.Coord(c)
+	}
+	return converted
+}
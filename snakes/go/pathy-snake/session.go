@@ -0,0 +1,112 @@
+package main
+
+import (
+	"sync"
+)
+
+// Sessions: what we keep about each game we are playing between requests. Requests for different games can arrive
+// at the same time, and so can the requests for squad teammates played by this server in the same game, so the
+// sessions are shared behind a lock and each snake in a game has its own. Even the requests for one snake can
+// overlap, when one that timed out is still running as the next arrives, so each session has a lock of its own
+// too, and searches get copies of what they read from it.
+
+type session struct {
+	// The strategy chosen for the game when it started. It never changes, so it can be read without the lock.
+	strategy Strategy
+
+	// Held while reading or changing anything below.
+	mu sync.Mutex
+	// Each snake's health on the turn we last saw it, by snake ID. We use this to determine if a snake ate food.
+	healths map[string]snakeHealth
+	// What we have learnt about how each other snake plays, by snake ID, and the last turn we saw to learn from.
+	opponents map[string]*opponentModel
+	previous  *GameState
+}
+
+var sessions = struct {
+	sync.Mutex
+	bySnake map[string]*session
+}{bySnake: make(map[string]*session)}
+
+// Function that returns the key of the session for the snake the state is for.
+func sessionKey(state GameState) string {
+	return state.Game.ID + "/" + state.You.ID
+}
+
+// Function that starts a session for our snake in the game, choosing its strategy from its ruleset and map.
+func startSession(state GameState) *session {
+	s := newSession(state)
+	sessions.Lock()
+	defer sessions.Unlock()
+	sessions.bySnake[sessionKey(state)] = s
+	return s
+}
+
+// Function that returns our snake's session for the game, starting one if we never saw the game start, for example because the
+// snake was restarted part way through it.
+func sessionFor(state GameState) *session {
+	sessions.Lock()
+	defer sessions.Unlock()
+	s, ok := sessions.bySnake[sessionKey(state)]
+	if !ok {
+		s = newSession(state)
+		sessions.bySnake[sessionKey(state)] = s
+	}
+	return s
+}
+
+func newSession(state GameState) *session {
+	return &session{strategy: strategyFor(state), healths: make(map[string]snakeHealth), opponents: make(map[string]*opponentModel)}
+}
+
+// Function that forgets our snake's session once the game is over.
+func endSession(state GameState) {
+	sessions.Lock()
+	defer sessions.Unlock()
+	delete(sessions.bySnake, sessionKey(state))
+}
+
+// Function that returns a copy of what we have learnt about how a snake plays, or nil if we haven't seen it move
+// yet.
+func (s *session) opponentModel(id string) *opponentModel {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	model, ok := s.opponents[id]
+	if !ok {
+		return nil
+	}
+	copied := *model
+	return &copied
+}
+
+// Function that returns a copy of the models of every other snake, by snake ID.
+func (s *session) opponentModels() map[string]*opponentModel {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	models := make(map[string]*opponentModel, len(s.opponents))
+	for id, model := range s.opponents {
+		copied := *model
+		models[id] = &copied
+	}
+	return models
+}
+
+// Function that returns a snake's health on the turn we last saw it.
+func (s *session) snakeHealth(id string) (snakeHealth, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	health, ok := s.healths[id]
+	return health, ok
+}
+
+// Function that remembers every snake's health on this turn, forgetting everything from before if reset is set.
+func (s *session) recordHealths(state GameState, reset bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if reset {
+		s.healths = make(map[string]snakeHealth)
+	}
+	for _, snake := range state.Board.Snakes {
+		s.healths[snake.ID] = snakeHealth{turn: state.Turn, health: int(snake.Health)}
+	}
+}
@@ -40,9 +40,20 @@ func (s Battlesnake) ownSnake(state GameState) bool {
 	return s.ID == state.You.ID
 }
 
-// is not us
+// is not us, or on our squad
 func (s Battlesnake) isEnemySnake(state GameState) bool {
-	return s.ID != state.You.ID
+	return s.ID != state.You.ID && !s.isTeammate(state)
+}
+
+// Function that reports whether a snake is another member of our squad in a squad game.
+func (s Battlesnake) isTeammate(state GameState) bool {
+	return state.isSquad() && s.ID != state.You.ID && s.Squad != "" && s.Squad == state.You.Squad
+}
+
+// Function that reports whether we can move through a snake's body, which we can for a teammate if the squad
+// settings allow body collisions.
+func (s Battlesnake) isPassable(state GameState) bool {
+	return s.isTeammate(state) && state.Game.Ruleset.Settings.Squad.AllowBodyCollisions
 }
 
 //func to check if a snake is on the opposite side of the board to coord
@@ -67,27 +78,42 @@ func (s Battlesnake) isLargerThanUs(state GameState) bool {
 	return s.Length >= state.You.Length
 }
 
-// Keep track of each snake's length between turns. 
-// We use this to determine if a snake ate food or not.
-var snakeHealths = make(map[string]int)
+// The health of a snake and the turn we saw it on, so a request for the same turn twice isn't compared with itself.
+// Each game's session keeps one for every snake, see session.go.
+type snakeHealth struct {
+	turn   int
+	health int
+}
 
-// Function that takes in a state and clears the snakeHealths map.
+// Function that takes in a state and clears the snake healths remembered for its game.
 func clearSnakeHealths(state GameState) {
 	if state.Turn <= 3 {
-		snakeHealths = make(map[string]int)
-		updateSnakeHealth(state)
+		sessionFor(state).recordHealths(state, true)
 	}
 }
 
 // Function to check if a snake ate food on the previous turn. 
 func didSnakeEatFood(snake Battlesnake, state GameState) bool {
+	// A stacked tail means they ate, whatever we remember about their health.
+	if n := len(snake.Body); n > 1 && snake.Body[n-1] == snake.Body[n-2] {
+		return true
+	}
+	// If we haven't seen the snake on an earlier turn, the stacked tail is all we have to go on.
+	previous, ok := sessionFor(state).snakeHealth(snake.ID)
+	if !ok || previous.turn >= state.Turn {
+		return false
+	}
 	// If the snakes health is greater than the previous turn, they ate food.
-	return int(snake.Health) >= snakeHealths[snake.ID] && state.Turn != 0
+	return int(snake.Health) >= previous.health && state.Turn != 0
+}
+
+// Function that reports whether a snake's tail will be out of the way next turn. It stays put if the snake just ate,
+// and always does in constrictor, where every snake grows every turn.
+func tailWillMove(snake Battlesnake, state GameState) bool {
+	return !state.isConstrictor() && !didSnakeEatFood(snake, state)
 }
 
 // Function to loop through all snakes and update their health.
 func updateSnakeHealth(state GameState) {
-	for _, snake := range state.Board.Snakes {
-		snakeHealths[snake.ID] = int(snake.Health)
-	}
+	sessionFor(state).recordHealths(state, false)
 }
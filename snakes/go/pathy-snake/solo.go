@@ -0,0 +1,162 @@
+package main
+
+import (
+	"expvar"
+)
+
+// Solo: with nobody else on the board the only ways to die are running out of room and running out of health, so
+// we follow a Hamiltonian cycle, a loop that goes through every cell of the board once. A snake on the cycle can
+// follow it forever however long it grows. We leave it for a shortcut to a cell further along only when we need
+// food, and never past our own tail, so the cells between our head and our tail stay free. Boards with an odd
+// number of cells have no such loop, so the top right corner is left out.
+
+// Health to keep in hand, over what getting to the next food along the cycle costs, before we go for it.
+const soloHealthMargin = 10
+
+// Served at /debug/vars, as a benchmark of how long the snake lasts on its own.
+var (
+	soloGames         = expvar.NewInt("solo_games")
+	soloTurnsSurvived = expvar.NewInt("solo_turns_survived")
+)
+
+// Function that returns the cells of the board in the order a Hamiltonian cycle visits them, or nil if the board
+// is too small to have one.
+func hamiltonianCycle(width, height int) []Coord {
+	switch {
+	case width < 2 || height < 2:
+		return nil
+	case width%2 == 0:
+		return columnCycle(width, height)
+	case height%2 == 0:
+		cycle := columnCycle(height, width)
+		for i, c := range cycle {
+			cycle[i] = Coord{X: c.Y, Y: c.X}
+		}
+		return cycle
+	}
+	return cornerCycle(width, height)
+}
+
+// Function that returns a cycle along the bottom row, then up and down the columns from right to left, which
+// ends next to where it started if the width is even.
+func columnCycle(width, height int) []Coord {
+	var cycle []Coord
+	for x := 0; x < width; x++ {
+		cycle = append(cycle, Coord{X: x, Y: 0})
+	}
+	for x := width - 1; x >= 0; x-- {
+		if (width-1-x)%2 == 0 {
+			for y := 1; y < height; y++ {
+				cycle = append(cycle, Coord{X: x, Y: y})
+			}
+		} else {
+			for y := height - 1; y >= 1; y-- {
+				cycle = append(cycle, Coord{X: x, Y: y})
+			}
+		}
+	}
+	return cycle
+}
+
+// Function that returns a cycle through every cell but the top right corner of a board with an odd width and
+// height: up the left column, through the top two rows two columns at a time, then back and forth along the rows
+// below, ending next to where it started.
+func cornerCycle(width, height int) []Coord {
+	var cycle []Coord
+	for y := 0; y <= height-3; y++ {
+		cycle = append(cycle, Coord{X: 0, Y: y})
+	}
+	for x := 0; x <= width-3; x += 2 {
+		cycle = append(cycle, Coord{X: x, Y: height - 2}, Coord{X: x, Y: height - 1}, Coord{X: x + 1, Y: height - 1}, Coord{X: x + 1, Y: height - 2})
+	}
+	cycle = append(cycle, Coord{X: width - 1, Y: height - 2})
+	for y := height - 3; y >= 0; y-- {
+		if (height-3-y)%2 == 0 {
+			for x := width - 1; x >= 1; x-- {
+				cycle = append(cycle, Coord{X: x, Y: y})
+			}
+		} else {
+			for x := 1; x <= width-1; x++ {
+				cycle = append(cycle, Coord{X: x, Y: y})
+			}
+		}
+	}
+	return cycle
+}
+
+// Function that returns the move that keeps us on the cycle, or takes a shortcut along it towards food when our
+// health needs it. It returns false if our head is off the cycle or no move along it is safe, to leave the move to
+// the path.
+func soloMove(state GameState, grid *Grid) (string, bool) {
+	cycle := hamiltonianCycle(state.Board.Width, state.Board.Height)
+	index := make(map[Coord]int, len(cycle))
+	for i, c := range cycle {
+		index[c] = i
+	}
+	head, onCycle := index[state.You.Head]
+	if !onCycle {
+		return "", false
+	}
+	// How many steps along the cycle it is from our head to a cell.
+	ahead := func(c Coord) (int, bool) {
+		i, ok := index[c]
+		return (i - head + len(cycle)) % len(cycle), ok
+	}
+
+	// Shortcuts can't go past our tail. A tail that is still on our head, at the start of the game, is no limit.
+	limit := len(cycle)
+	if tail, ok := ahead(state.You.Body[len(state.You.Body)-1]); ok && tail > 0 {
+		limit = tail
+	}
+	food := len(cycle)
+	for _, f := range state.Board.Food {
+		if d, ok := ahead(f); ok && d > 0 && d < food {
+			food = d
+		}
+	}
+	hungry := food < len(cycle) && int(state.You.Health) <= cycleCost(state, cycle, head, food)+soloHealthMargin
+
+	bestMove, bestAhead, bestAvoids := "", 0, false
+	for _, move := range []string{"up", "down", "left", "right"} {
+		c, ok := stepCoord(state, state.You.Head, move)
+		if !ok {
+			continue
+		}
+		d, ok := ahead(c)
+		if !ok || d == 0 || d > limit {
+			continue
+		}
+		if cell := grid.Get(c.X, c.Y); cell == nil || !cell.Walkable || roomFrom(state, grid, c, len(state.You.Body)) < len(state.You.Body) {
+			continue
+		}
+		if hungry {
+			// Get as far along as we can without passing the food.
+			if d <= food && d > bestAhead {
+				bestMove, bestAhead = move, d
+			}
+			continue
+		}
+		// Otherwise stay on the cycle, keeping off food we don't need and hazards where we can.
+		avoids := !containsCoord(state.Board.Food, c) && hazardCount(state, c) == 0
+		if bestMove == "" || (avoids && !bestAvoids) || (avoids == bestAvoids && d < bestAhead) {
+			bestMove, bestAhead, bestAvoids = move, d, avoids
+		}
+	}
+	return bestMove, bestMove != ""
+}
+
+// Function that returns the health it costs to follow the cycle for the given number of steps from our head.
+func cycleCost(state GameState, cycle []Coord, head, steps int) int {
+	cost := 0
+	for i := 1; i <= steps; i++ {
+		c := cycle[(head+i)%len(cycle)]
+		cost += 1 + int(state.Game.Ruleset.Settings.HazardDamagePerTurn)*hazardCount(state, c)
+	}
+	return cost
+}
+
+// Function that records how many turns a solo game lasted.
+func recordSoloGame(state GameState) {
+	soloGames.Add(1)
+	soloTurnsSurvived.Set(int64(state.Turn))
+}
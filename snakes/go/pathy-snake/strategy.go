@@ -0,0 +1,195 @@
+package main
+
+import (
+	"log"
+	"strings"
+)
+
+// Strategies: each way of picking a move is a Strategy, and the registry below says which one plays each kind of
+// game, by ruleset and map. A pipeline tries several strategies in turn, so a search can hand over to paths when it
+// doesn't apply or runs out of time. The choice is made once per game when it starts, see session.go, so adding
+// logic for a new game mode means registering a strategy for it here rather than another branch in move.
+
+// Strategy picks our move for a turn, or returns false to leave it to the next strategy in a pipeline.
+type Strategy interface {
+	Name() string
+	Move(state GameState) (string, bool)
+}
+
+// A Strategy that asks each of its strategies in order and takes the first move it gets.
+type pipeline []Strategy
+
+func (p pipeline) Name() string {
+	names := make([]string, len(p))
+	for i, s := range p {
+		names[i] = s.Name()
+	}
+	return strings.Join(names, "+")
+}
+
+func (p pipeline) Move(state GameState) (string, bool) {
+	for _, s := range p {
+		if nextMove, ok := s.Move(state); ok {
+			return nextMove, true
+		}
+	}
+	return "", false
+}
+
+// Search ahead once only one other snake is left, see duel.go.
+type duelStrategy struct{}
+
+func (duelStrategy) Name() string {
+	return "duel"
+}
+
+func (duelStrategy) Move(state GameState) (string, bool) {
+	nextMove, depth, ok := duelMove(state)
+	if ok {
+		log.Printf("%s MOVE %d: %s (duel search %d turns ahead)\n", sanatizeInput(state.Game.ID), isNumber(state.Turn), nextMove, depth)
+	}
+	return nextMove, ok
+}
+
+// Search with MCTS when there are three or more snakes and the config selects it, see mcts.go.
+type mctsStrategy struct{}
+
+func (mctsStrategy) Name() string {
+	return "mcts"
+}
+
+func (mctsStrategy) Move(state GameState) (string, bool) {
+	nextMove, playouts, ok := mctsMove(state)
+	if ok {
+		log.Printf("%s MOVE %d: %s (%d MCTS playouts)\n", sanatizeInput(state.Game.ID), isNumber(state.Turn), nextMove, playouts)
+	}
+	return nextMove, ok
+}
+
+// Follow a path to a target cell, see pathing.go. It always has a move.
+type pathStrategy struct{}
+
+func (pathStrategy) Name() string {
+	return "path"
+}
+
+func (pathStrategy) Move(state GameState) (string, bool) {
+	nextMove := createSnakeMap(state, newGameRand(state)).Move
+	log.Printf("%s MOVE %d: %s\n", sanatizeInput(state.Game.ID), isNumber(state.Turn), nextMove)
+	return nextMove, true
+}
+
+// Follow a path, with the rules spring-league-2022 moves by as a veto over it, see ensemble.go. It always has a
+// move.
+type ensembleStrategy struct{}
+
+func (ensembleStrategy) Name() string {
+	return "ensemble"
+}
+
+func (ensembleStrategy) Move(state GameState) (string, bool) {
+	nextMove, trace := ensembleMove(state, newGameRand(state))
+	if len(trace) > 0 {
+		log.Printf("%s MOVE %d: %s (ensemble: %s)\n", sanatizeInput(state.Game.ID), isNumber(state.Turn), nextMove, strings.Join(trace, ", "))
+	} else {
+		log.Printf("%s MOVE %d: %s\n", sanatizeInput(state.Game.ID), isNumber(state.Turn), nextMove)
+	}
+	return nextMove, true
+}
+
+// Take the move that leaves us the most room to grow into, see constrictor.go.
+type constrictorStrategy struct{}
+
+func (constrictorStrategy) Name() string {
+	return "constrictor"
+}
+
+func (constrictorStrategy) Move(state GameState) (string, bool) {
+	grid := NewGrid(state.Board.Width, state.Board.Height, 0, 0)
+	addSnakesToGrid(state, grid)
+	changeHazardsCost(state, grid)
+	nextMove, territory, ok := constrictorMove(state, grid)
+	if !ok {
+		return "", false
+	}
+	nextMove = vetoHeadToHead(state, grid, BattlesnakeMoveResponse{Move: nextMove}).Move
+	log.Printf("%s MOVE %d: %s (constrictor, %d cells of territory)\n", sanatizeInput(state.Game.ID), isNumber(state.Turn), nextMove, territory)
+	return nextMove, true
+}
+
+// Follow a cycle through the whole board, see solo.go.
+type soloStrategy struct{}
+
+func (soloStrategy) Name() string {
+	return "solo"
+}
+
+func (soloStrategy) Move(state GameState) (string, bool) {
+	grid := NewGrid(state.Board.Width, state.Board.Height, 0, 0)
+	addSnakesToGrid(state, grid)
+	changeHazardsCost(state, grid)
+	nextMove, ok := soloMove(state, grid)
+	if ok {
+		log.Printf("%s MOVE %d: %s (solo cycle)\n", sanatizeInput(state.Game.ID), isNumber(state.Turn), nextMove)
+	}
+	return nextMove, ok
+}
+
+// Play the first moves of a game from the opening book, see book.go.
+type bookStrategy struct{}
+
+func (bookStrategy) Name() string {
+	return "book"
+}
+
+func (bookStrategy) Move(state GameState) (string, bool) {
+	nextMove, ok := bookMove(openings, state)
+	if ok {
+		log.Printf("%s MOVE %d: %s (opening book)\n", sanatizeInput(state.Game.ID), isNumber(state.Turn), nextMove)
+	}
+	return nextMove, ok
+}
+
+// The strategy for games nothing more specific is registered for.
+var defaultStrategy Strategy = pipeline{duelStrategy{}, mctsStrategy{}, ensembleStrategy{}}
+
+// The kind of game a strategy is registered for. An empty ruleset or map matches any.
+type gameMode struct {
+	ruleset string
+	gameMap string
+}
+
+var strategies = make(map[gameMode]Strategy)
+
+// Function that registers the strategy to play games with the given ruleset and map. Either can be empty to
+// register for every ruleset or every map.
+func registerStrategy(ruleset, gameMap string, s Strategy) {
+	strategies[gameMode{ruleset: ruleset, gameMap: gameMap}] = s
+}
+
+func init() {
+	// The opening book only has standard and wrapped games in it.
+	registerStrategy("standard", "", pipeline{bookStrategy{}, defaultStrategy})
+	registerStrategy("wrapped", "", pipeline{bookStrategy{}, defaultStrategy})
+	registerStrategy("royale", "", defaultStrategy)
+	// Food doesn't exist in constrictor, so fill space rather than follow paths to it.
+	registerStrategy("constrictor", "", pipeline{duelStrategy{}, mctsStrategy{}, constrictorStrategy{}, pathStrategy{}})
+	registerStrategy("squad", "", defaultStrategy)
+	// With nobody else on the board there is nothing to search against, only room and health to keep.
+	registerStrategy("solo", "", pipeline{soloStrategy{}, pathStrategy{}})
+	// The searches play hazards as damage, but on these they are walls, which only the grid knows about.
+	registerStrategy("arcade-maze", "", pathStrategy{})
+	registerStrategy("", "hz_rivers_bridges", pathStrategy{})
+}
+
+// Function that returns the strategy registered for the game's ruleset and map. A registration for both wins over
+// one for the map alone, which wins over one for the ruleset alone, and anything else gets defaultStrategy.
+func strategyFor(state GameState) Strategy {
+	ruleset, gameMap := state.Game.Ruleset.Name, state.Game.Map
+	for _, mode := range []gameMode{{ruleset, gameMap}, {"", gameMap}, {ruleset, ""}} {
+		if s, ok := strategies[mode]; ok {
+			return s
+		}
+	}
+	return defaultStrategy
+}
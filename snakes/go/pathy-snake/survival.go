@@ -0,0 +1,109 @@
+package main
+
+import (
+	"log"
+)
+
+// Survival mode: when there is barely room for us, stop looking for food or a random cell and play for time by
+// following our own tail, which always moves out of the way. Of the cells next to our head that can still reach
+// the tail, we take the one furthest from it, so we coil through the space we have rather than run straight
+// round in the tightest loop.
+
+// We are in a tight space when the cells we can reach number fewer than this many times our length.
+const survivalRoomFactor = 2
+
+// Moves of health to spare, over the distance to the nearest food we can reach, before we stop stalling and go
+// to eat.
+const survivalFoodMargin = 2
+
+// Function that reports whether the region we can reach is barely larger than our body, and how big it is.
+func inTightSpace(state GameState, grid *Grid) (bool, int) {
+	limit := survivalRoomFactor * len(state.You.Body)
+	// The head counts itself, and isn't room to move into.
+	room := areaFrom(state, grid, state.You.Head, nil, limit+1) - 1
+	return room < limit, room
+}
+
+// Function that returns the cell next to our head to move to so we keep our tail in reach, or nil if no
+// walkable cell next to our head can reach it.
+func survivalTarget(state GameState, grid *Grid) *Cell {
+	distances := tailDistances(state, grid)
+	var best *Cell
+	bestDistance := -1
+	for _, move := range []string{"up", "down", "left", "right"} {
+		c, ok := stepCoord(state, state.You.Head, move)
+		if !ok {
+			continue
+		}
+		cell := grid.Get(c.X, c.Y)
+		if cell == nil || !cell.Walkable {
+			continue
+		}
+		if distance, reachable := distances[c]; reachable && distance > bestDistance {
+			best, bestDistance = cell, distance
+		}
+	}
+	return best
+}
+
+// Function that returns the number of moves from our tail to every walkable cell it can reach without going
+// through our head.
+func tailDistances(state GameState, grid *Grid) map[Coord]int {
+	tail := state.You.Body[len(state.You.Body)-1]
+	distances := map[Coord]int{tail: 0, state.You.Head: -1}
+	frontier := []Coord{tail}
+	for step := 1; len(frontier) > 0; step++ {
+		var next []Coord
+		for _, c := range frontier {
+			for _, move := range []string{"up", "down", "left", "right"} {
+				n, ok := stepCoord(state, c, move)
+				if !ok {
+					continue
+				}
+				if _, seen := distances[n]; seen {
+					continue
+				}
+				if cell := grid.Get(n.X, n.Y); cell != nil && cell.Walkable {
+					distances[n] = step
+					next = append(next, n)
+				}
+			}
+		}
+		frontier = next
+	}
+	delete(distances, state.You.Head)
+	return distances
+}
+
+// Function that returns the number of moves to the nearest food we can reach, or false if we can't reach any.
+func nearestFoodDistance(state GameState, grid *Grid) (int, bool) {
+	distances := moveDistances(state, grid, state.You.Head)
+	nearest, found := 0, false
+	for _, food := range state.Board.Food {
+		if distance, ok := distances[food]; ok && (!found || distance < nearest) {
+			nearest, found = distance, true
+		}
+	}
+	return nearest, found
+}
+
+// Function that returns the survival target if the room we can reach is small for our length, and we have
+// enough health to stall in it rather than go to the nearest food we can reach. With no food in reach, stalling
+// is all we can do. Tails never move in constrictor, so there is nothing to follow.
+func survivalModeTarget(state GameState, grid *Grid) *Cell {
+	if state.isConstrictor() {
+		return nil
+	}
+	tight, room := inTightSpace(state, grid)
+	if !tight {
+		return nil
+	}
+	if distance, ok := nearestFoodDistance(state, grid); ok && int(state.You.Health) <= distance+survivalFoodMargin {
+		return nil
+	}
+	target := survivalTarget(state, grid)
+	if target != nil {
+		log.Printf("%s MOVE %d: survival mode, %d cells of room for a length of %d\n", sanatizeInput(state.Game.ID), isNumber(state.Turn), room, len(state.You.Body))
+	}
+	return target
+}
@@ -0,0 +1,233 @@
+/*
+Package rating keeps a local ledger of Elo ratings for snake versions. A snake version is its name, the git
+revision it was built from and a hash of the config it ran with, so a change to the code or to a tuning file
+shows up as a new entry rather than being blended into the old rating.
+
+Multiplayer games are rated as every pair of snakes playing a match, scored by who placed higher, with the
+K-factor shared out between the opponents so a four snake game moves ratings about as much as a duel.
+*/
+package rating
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	ledgerVersion = 1
+	// InitialRating is the rating every new snake version starts at.
+	InitialRating = 1500.0
+	// DefaultK is the Elo K-factor used when the ledger doesn't set one.
+	DefaultK = 32.0
+)
+
+// Identity names one version of a snake.
+type Identity struct {
+	Name       string `json:"name"`
+	Revision   string `json:"revision"`
+	ConfigHash string `json:"configHash,omitempty"`
+}
+
+// Key is the identity's key in the ledger, e.g. "pathy@1a2b3c4" or "pathy@1a2b3c4+9f8e7d6c5b4a".
+func (i Identity) Key() string {
+	key := i.Name + "@" + i.Revision
+	if i.ConfigHash != "" {
+		key += "+" + i.ConfigHash
+	}
+	return key
+}
+
+// Point is a snake's rating after one game.
+type Point struct {
+	Game   string    `json:"game"`
+	Time   time.Time `json:"time"`
+	Rating float64   `json:"rating"`
+	Place  int       `json:"place"`
+	Field  int       `json:"field"`
+}
+
+// Entry is everything the ledger knows about one snake version.
+type Entry struct {
+	Identity
+	Rating  float64 `json:"rating"`
+	Games   int     `json:"games"`
+	Wins    int     `json:"wins"`
+	History []Point `json:"history"`
+}
+
+// Ledger is the JSON file holding every rated snake version.
+type Ledger struct {
+	Version int               `json:"version"`
+	K       float64           `json:"k"`
+	Entries map[string]*Entry `json:"entries"`
+}
+
+// Standing is where a snake finished in one game. Lower places are better and equal places are draws.
+type Standing struct {
+	Identity Identity
+	Place    int
+}
+
+// New returns an empty ledger.
+func New() *Ledger {
+	return &Ledger{Version: ledgerVersion, K: DefaultK, Entries: make(map[string]*Entry)}
+}
+
+// Load reads a ledger from path. A missing file is not an error and gives an empty ledger.
+func Load(path string) (*Ledger, error) {
+	data, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	l := New()
+	if err := json.Unmarshal(data, l); err != nil {
+		return nil, err
+	}
+	if l.Entries == nil {
+		l.Entries = make(map[string]*Entry)
+	}
+	if l.K == 0 {
+		l.K = DefaultK
+	}
+	return l, nil
+}
+
+// Save writes the ledger to path. The file is replaced atomically so a crash never leaves half a ledger behind.
+func (l *Ledger) Save(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".ledger-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Entry returns the ledger entry for id, creating it at the initial rating if needed.
+func (l *Ledger) Entry(id Identity) *Entry {
+	entry, ok := l.Entries[id.Key()]
+	if !ok {
+		entry = &Entry{Identity: id, Rating: InitialRating}
+		l.Entries[id.Key()] = entry
+	}
+	return entry
+}
+
+// Record updates the ratings of every snake in a finished game. All updates are worked out from the ratings
+// before the game, so the order of standings doesn't matter. A snake can't play itself, so standings with the
+// same identity twice are rejected and the ledger is left as it was.
+func (l *Ledger) Record(game string, at time.Time, standings []Standing) error {
+	seen := make(map[string]bool, len(standings))
+	for _, s := range standings {
+		if seen[s.Identity.Key()] {
+			return fmt.Errorf("game %s: %s is in the standings more than once", game, s.Identity.Key())
+		}
+		seen[s.Identity.Key()] = true
+	}
+	if len(standings) < 2 {
+		return nil
+	}
+	before := make([]float64, len(standings))
+	for i, s := range standings {
+		before[i] = l.Entry(s.Identity).Rating
+	}
+
+	k := l.K / float64(len(standings)-1)
+	for i, s := range standings {
+		delta := 0.0
+		for j, other := range standings {
+			if i == j {
+				continue
+			}
+			delta += k * (score(s.Place, other.Place) - Expected(before[i], before[j]))
+		}
+		entry := l.Entry(s.Identity)
+		entry.Rating = before[i] + delta
+		entry.Games++
+		if s.Place == 1 && uniqueFirst(standings) {
+			entry.Wins++
+		}
+		entry.History = append(entry.History, Point{Game: game, Time: at, Rating: entry.Rating, Place: s.Place, Field: len(standings)})
+	}
+	return nil
+}
+
+// HasGame reports whether a game has already been recorded under the given name.
+func (l *Ledger) HasGame(game string) bool {
+	for _, entry := range l.Entries {
+		for _, point := range entry.History {
+			if point.Game == game {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Leaderboard returns every entry, best rating first.
+func (l *Ledger) Leaderboard() []*Entry {
+	entries := make([]*Entry, 0, len(l.Entries))
+	for _, entry := range l.Entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Rating != entries[j].Rating {
+			return entries[i].Rating > entries[j].Rating
+		}
+		return entries[i].Key() < entries[j].Key()
+	})
+	return entries
+}
+
+// Expected is the Elo expected score of a player rated a against one rated b.
+func Expected(a, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// ConfigHash returns a short, stable hash of a snake's config file.
+func ConfigHash(config []byte) string {
+	sum := sha256.Sum256(config)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+func score(place, otherPlace int) float64 {
+	switch {
+	case place < otherPlace:
+		return 1
+	case place == otherPlace:
+		return 0.5
+	}
+	return 0
+}
+
+func uniqueFirst(standings []Standing) bool {
+	first := 0
+	for _, s := range standings {
+		if s.Place == 1 {
+			first++
+		}
+	}
+	return first == 1
+}
@@ -0,0 +1,119 @@
+package rating
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var (
+	pathy  = Identity{Name: "pathy", Revision: "abc123"}
+	spring = Identity{Name: "spring", Revision: "abc123"}
+	ruby   = Identity{Name: "ruby", Revision: "abc123"}
+)
+
+func TestRecordDuel(t *testing.T) {
+	// Arrange
+	l := New()
+
+	// Act
+	l.Record("game-1", time.Unix(0, 0), []Standing{{pathy, 1}, {spring, 2}})
+
+	// Assert
+	if got := l.Entry(pathy).Rating; got != InitialRating+DefaultK/2 {
+		t.Errorf("expected the winner to gain half of K between equal snakes, got %f", got)
+	}
+	if got := l.Entry(spring).Rating; got != InitialRating-DefaultK/2 {
+		t.Errorf("expected the loser to drop half of K between equal snakes, got %f", got)
+	}
+	if l.Entry(pathy).Wins != 1 || l.Entry(spring).Games != 1 {
+		t.Errorf("unexpected counts %+v %+v", l.Entry(pathy), l.Entry(spring))
+	}
+}
+
+func TestRecordDrawAndMultiplayerConserveRating(t *testing.T) {
+	// Arrange
+	l := New()
+
+	// Act
+	l.Record("game-1", time.Unix(0, 0), []Standing{{pathy, 1}, {spring, 1}})
+	l.Record("game-2", time.Unix(0, 0), []Standing{{pathy, 2}, {spring, 1}, {ruby, 3}})
+
+	// Assert
+	total := 0.0
+	for _, entry := range l.Leaderboard() {
+		total += entry.Rating
+	}
+	if math.Abs(total-3*InitialRating) > 1e-9 {
+		t.Errorf("Elo should be zero sum, total is %f", total)
+	}
+	if board := l.Leaderboard(); board[0].Name != "spring" || board[2].Name != "ruby" {
+		t.Errorf("unexpected leaderboard order %s %s %s", board[0].Key(), board[1].Key(), board[2].Key())
+	}
+	if l.Entry(pathy).Wins != 0 {
+		t.Errorf("a shared first place is not a win")
+	}
+}
+
+func TestConfigChangesIdentity(t *testing.T) {
+	tuned := pathy
+	tuned.ConfigHash = ConfigHash([]byte(`{"foodCost":0.4}`))
+	if tuned.Key() == pathy.Key() {
+		t.Errorf("config hash should be part of the key")
+	}
+}
+
+func TestRecordRejectsDuplicates(t *testing.T) {
+	// Arrange
+	l := New()
+
+	// Act
+	err := l.Record("game-1", time.Unix(0, 0), []Standing{{pathy, 1}, {spring, 2}, {pathy, 3}})
+
+	// Assert
+	if err == nil {
+		t.Errorf("expected a snake in the standings twice to be rejected")
+	}
+	if len(l.Entries) != 0 {
+		t.Errorf("expected a rejected game to leave the ledger alone, got %d entries", len(l.Entries))
+	}
+}
+
+func TestHasGame(t *testing.T) {
+	// Arrange
+	l := New()
+
+	// Act
+	l.Record("game-1", time.Unix(0, 0), []Standing{{pathy, 1}, {spring, 2}})
+
+	// Assert
+	if !l.HasGame("game-1") || l.HasGame("game-2") {
+		t.Errorf("expected only game-1 to be recorded")
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "ratings.json")
+	l := New()
+	l.Record("game-1", time.Unix(0, 0), []Standing{{pathy, 1}, {spring, 2}})
+
+	// Act
+	if err := l.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	missing, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+
+	// Assert
+	if err != nil || len(missing.Entries) != 0 {
+		t.Errorf("a missing ledger should load empty, got %v", err)
+	}
+	if loaded.Entry(pathy).Rating != l.Entry(pathy).Rating || len(loaded.Entry(pathy).History) != 1 {
+		t.Errorf("ledger did not round trip: %+v", loaded.Entry(pathy))
+	}
+}
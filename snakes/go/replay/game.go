@@ -0,0 +1,88 @@
+/*
+Package replay turns a recorded game into something that can be stepped through turn by turn and drawn in a
+terminal. It is what `arena view` is built on. Games come through package transcript, from an arena recording, the
+battlesnake CLI or the official game export.
+*/
+package replay
+
+import (
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+	"github.com/es-na-battlesnake/snakes/snakes/go/transcript"
+)
+
+// Snake is a snake taking part in a game, with the glyph it is drawn with.
+type Snake struct {
+	ID    string
+	Name  string
+	Glyph rune
+}
+
+// Turn is the board at the start of one turn and what happened on it.
+type Turn struct {
+	Turn       int
+	Board      rules.Board
+	Moves      map[string]string
+	Latency    map[string]int64
+	Eliminated []rules.Elimination
+}
+
+// Game is a whole game ready to replay.
+type Game struct {
+	ID      string
+	Ruleset string
+	Map     string
+	Snakes  []Snake
+	Turns   []Turn
+	// Outcome is a one line description of how the game ended, empty if unknown.
+	Outcome string
+}
+
+// FromTranscript builds a Game from a transcript, for games recorded by the arena, played by the battlesnake CLI
+// or exported from the official engine.
+func FromTranscript(t transcript.Transcript) Game {
+	g := Game{ID: t.Game.ID, Ruleset: t.Game.Ruleset.Name, Map: t.Game.Map}
+	for _, turn := range t.Turns {
+		g.Turns = append(g.Turns, Turn{Turn: turn.Turn, Board: turn.Board, Moves: turn.Moves, Latency: turn.Latency, Eliminated: turn.Eliminated})
+		for _, snake := range turn.Board.Snakes {
+			g.addSnake(snake.ID, snake.Name)
+		}
+	}
+	g.setOutcome(t.Result.IsDraw, t.Result.WinnerName)
+	return g
+}
+
+func (g *Game) setOutcome(isDraw bool, winner string) {
+	switch {
+	case isDraw:
+		g.Outcome = "draw"
+	case winner != "":
+		g.Outcome = "winner: " + winner
+	}
+}
+
+// Snake returns the snake with the given ID. Unknown IDs get a '?' glyph.
+func (g *Game) Snake(id string) Snake {
+	for _, snake := range g.Snakes {
+		if snake.ID == id {
+			return snake
+		}
+	}
+	return Snake{ID: id, Name: id, Glyph: '?'}
+}
+
+// addSnake registers a snake the first time it is seen and gives it the next free letter.
+func (g *Game) addSnake(id, name string) {
+	for _, snake := range g.Snakes {
+		if snake.ID == id {
+			return
+		}
+	}
+	glyph := '?'
+	if len(g.Snakes) < 26 {
+		glyph = rune('A' + len(g.Snakes))
+	}
+	if name == "" {
+		name = id
+	}
+	g.Snakes = append(g.Snakes, Snake{ID: id, Name: name, Glyph: glyph})
+}
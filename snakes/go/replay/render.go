@@ -0,0 +1,144 @@
+package replay
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// ANSI escape codes used when drawing in colour.
+const (
+	ansiReset  = "\033[0m"
+	ansiBold   = "\033[1m"
+	ansiFood   = "\033[31m"
+	ansiHazard = "\033[45m"
+)
+
+var snakeColours = []string{"\033[36m", "\033[33m", "\033[32m", "\033[34m", "\033[35m", "\033[37m", "\033[91m", "\033[96m"}
+
+// RenderOptions controls how a turn is drawn.
+type RenderOptions struct {
+	Colour bool
+}
+
+// Render draws turn index of the game: a header, the board with y=0 at the bottom like the official viewer, and a
+// line per snake with its health, length, the move it made and how long it took.
+//
+// Snakes are drawn as their letter, upper case for the head and lower case for the body. Food is '*', hazards
+// are 'x' and an empty cell is '.'. With colour on, every snake gets its own colour, heads are bold, and
+// hazards are shown as a background so snakes standing in them are still visible.
+func Render(w io.Writer, g *Game, index int, opts RenderOptions) {
+	if len(g.Turns) == 0 {
+		fmt.Fprintln(w, "No turns in this game.")
+		return
+	}
+	turn := g.Turns[index]
+	board := turn.Board
+
+	title := fmt.Sprintf("Game %s (%s", g.ID, g.Ruleset)
+	if g.Map != "" {
+		title += ", " + g.Map
+	}
+	title += ")"
+	fmt.Fprintf(w, "%s  turn %d  [%d/%d]\n\n", title, turn.Turn, index+1, len(g.Turns))
+
+	cells := make(map[rules.Coord]string)
+	hazards := make(map[rules.Coord]int)
+	for _, hazard := range board.Hazards {
+		hazards[hazard]++
+	}
+	for _, food := range board.Food {
+		cells[food] = paint(opts, ansiFood, "*")
+	}
+	for _, snake := range board.Snakes {
+		s := g.Snake(snake.ID)
+		colour := snakeColours[g.snakeIndex(snake.ID)%len(snakeColours)]
+		// Draw from the tail up so the head wins when a snake is stacked on itself.
+		for j := len(snake.Body) - 1; j >= 0; j-- {
+			if j == 0 {
+				cells[snake.Body[j]] = paint(opts, colour+ansiBold, string(s.Glyph))
+				continue
+			}
+			cells[snake.Body[j]] = paint(opts, colour, string(unicode.ToLower(s.Glyph)))
+		}
+	}
+
+	for y := board.Height - 1; y >= 0; y-- {
+		var row strings.Builder
+		fmt.Fprintf(&row, "%3d ", y)
+		for x := 0; x < board.Width; x++ {
+			c := rules.Coord{X: x, Y: y}
+			glyph, ok := cells[c]
+			switch {
+			case ok && hazards[c] > 0 && opts.Colour:
+				glyph = ansiHazard + glyph + ansiReset
+			case ok:
+			case hazards[c] > 0:
+				glyph = paint(opts, ansiHazard, "x")
+			default:
+				glyph = "."
+			}
+			row.WriteString(glyph)
+			row.WriteString(" ")
+		}
+		fmt.Fprintln(w, strings.TrimRight(row.String(), " "))
+	}
+	var axis strings.Builder
+	axis.WriteString("    ")
+	for x := 0; x < board.Width; x++ {
+		fmt.Fprintf(&axis, "%-2d", x%100)
+	}
+	fmt.Fprintln(w, strings.TrimRight(axis.String(), " "))
+	fmt.Fprintln(w, "")
+
+	alive := make(map[string]rules.Battlesnake)
+	for _, snake := range board.Snakes {
+		alive[snake.ID] = snake
+	}
+	for _, s := range g.Snakes {
+		snake, ok := alive[s.ID]
+		if !ok {
+			continue
+		}
+		move := turn.Moves[s.ID]
+		if move == "" {
+			move = "-"
+		}
+		latency := ""
+		if ms, ok := turn.Latency[s.ID]; ok {
+			latency = fmt.Sprintf("%dms", ms)
+		}
+		colour := snakeColours[g.snakeIndex(s.ID)%len(snakeColours)]
+		fmt.Fprintf(w, "%s %-20s health %3d  length %3d  move %-5s %s\n", paint(opts, colour+ansiBold, string(s.Glyph)), s.Name, snake.Health, len(snake.Body), move, latency)
+	}
+	for _, elimination := range turn.Eliminated {
+		s := g.Snake(elimination.ID)
+		by := ""
+		if elimination.By != "" && elimination.By != elimination.ID {
+			by = " by " + g.Snake(elimination.By).Name
+		}
+		fmt.Fprintf(w, "%c %s eliminated: %s%s\n", s.Glyph, s.Name, elimination.Cause, by)
+	}
+	if index == len(g.Turns)-1 && g.Outcome != "" {
+		fmt.Fprintf(w, "Game over, %s\n", g.Outcome)
+	}
+}
+
+func (g *Game) snakeIndex(id string) int {
+	for i, snake := range g.Snakes {
+		if snake.ID == id {
+			return i
+		}
+	}
+	return len(g.Snakes)
+}
+
+func paint(opts RenderOptions, code, s string) string {
+	if !opts.Colour {
+		return s
+	}
+	return code + s + ansiReset
+}
@@ -0,0 +1,130 @@
+package replay
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/arena"
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+	"github.com/es-na-battlesnake/snakes/snakes/go/transcript"
+)
+
+func testRecording() arena.Recording {
+	board := func(turn int) rules.Board {
+		return rules.Board{
+			Width:   3,
+			Height:  3,
+			Food:    []rules.Coord{{X: 2, Y: 2}},
+			Hazards: []rules.Coord{{X: 0, Y: 2}},
+			Snakes: []rules.Battlesnake{
+				{ID: "snake-1", Name: "pathy", Health: int32(100 - turn), Body: []rules.Coord{{X: 0, Y: turn}, {X: 0, Y: 0}, {X: 0, Y: 0}}},
+				{ID: "snake-2", Name: "spring", Health: 90, Body: []rules.Coord{{X: 2, Y: 0}, {X: 1, Y: 0}}},
+			},
+		}
+	}
+	return arena.Recording{
+		Header: arena.Header{
+			Game:   rules.Game{ID: "arena-1", Ruleset: rules.Ruleset{Name: rules.RulesetStandard}, Map: rules.MapStandard},
+			Snakes: []arena.SnakeConfig{{ID: "snake-1", Name: "pathy"}, {ID: "snake-2", Name: "spring"}},
+		},
+		Frames: []arena.Frame{
+			{Turn: 0, Board: board(0), Moves: map[string]string{"snake-1": "up", "snake-2": "up"}, Latency: map[string]int64{"snake-1": 12}},
+			{Turn: 1, Board: board(1)},
+			{Turn: 2, Board: board(2), Eliminated: []rules.Elimination{{ID: "snake-2", Cause: rules.EliminatedByCollision, By: "snake-1"}}},
+		},
+		Result: arena.Result{WinnerID: "snake-1", WinnerName: "pathy"},
+	}
+}
+
+func TestRender(t *testing.T) {
+	// Arrange
+	g := FromTranscript(transcript.FromRecording(testRecording()))
+	var out bytes.Buffer
+
+	// Act
+	Render(&out, &g, 0, RenderOptions{})
+
+	// Assert
+	expected := []string{
+		"  2 x . *",
+		"  1 . . .",
+		"  0 A b B",
+		"A pathy                health 100  length   3  move up    12ms",
+	}
+	for _, line := range expected {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("expected line %q in:\n%s", line, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "\033[") {
+		t.Errorf("expected no colour codes without Colour")
+	}
+}
+
+func TestRenderLastTurn(t *testing.T) {
+	// Arrange
+	g := FromTranscript(transcript.FromRecording(testRecording()))
+	var out bytes.Buffer
+
+	// Act
+	Render(&out, &g, 2, RenderOptions{Colour: true})
+
+	// Assert
+	for _, expected := range []string{"B spring eliminated: snake-collision by pathy", "Game over, winner: pathy"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestViewer(t *testing.T) {
+	// Arrange
+	g := FromTranscript(transcript.FromRecording(testRecording()))
+	v := Viewer{Game: &g}
+
+	// Act & Assert
+	v.Do(ActionBack)
+	if v.Index != 0 {
+		t.Errorf("expected to stay on the first turn, got %d", v.Index)
+	}
+	v.Do(ActionLast)
+	v.Do(ActionForward)
+	if v.Index != 2 {
+		t.Errorf("expected to stay on the last turn, got %d", v.Index)
+	}
+	v.Do(ActionTogglePlay)
+	if !v.Playing || v.Index != 0 {
+		t.Errorf("expected play at the end to restart, got index %d playing %t", v.Index, v.Playing)
+	}
+	v.Tick()
+	v.Tick()
+	if v.Playing || v.Index != 2 {
+		t.Errorf("expected playing to stop at the end, got index %d playing %t", v.Index, v.Playing)
+	}
+	if !v.JumpTo(1) || v.Index != 1 {
+		t.Errorf("expected to jump to turn 1, got %d", v.Index)
+	}
+	if v.JumpTo(50) || v.Index != 2 {
+		t.Errorf("expected a missing turn to go to the closest, got %d", v.Index)
+	}
+	if v.Do(ActionQuit) {
+		t.Errorf("expected quit to stop the viewer")
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	// Act
+	actions := ParseKeys([]byte("l\x1b[Dq \x1b[F"))
+
+	// Assert
+	expected := []Action{ActionForward, ActionBack, ActionQuit, ActionTogglePlay, ActionLast}
+	if len(actions) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, actions)
+	}
+	for i := range expected {
+		if actions[i] != expected[i] {
+			t.Errorf("action %d: expected %v, got %v", i, expected[i], actions[i])
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package replay
+
+// Action is something the person watching a replay asked for.
+type Action int
+
+const (
+	ActionNone Action = iota
+	ActionForward
+	ActionBack
+	ActionFirst
+	ActionLast
+	ActionTogglePlay
+	ActionJump
+	ActionQuit
+)
+
+// Help lists the keys ParseKeys understands.
+const Help = "→/l/n next  ←/h/p previous  space play/pause  g jump to turn  0/home first  $/end last  q quit"
+
+// Viewer is the position and play state of a replay. It knows nothing about terminals so it can be tested directly.
+type Viewer struct {
+	Game    *Game
+	Index   int
+	Playing bool
+}
+
+// Do applies an action and reports whether the viewer should keep running.
+func (v *Viewer) Do(action Action) bool {
+	switch action {
+	case ActionForward:
+		v.step(1)
+	case ActionBack:
+		v.Playing = false
+		v.step(-1)
+	case ActionFirst:
+		v.Index = 0
+	case ActionLast:
+		v.Playing = false
+		v.Index = len(v.Game.Turns) - 1
+	case ActionTogglePlay:
+		v.Playing = !v.Playing
+		if v.Playing && v.AtEnd() {
+			v.Index = 0
+		}
+	case ActionQuit:
+		return false
+	}
+	return true
+}
+
+// Tick advances one turn while playing and stops at the end of the game.
+func (v *Viewer) Tick() {
+	if !v.Playing {
+		return
+	}
+	v.step(1)
+	if v.AtEnd() {
+		v.Playing = false
+	}
+}
+
+// JumpTo moves to the given turn number, or the closest turn to it in the game. It reports whether the exact
+// turn was found.
+func (v *Viewer) JumpTo(turn int) bool {
+	best := 0
+	for i, t := range v.Game.Turns {
+		if t.Turn == turn {
+			v.Index = i
+			return true
+		}
+		if abs(t.Turn-turn) < abs(v.Game.Turns[best].Turn-turn) {
+			best = i
+		}
+	}
+	if len(v.Game.Turns) > 0 {
+		v.Index = best
+	}
+	return false
+}
+
+// AtEnd reports whether the viewer is on the final turn.
+func (v *Viewer) AtEnd() bool {
+	return v.Index >= len(v.Game.Turns)-1
+}
+
+func (v *Viewer) step(delta int) {
+	v.Index += delta
+	if v.Index < 0 {
+		v.Index = 0
+	}
+	if v.Index > len(v.Game.Turns)-1 {
+		v.Index = len(v.Game.Turns) - 1
+	}
+}
+
+// ParseKeys turns raw terminal input into actions, including the escape sequences sent by arrow, home and end keys.
+func ParseKeys(input []byte) []Action {
+	var actions []Action
+	for i := 0; i < len(input); i++ {
+		if input[i] == 0x1b && i+2 < len(input) && (input[i+1] == '[' || input[i+1] == 'O') {
+			switch input[i+2] {
+			case 'C':
+				actions = append(actions, ActionForward)
+			case 'D':
+				actions = append(actions, ActionBack)
+			case 'H':
+				actions = append(actions, ActionFirst)
+			case 'F':
+				actions = append(actions, ActionLast)
+			}
+			i += 2
+			continue
+		}
+		switch input[i] {
+		case 'l', 'n', '.':
+			actions = append(actions, ActionForward)
+		case 'h', 'p', ',':
+			actions = append(actions, ActionBack)
+		case '0':
+			actions = append(actions, ActionFirst)
+		case '$', 'G':
+			actions = append(actions, ActionLast)
+		case ' ':
+			actions = append(actions, ActionTogglePlay)
+		case 'g', ':':
+			actions = append(actions, ActionJump)
+		case 'q', 3:
+			actions = append(actions, ActionQuit)
+		}
+	}
+	return actions
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,713 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// Ruleset names understood by the engine.
+const (
+	RulesetStandard    = "standard"
+	RulesetWrapped     = "wrapped"
+	RulesetRoyale      = "royale"
+	RulesetSolo        = "solo"
+	RulesetConstrictor = "constrictor"
+	RulesetSquad       = "squad"
+)
+
+// Maps understood by the engine. Anything else is rejected by NewEngine rather than silently played as standard.
+const (
+	MapStandard         = "standard"
+	MapEmpty            = "empty"
+	MapHzIslandsBridges = "hz_islands_bridges"
+)
+
+// Elimination causes. These use the same strings as the official engine so recordings stay comparable.
+const (
+	EliminatedByCollision     = "snake-collision"
+	EliminatedBySelfCollision = "snake-self-collision"
+	EliminatedByOutOfHealth   = "out-of-health"
+	EliminatedByHeadToHead    = "head-collision"
+	EliminatedByOutOfBounds   = "wall-collision"
+	EliminatedBySquad         = "squad-eliminated"
+)
+
+const (
+	snakeMaxHealth   = 100
+	snakeStartLength = 3
+)
+
+// Elimination records when and why a snake left the game.
+type Elimination struct {
+	ID    string `json:"id"`
+	Cause string `json:"cause"`
+	Turn  int    `json:"turn"`
+	By    string `json:"by,omitempty"`
+}
+
+// Engine holds the full state of one game and advances it a turn at a time.
+// Board only ever contains the snakes that are still alive, the same as the official API.
+type Engine struct {
+	Game       Game
+	Turn       int
+	Board      Board
+	Eliminated []Elimination
+
+	// rand is made from seed the first time it is needed, so clones that never use it cost nothing to seed.
+	// clones counts the copies Clone has made, which gives each its own seed.
+	seed      int64
+	rand      *rand.Rand
+	clones    int64
+	lastMoves map[string]string
+}
+
+// DefaultSettings returns the settings the official engine uses when none are given.
+func DefaultSettings() Settings {
+	return Settings{
+		FoodSpawnChance:     15,
+		MinimumFood:         1,
+		HazardDamagePerTurn: 14,
+		Royale:              Royale{ShrinkEveryNTurns: 25},
+		Squad:               Squad{AllowBodyCollisions: true, SharedElimination: true, SharedHealth: true, SharedLength: true},
+	}
+}
+
+// NewEngine creates a game on a width x height board with the given snakes placed at their starting positions,
+// and the map's hazards if it has any.
+// Only the ID, Name and Squad of each snake are used. seed controls snake placement, food spawns and royale
+// hazards so two engines with the same arguments play out identically for identical moves.
+func NewEngine(game Game, width, height int, snakes []Battlesnake, seed int64) (*Engine, error) {
+	if width < 3 || height < 3 {
+		return nil, fmt.Errorf("board %dx%d is too small", width, height)
+	}
+	if len(snakes) == 0 {
+		return nil, errors.New("a game needs at least one snake")
+	}
+	if err := checkGame(&game); err != nil {
+		return nil, err
+	}
+	if err := checkSquads(game, snakes); err != nil {
+		return nil, err
+	}
+	hazards, err := mapHazards(game.Map, width, height)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Engine{
+		Game:      game,
+		Board:     Board{Width: width, Height: height, Food: []Coord{}, Hazards: hazards},
+		seed:      seed,
+		lastMoves: make(map[string]string),
+	}
+	starts, err := e.startPositions(len(snakes))
+	if err != nil {
+		return nil, err
+	}
+	for i, snake := range snakes {
+		body := make([]Coord, snakeStartLength)
+		for j := range body {
+			body[j] = starts[i]
+		}
+		e.Board.Snakes = append(e.Board.Snakes, Battlesnake{
+			ID:      snake.ID,
+			Name:    snake.Name,
+			Squad:   snake.Squad,
+			Health:  snakeMaxHealth,
+			Body:    body,
+			Head:    body[0],
+			Length:  int32(len(body)),
+			Latency: "0",
+		})
+	}
+	// Hazard maps start with the standard food, the centre food included even when it is on a hazard.
+	if game.Map != MapEmpty && game.Ruleset.Name != RulesetConstrictor {
+		e.placeStartingFood()
+	}
+	return e, nil
+}
+
+// ResumeEngine creates an engine that carries on from a board part way through a game, for example one read
+// from a transcript. The board is copied. seed controls food spawns and royale hazards from this turn on.
+func ResumeEngine(game Game, turn int, board Board, seed int64) (*Engine, error) {
+	if err := checkGame(&game); err != nil {
+		return nil, err
+	}
+	if err := checkSquads(game, board.Snakes); err != nil {
+		return nil, err
+	}
+	e := &Engine{
+		Game:      game,
+		Turn:      turn,
+		Board:     board.Clone(),
+		seed:      seed,
+		lastMoves: make(map[string]string),
+	}
+	for i := range e.Board.Snakes {
+		snake := &e.Board.Snakes[i]
+		if len(snake.Body) == 0 {
+			return nil, fmt.Errorf("snake %s has no body", snake.ID)
+		}
+		snake.Head = snake.Body[0]
+		snake.Length = int32(len(snake.Body))
+	}
+	return e, nil
+}
+
+// Clone returns an independent copy of the engine, for trying out moves without changing the real game. The
+// copy gets its own seed, worked out from the original's seed and how many clones came before it without
+// touching the original's random source, so the nth clone of the same engine always plays out the same way.
+func (e *Engine) Clone() *Engine {
+	e.clones++
+	clone := &Engine{
+		Game:       e.Game,
+		Turn:       e.Turn,
+		Board:      e.Board.Clone(),
+		Eliminated: append([]Elimination{}, e.Eliminated...),
+		seed:       cloneSeed(e.seed, e.clones),
+		lastMoves:  make(map[string]string, len(e.lastMoves)),
+	}
+	for id, move := range e.lastMoves {
+		clone.lastMoves[id] = move
+	}
+	return clone
+}
+
+// cloneSeed mixes a seed with a clone's number (splitmix64), so neighbouring seeds and numbers give unrelated
+// seeds.
+func cloneSeed(seed, n int64) int64 {
+	z := uint64(seed) + uint64(n)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
+
+func (e *Engine) random() *rand.Rand {
+	if e.rand == nil {
+		e.rand = rand.New(rand.NewSource(e.seed))
+	}
+	return e.rand
+}
+
+func checkGame(game *Game) error {
+	switch game.Ruleset.Name {
+	case RulesetStandard, RulesetWrapped, RulesetRoyale, RulesetSolo, RulesetConstrictor, RulesetSquad:
+	default:
+		return fmt.Errorf("unsupported ruleset %q", game.Ruleset.Name)
+	}
+	switch game.Map {
+	case "":
+		game.Map = MapStandard
+	case MapStandard, MapEmpty, MapHzIslandsBridges:
+	default:
+		return fmt.Errorf("unsupported map %q", game.Map)
+	}
+	return nil
+}
+
+// checkSquads makes sure every snake in a squad game is in a squad, since a snake without one would have nobody
+// to win with.
+func checkSquads(game Game, snakes []Battlesnake) error {
+	if game.Ruleset.Name != RulesetSquad {
+		return nil
+	}
+	for _, snake := range snakes {
+		if snake.Squad == "" {
+			return fmt.Errorf("snake %s has no squad, every snake in a squad game needs one", snake.ID)
+		}
+	}
+	return nil
+}
+
+// startPositions picks a start cell for each snake. On the usual 7x7, 11x11 and 19x19 boards the official fixed
+// positions are used, otherwise snakes are spread over random cells of the same parity.
+func (e *Engine) startPositions(n int) ([]Coord, error) {
+	w, h := e.Board.Width, e.Board.Height
+	if w == h && (w == 7 || w == 11 || w == 19) && n <= 8 {
+		mn, md, mx := 1, (w-1)/2, w-2
+		corners := []Coord{{mn, mn}, {mn, mx}, {mx, mn}, {mx, mx}}
+		cardinals := []Coord{{mn, md}, {md, mn}, {md, mx}, {mx, md}}
+		e.random().Shuffle(len(corners), func(i, j int) { corners[i], corners[j] = corners[j], corners[i] })
+		e.random().Shuffle(len(cardinals), func(i, j int) { cardinals[i], cardinals[j] = cardinals[j], cardinals[i] })
+		return append(corners, cardinals...)[:n], nil
+	}
+
+	var even []Coord
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			if (x+y)%2 == 0 {
+				even = append(even, Coord{x, y})
+			}
+		}
+	}
+	if n > len(even) {
+		return nil, fmt.Errorf("not enough room for %d snakes on a %dx%d board", n, w, h)
+	}
+	e.random().Shuffle(len(even), func(i, j int) { even[i], even[j] = even[j], even[i] })
+	return even[:n], nil
+}
+
+// placeStartingFood puts one food diagonally next to each snake, away from the centre, and one in the centre.
+func (e *Engine) placeStartingFood() {
+	centre := Coord{(e.Board.Width - 1) / 2, (e.Board.Height - 1) / 2}
+	occupied := e.occupied()
+	for _, snake := range e.Board.Snakes {
+		head := snake.Head
+		options := []Coord{}
+		for _, c := range []Coord{{head.X - 1, head.Y - 1}, {head.X - 1, head.Y + 1}, {head.X + 1, head.Y - 1}, {head.X + 1, head.Y + 1}} {
+			if !e.inBounds(c) || occupied[c] || c == centre {
+				continue
+			}
+			// Food should not be closer to the centre than the snake is.
+			if manhattan(c, centre) < manhattan(head, centre) {
+				continue
+			}
+			options = append(options, c)
+		}
+		if len(options) == 0 {
+			continue
+		}
+		food := options[e.random().Intn(len(options))]
+		e.Board.Food = append(e.Board.Food, food)
+		occupied[food] = true
+	}
+	if !occupied[centre] {
+		e.Board.Food = append(e.Board.Food, centre)
+	}
+}
+
+// StateFor returns the request body for the given snake on the current turn.
+func (e *Engine) StateFor(snakeID string) (GameState, bool) {
+	return StateFor(e.Game, e.Turn, e.Board, snakeID)
+}
+
+// SetLatency records the response time of a snake, which the API reports back to it on the next request.
+func (e *Engine) SetLatency(snakeID, latency string) {
+	for i := range e.Board.Snakes {
+		if e.Board.Snakes[i].ID == snakeID {
+			e.Board.Snakes[i].Latency = latency
+		}
+	}
+}
+
+// IsOver reports whether the game has finished. Solo games run until the last snake dies, squad games until
+// only one squad is left, and everything else until at most one snake is left.
+func (e *Engine) IsOver() bool {
+	switch e.Game.Ruleset.Name {
+	case RulesetSolo:
+		return len(e.Board.Snakes) == 0
+	case RulesetSquad:
+		for _, snake := range e.Board.Snakes {
+			if snake.Squad != e.Board.Snakes[0].Squad {
+				return false
+			}
+		}
+		return true
+	}
+	return len(e.Board.Snakes) <= 1
+}
+
+// Winner returns the snake that won the game, or in squad games the first snake left in the winning squad. The
+// second return value is false for a draw or an unfinished game.
+func (e *Engine) Winner() (Battlesnake, bool) {
+	if !e.IsOver() || len(e.Board.Snakes) == 0 {
+		return Battlesnake{}, false
+	}
+	if len(e.Board.Snakes) > 1 && e.Game.Ruleset.Name != RulesetSquad {
+		return Battlesnake{}, false
+	}
+	return e.Board.Snakes[0], true
+}
+
+// Step applies one move per snake and advances the game by one turn. Snakes without a valid move carry on in
+// the direction they last moved. It returns the snakes eliminated this turn. In constrictor every snake that is
+// left grows, and there is never any food. In squad games teammates share what the squad settings say they do.
+func (e *Engine) Step(moves map[string]string) []Elimination {
+	e.moveSnakes(moves)
+	e.reduceHealth()
+	e.damageHazards()
+	e.feedSnakes()
+	e.spawnFood()
+	moved := e.Board.Snakes
+	eliminated := e.eliminateSnakes()
+	if e.Game.Ruleset.Name == RulesetSquad {
+		eliminated = append(eliminated, e.shareSquads(moved, eliminated)...)
+	}
+	if e.Game.Ruleset.Name == RulesetConstrictor {
+		e.constrict()
+	}
+	e.Turn++
+	if e.Game.Ruleset.Name == RulesetRoyale {
+		e.shrinkRoyale()
+	}
+	return eliminated
+}
+
+func (e *Engine) moveSnakes(moves map[string]string) {
+	for i := range e.Board.Snakes {
+		snake := &e.Board.Snakes[i]
+		move := moves[snake.ID]
+		if !IsValidMove(move) {
+			move = e.defaultMove(*snake)
+		}
+		e.lastMoves[snake.ID] = move
+
+		head := e.wrap(snake.Head.Neighbour(move))
+		snake.Body = append([]Coord{head}, snake.Body[:len(snake.Body)-1]...)
+		snake.Head = head
+	}
+}
+
+// defaultMove is the move the official engine makes for a snake that timed out or sent garbage: the last move
+// it made, otherwise the direction its neck points away from, otherwise up.
+func (e *Engine) defaultMove(snake Battlesnake) string {
+	if move, ok := e.lastMoves[snake.ID]; ok {
+		return move
+	}
+	if len(snake.Body) > 1 && snake.Body[0] != snake.Body[1] {
+		for _, move := range Moves {
+			if e.wrap(snake.Body[1].Neighbour(move)) == snake.Body[0] {
+				return move
+			}
+		}
+	}
+	return MoveUp
+}
+
+func (e *Engine) reduceHealth() {
+	for i := range e.Board.Snakes {
+		e.Board.Snakes[i].Health--
+	}
+}
+
+// damageHazards applies hazard damage to every snake whose head is on a hazard. The same cell can appear in
+// Board.Hazards more than once, in which case the damage stacks. Eating food on a hazard cancels the damage.
+func (e *Engine) damageHazards() {
+	damage := e.Game.Ruleset.Settings.HazardDamagePerTurn
+	if damage <= 0 {
+		return
+	}
+	for i := range e.Board.Snakes {
+		snake := &e.Board.Snakes[i]
+		if containsCoord(e.Board.Food, snake.Head) {
+			continue
+		}
+		for _, hazard := range e.Board.Hazards {
+			if hazard == snake.Head {
+				snake.Health -= damage
+			}
+		}
+		if snake.Health < 0 {
+			snake.Health = 0
+		}
+	}
+}
+
+func (e *Engine) feedSnakes() {
+	eaten := make(map[Coord]bool)
+	for i := range e.Board.Snakes {
+		snake := &e.Board.Snakes[i]
+		if !containsCoord(e.Board.Food, snake.Head) {
+			continue
+		}
+		eaten[snake.Head] = true
+		snake.Health = snakeMaxHealth
+		snake.Body = append(snake.Body, snake.Body[len(snake.Body)-1])
+	}
+	remaining := []Coord{}
+	for _, food := range e.Board.Food {
+		if !eaten[food] {
+			remaining = append(remaining, food)
+		}
+	}
+	e.Board.Food = remaining
+	for i := range e.Board.Snakes {
+		e.Board.Snakes[i].Length = int32(len(e.Board.Snakes[i].Body))
+	}
+}
+
+func (e *Engine) spawnFood() {
+	settings := e.Game.Ruleset.Settings
+	if e.Game.Map == MapEmpty || e.Game.Ruleset.Name == RulesetConstrictor {
+		return
+	}
+	spawn := 0
+	if len(e.Board.Food) < int(settings.MinimumFood) {
+		spawn = int(settings.MinimumFood) - len(e.Board.Food)
+	} else if settings.FoodSpawnChance > 0 && e.random().Intn(100) < int(settings.FoodSpawnChance) {
+		spawn = 1
+	}
+	if spawn == 0 {
+		return
+	}
+	free := e.freeCells()
+	e.random().Shuffle(len(free), func(i, j int) { free[i], free[j] = free[j], free[i] })
+	if spawn > len(free) {
+		spawn = len(free)
+	}
+	e.Board.Food = append(e.Board.Food, free[:spawn]...)
+}
+
+// constrict grows every snake by one and keeps it at full health, so tails never move and nobody starves, and
+// removes any food from the board.
+func (e *Engine) constrict() {
+	for i := range e.Board.Snakes {
+		snake := &e.Board.Snakes[i]
+		snake.Health = snakeMaxHealth
+		snake.Body = append(snake.Body, snake.Body[len(snake.Body)-1])
+		snake.Length = int32(len(snake.Body))
+	}
+	e.Board.Food = []Coord{}
+}
+
+// eliminateSnakes removes dead snakes from the board. Starvation and walls are checked first; collisions are
+// then checked against every snake that survived those, so two snakes can kill each other on the same turn.
+func (e *Engine) eliminateSnakes() []Elimination {
+	var eliminated []Elimination
+	dead := make(map[string]bool)
+	for _, snake := range e.Board.Snakes {
+		if snake.Health <= 0 {
+			eliminated = append(eliminated, Elimination{ID: snake.ID, Cause: EliminatedByOutOfHealth, Turn: e.Turn + 1})
+			dead[snake.ID] = true
+			continue
+		}
+		if !e.inBounds(snake.Head) {
+			eliminated = append(eliminated, Elimination{ID: snake.ID, Cause: EliminatedByOutOfBounds, Turn: e.Turn + 1})
+			dead[snake.ID] = true
+		}
+	}
+
+	var collisions []Elimination
+	for _, snake := range e.Board.Snakes {
+		if dead[snake.ID] {
+			continue
+		}
+		if containsCoord(snake.Body[1:], snake.Head) {
+			collisions = append(collisions, Elimination{ID: snake.ID, Cause: EliminatedBySelfCollision, Turn: e.Turn + 1, By: snake.ID})
+			continue
+		}
+		if by, ok := e.bodyCollision(snake, dead); ok {
+			collisions = append(collisions, Elimination{ID: snake.ID, Cause: EliminatedByCollision, Turn: e.Turn + 1, By: by})
+			continue
+		}
+		if by, ok := e.headCollision(snake, dead); ok {
+			collisions = append(collisions, Elimination{ID: snake.ID, Cause: EliminatedByHeadToHead, Turn: e.Turn + 1, By: by})
+		}
+	}
+	for _, elimination := range collisions {
+		dead[elimination.ID] = true
+	}
+	eliminated = append(eliminated, collisions...)
+
+	alive := []Battlesnake{}
+	for _, snake := range e.Board.Snakes {
+		if !dead[snake.ID] {
+			alive = append(alive, snake)
+		}
+	}
+	e.Board.Snakes = alive
+	e.Eliminated = append(e.Eliminated, eliminated...)
+	return eliminated
+}
+
+func (e *Engine) bodyCollision(snake Battlesnake, dead map[string]bool) (string, bool) {
+	for _, other := range e.Board.Snakes {
+		if other.ID == snake.ID || dead[other.ID] || e.passesThrough(snake, other) {
+			continue
+		}
+		if containsCoord(other.Body[1:], snake.Head) {
+			return other.ID, true
+		}
+	}
+	return "", false
+}
+
+// passesThrough reports whether snake can move through other's body, which squad games allow between teammates.
+func (e *Engine) passesThrough(snake, other Battlesnake) bool {
+	return e.Game.Ruleset.Name == RulesetSquad && e.Game.Ruleset.Settings.Squad.AllowBodyCollisions && snake.Squad == other.Squad
+}
+
+// shareSquads applies the shared squad settings once this turn's eliminations are done. moved is the board's
+// snakes before those eliminations. Teammates of an eliminated snake are eliminated with it, and every snake left
+// takes the highest health and the longest length in its squad. As in the official rules, teammates eliminated
+// this turn still count for health and length.
+func (e *Engine) shareSquads(moved []Battlesnake, eliminated []Elimination) []Elimination {
+	settings := e.Game.Ruleset.Settings.Squad
+	var squadEliminated []Elimination
+	if settings.SharedElimination && len(eliminated) > 0 {
+		dead := make(map[string]bool)
+		for _, elimination := range eliminated {
+			dead[elimination.ID] = true
+		}
+		lost := make(map[string]bool)
+		for _, snake := range moved {
+			if dead[snake.ID] {
+				lost[snake.Squad] = true
+			}
+		}
+		alive := []Battlesnake{}
+		for _, snake := range e.Board.Snakes {
+			if lost[snake.Squad] {
+				squadEliminated = append(squadEliminated, Elimination{ID: snake.ID, Cause: EliminatedBySquad, Turn: e.Turn + 1})
+				continue
+			}
+			alive = append(alive, snake)
+		}
+		e.Board.Snakes = alive
+		e.Eliminated = append(e.Eliminated, squadEliminated...)
+	}
+
+	for i := range e.Board.Snakes {
+		snake := &e.Board.Snakes[i]
+		for _, teammate := range moved {
+			if teammate.Squad != snake.Squad {
+				continue
+			}
+			if settings.SharedHealth && teammate.Health > snake.Health {
+				snake.Health = teammate.Health
+			}
+			for settings.SharedLength && len(snake.Body) < len(teammate.Body) {
+				snake.Body = append(snake.Body, snake.Body[len(snake.Body)-1])
+			}
+		}
+		snake.Length = int32(len(snake.Body))
+	}
+	return squadEliminated
+}
+
+// headCollision reports a head-to-head loss. Equal lengths eliminate both snakes.
+func (e *Engine) headCollision(snake Battlesnake, dead map[string]bool) (string, bool) {
+	for _, other := range e.Board.Snakes {
+		if other.ID == snake.ID || dead[other.ID] {
+			continue
+		}
+		if other.Head == snake.Head && len(snake.Body) <= len(other.Body) {
+			return other.ID, true
+		}
+	}
+	return "", false
+}
+
+// shrinkRoyale turns one more row or column of the safe area into hazard every ShrinkEveryNTurns turns.
+func (e *Engine) shrinkRoyale() {
+	every := int(e.Game.Ruleset.Settings.Royale.ShrinkEveryNTurns)
+	if every <= 0 || e.Turn%every != 0 {
+		return
+	}
+	hazards := make(map[Coord]bool)
+	for _, hazard := range e.Board.Hazards {
+		hazards[hazard] = true
+	}
+	minX, maxX, minY, maxY := e.Board.Width, -1, e.Board.Height, -1
+	for x := 0; x < e.Board.Width; x++ {
+		for y := 0; y < e.Board.Height; y++ {
+			if hazards[Coord{x, y}] {
+				continue
+			}
+			if x < minX {
+				minX = x
+			}
+			if x > maxX {
+				maxX = x
+			}
+			if y < minY {
+				minY = y
+			}
+			if y > maxY {
+				maxY = y
+			}
+		}
+	}
+	if maxX < minX || maxY < minY {
+		return
+	}
+
+	var side []Coord
+	switch e.random().Intn(4) {
+	case 0:
+		for y := minY; y <= maxY; y++ {
+			side = append(side, Coord{minX, y})
+		}
+	case 1:
+		for y := minY; y <= maxY; y++ {
+			side = append(side, Coord{maxX, y})
+		}
+	case 2:
+		for x := minX; x <= maxX; x++ {
+			side = append(side, Coord{x, minY})
+		}
+	default:
+		for x := minX; x <= maxX; x++ {
+			side = append(side, Coord{x, maxY})
+		}
+	}
+	for _, c := range side {
+		if !hazards[c] {
+			e.Board.Hazards = append(e.Board.Hazards, c)
+		}
+	}
+}
+
+func (e *Engine) wrap(c Coord) Coord {
+	if e.Game.Ruleset.Name != RulesetWrapped {
+		return c
+	}
+	c.X = (c.X + e.Board.Width) % e.Board.Width
+	c.Y = (c.Y + e.Board.Height) % e.Board.Height
+	return c
+}
+
+func (e *Engine) inBounds(c Coord) bool {
+	return c.X >= 0 && c.Y >= 0 && c.X < e.Board.Width && c.Y < e.Board.Height
+}
+
+func (e *Engine) occupied() map[Coord]bool {
+	occupied := make(map[Coord]bool)
+	for _, snake := range e.Board.Snakes {
+		for _, part := range snake.Body {
+			occupied[part] = true
+		}
+	}
+	for _, food := range e.Board.Food {
+		occupied[food] = true
+	}
+	return occupied
+}
+
+// freeCells returns every cell without a snake, food or hazard on it, in a stable order.
+func (e *Engine) freeCells() []Coord {
+	occupied := e.occupied()
+	for _, hazard := range e.Board.Hazards {
+		occupied[hazard] = true
+	}
+	var free []Coord
+	for x := 0; x < e.Board.Width; x++ {
+		for y := 0; y < e.Board.Height; y++ {
+			if !occupied[Coord{x, y}] {
+				free = append(free, Coord{x, y})
+			}
+		}
+	}
+	return free
+}
+
+func containsCoord(coords []Coord, coord Coord) bool {
+	for _, c := range coords {
+		if c == coord {
+			return true
+		}
+	}
+	return false
+}
+
+func manhattan(a, b Coord) int {
+	return abs(a.X-b.X) + abs(a.Y-b.Y)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
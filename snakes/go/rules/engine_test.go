@@ -0,0 +1,352 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+)
+
+// newTestEngine builds an engine with the given snakes already placed, bypassing the random start positions.
+func newTestEngine(t *testing.T, ruleset string, snakes ...Battlesnake) *Engine {
+	t.Helper()
+	game := Game{ID: "test", Ruleset: Ruleset{Name: ruleset, Settings: Settings{HazardDamagePerTurn: 14}}, Map: MapEmpty}
+	e, err := NewEngine(game, 11, 11, snakes, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range snakes {
+		snakes[i].Head = snakes[i].Body[0]
+		snakes[i].Length = int32(len(snakes[i].Body))
+		if snakes[i].Health == 0 {
+			snakes[i].Health = 100
+		}
+	}
+	e.Board.Snakes = snakes
+	return e
+}
+
+func TestEngineMovesAndStarves(t *testing.T) {
+	// Arrange
+	me := Battlesnake{ID: "me", Health: 1, Body: []Coord{{5, 5}, {5, 4}, {5, 3}}}
+	e := newTestEngine(t, RulesetSolo, me)
+
+	// Act
+	eliminated := e.Step(map[string]string{"me": MoveUp})
+
+	// Assert
+	if len(eliminated) != 1 || eliminated[0].Cause != EliminatedByOutOfHealth {
+		t.Fatalf("expected snake to starve, got %v", eliminated)
+	}
+	if !e.IsOver() {
+		t.Errorf("solo game should be over once the last snake is gone")
+	}
+}
+
+func TestEngineFeeds(t *testing.T) {
+	// Arrange
+	me := Battlesnake{ID: "me", Health: 50, Body: []Coord{{5, 5}, {5, 4}, {5, 3}}}
+	e := newTestEngine(t, RulesetSolo, me)
+	e.Board.Food = []Coord{{5, 6}}
+
+	// Act
+	e.Step(map[string]string{"me": MoveUp})
+
+	// Assert
+	snake := e.Board.Snakes[0]
+	if snake.Health != 100 || snake.Length != 4 || len(e.Board.Food) != 0 {
+		t.Errorf("snake did not eat: health %d length %d food %v", snake.Health, snake.Length, e.Board.Food)
+	}
+	if snake.Body[2] != snake.Body[3] {
+		t.Errorf("expected tail to be stacked after eating, got %v", snake.Body)
+	}
+}
+
+func TestEngineInvalidMoveContinuesStraight(t *testing.T) {
+	// Arrange
+	me := Battlesnake{ID: "me", Body: []Coord{{5, 5}, {4, 5}, {3, 5}}}
+	e := newTestEngine(t, RulesetSolo, me)
+
+	// Act
+	e.Step(map[string]string{"me": "sideways"})
+
+	// Assert
+	if e.Board.Snakes[0].Head != (Coord{6, 5}) {
+		t.Errorf("expected snake to keep moving right, head at %v", e.Board.Snakes[0].Head)
+	}
+}
+
+func TestEngineWalls(t *testing.T) {
+	// Arrange
+	me := Battlesnake{ID: "me", Body: []Coord{{0, 5}, {1, 5}, {2, 5}}}
+	other := Battlesnake{ID: "other", Body: []Coord{{5, 0}, {6, 0}, {7, 0}}}
+	e := newTestEngine(t, RulesetStandard, me, other)
+
+	// Act
+	e.Step(map[string]string{"me": MoveLeft, "other": MoveUp})
+
+	// Assert
+	winner, ok := e.Winner()
+	if !ok || winner.ID != "other" {
+		t.Errorf("expected other to win after we hit the wall, got %v %v", winner.ID, ok)
+	}
+}
+
+func TestEngineWrapped(t *testing.T) {
+	// Arrange
+	me := Battlesnake{ID: "me", Body: []Coord{{0, 5}, {1, 5}, {2, 5}}}
+	e := newTestEngine(t, RulesetWrapped, me)
+
+	// Act
+	e.Step(map[string]string{"me": MoveLeft})
+
+	// Assert
+	if len(e.Board.Snakes) != 1 || e.Board.Snakes[0].Head != (Coord{10, 5}) {
+		t.Errorf("expected snake to wrap to the right edge, got %v", e.Board.Snakes)
+	}
+}
+
+func TestEngineHeadToHead(t *testing.T) {
+	// Arrange
+	me := Battlesnake{ID: "me", Body: []Coord{{4, 5}, {3, 5}, {2, 5}, {1, 5}}}
+	other := Battlesnake{ID: "other", Body: []Coord{{6, 5}, {7, 5}, {8, 5}}}
+	e := newTestEngine(t, RulesetStandard, me, other)
+
+	// Act
+	eliminated := e.Step(map[string]string{"me": MoveRight, "other": MoveLeft})
+
+	// Assert
+	if len(eliminated) != 1 || eliminated[0].ID != "other" || eliminated[0].Cause != EliminatedByHeadToHead {
+		t.Errorf("expected the shorter snake to lose the head-to-head, got %v", eliminated)
+	}
+}
+
+func TestEngineHeadToHeadDraw(t *testing.T) {
+	// Arrange
+	me := Battlesnake{ID: "me", Body: []Coord{{4, 5}, {3, 5}, {2, 5}}}
+	other := Battlesnake{ID: "other", Body: []Coord{{6, 5}, {7, 5}, {8, 5}}}
+	e := newTestEngine(t, RulesetStandard, me, other)
+
+	// Act
+	e.Step(map[string]string{"me": MoveRight, "other": MoveLeft})
+
+	// Assert
+	if _, ok := e.Winner(); ok || !e.IsOver() {
+		t.Errorf("expected a draw when equal snakes collide, snakes left %v", e.Board.Snakes)
+	}
+}
+
+func TestEngineStackedHazards(t *testing.T) {
+	// Arrange
+	me := Battlesnake{ID: "me", Body: []Coord{{5, 5}, {5, 4}, {5, 3}}}
+	e := newTestEngine(t, RulesetSolo, me)
+	e.Board.Hazards = []Coord{{5, 6}, {5, 6}}
+
+	// Act
+	e.Step(map[string]string{"me": MoveUp})
+
+	// Assert
+	if health := e.Board.Snakes[0].Health; health != 100-1-2*14 {
+		t.Errorf("expected stacked hazard damage, health is %d", health)
+	}
+}
+
+func TestEngineStartingPositions(t *testing.T) {
+	// Arrange
+	game := Game{ID: "start", Ruleset: Ruleset{Name: RulesetStandard, Settings: DefaultSettings()}}
+	snakes := []Battlesnake{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}
+
+	// Act
+	first, err := NewEngine(game, 11, 11, snakes, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := NewEngine(game, 11, 11, snakes, 42)
+
+	// Assert
+	if len(first.Board.Food) != 5 {
+		t.Errorf("expected one food per snake plus the centre, got %v", first.Board.Food)
+	}
+	for i := range first.Board.Snakes {
+		if first.Board.Snakes[i].Head != second.Board.Snakes[i].Head {
+			t.Errorf("same seed placed snakes differently")
+		}
+	}
+}
+
+func TestEngineIslandsBridges(t *testing.T) {
+	// Arrange
+	game := Game{ID: "islands", Ruleset: Ruleset{Name: RulesetStandard, Settings: DefaultSettings()}, Map: MapHzIslandsBridges}
+	snakes := []Battlesnake{{ID: "a"}, {ID: "b"}}
+
+	// Act
+	e, err := NewEngine(game, 11, 11, snakes, 42)
+	_, tooBig := NewEngine(game, 19, 19, snakes, 42)
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e.Board.Hazards) != 37 || !containsCoord(e.Board.Hazards, Coord{5, 5}) || containsCoord(e.Board.Hazards, Coord{5, 2}) {
+		t.Errorf("expected the islands and bridges hazards, got %v", e.Board.Hazards)
+	}
+	if len(e.Board.Food) != 3 {
+		t.Errorf("expected the standard starting food, got %v", e.Board.Food)
+	}
+	if tooBig == nil {
+		t.Errorf("expected a 19x19 board to be rejected")
+	}
+}
+
+func TestResumeEngineAndClone(t *testing.T) {
+	// Arrange
+	board := Board{Width: 7, Height: 7, Food: []Coord{}, Hazards: []Coord{}, Snakes: []Battlesnake{
+		{ID: "me", Health: 80, Body: []Coord{{3, 3}, {3, 2}, {3, 1}}},
+	}}
+	game := Game{Ruleset: Ruleset{Name: RulesetSolo}, Map: MapEmpty}
+
+	// Act
+	e, err := ResumeEngine(game, 40, board, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone := e.Clone()
+	clone.Step(map[string]string{"me": MoveLeft})
+	e.Step(map[string]string{})
+
+	// Assert
+	if board.Snakes[0].Body[0] != (Coord{3, 3}) {
+		t.Errorf("resuming changed the board it was given")
+	}
+	if e.Turn != 41 || e.Board.Snakes[0].Head != (Coord{3, 4}) || e.Board.Snakes[0].Health != 79 {
+		t.Errorf("expected the resumed snake to carry on up from turn 40, got turn %d %+v", e.Turn, e.Board.Snakes[0])
+	}
+	if clone.Board.Snakes[0].Head != (Coord{2, 3}) {
+		t.Errorf("expected the clone to move independently, head at %v", clone.Board.Snakes[0].Head)
+	}
+}
+
+func TestCloneSeedsLazily(t *testing.T) {
+	// Arrange
+	board := Board{Width: 7, Height: 7, Food: []Coord{}, Hazards: []Coord{}, Snakes: []Battlesnake{
+		{ID: "me", Health: 80, Body: []Coord{{3, 3}, {3, 2}, {3, 1}}},
+	}}
+	game := Game{Ruleset: Ruleset{Name: RulesetStandard, Settings: Settings{FoodSpawnChance: 100}}, Map: MapStandard}
+	first, _ := ResumeEngine(game, 0, board, 7)
+	second, _ := ResumeEngine(game, 0, board, 7)
+
+	// Act
+	a, b := first.Clone(), first.Clone()
+	c := second.Clone()
+	a.Step(map[string]string{"me": MoveUp})
+	c.Step(map[string]string{"me": MoveUp})
+
+	// Assert
+	if first.rand != nil {
+		t.Errorf("cloning seeded the original's random source")
+	}
+	if a.seed == b.seed {
+		t.Errorf("expected each clone to get its own seed")
+	}
+	if !reflect.DeepEqual(a.Board.Food, c.Board.Food) {
+		t.Errorf("expected the first clones of equal engines to spawn the same food, got %v and %v", a.Board.Food, c.Board.Food)
+	}
+}
+
+func TestEngineConstrictor(t *testing.T) {
+	// Arrange
+	me := Battlesnake{ID: "me", Health: 50, Body: []Coord{{5, 5}, {5, 4}, {5, 3}}}
+	e := newTestEngine(t, RulesetConstrictor, me)
+	e.Board.Food = []Coord{{0, 0}}
+	e.Game.Ruleset.Settings.MinimumFood = 1
+
+	// Act
+	e.Step(map[string]string{"me": MoveUp})
+	e.Step(map[string]string{"me": MoveUp})
+
+	// Assert
+	snake := e.Board.Snakes[0]
+	if snake.Health != 100 || snake.Length != 5 {
+		t.Errorf("expected the snake to grow every turn at full health: health %d length %d", snake.Health, snake.Length)
+	}
+	// The tail only moves on the first turn, before the snake has grown.
+	if snake.Body[3] != (Coord{5, 4}) || snake.Body[4] != (Coord{5, 4}) {
+		t.Errorf("expected the tail to stay where it was after the first turn, got %v", snake.Body)
+	}
+	if len(e.Board.Food) != 0 {
+		t.Errorf("expected no food in constrictor, got %v", e.Board.Food)
+	}
+}
+
+// newSquadEngine builds a squad game with the official squad settings.
+func newSquadEngine(t *testing.T, snakes ...Battlesnake) *Engine {
+	t.Helper()
+	e := newTestEngine(t, RulesetSquad, snakes...)
+	e.Game.Ruleset.Settings.Squad = DefaultSettings().Squad
+	return e
+}
+
+func TestEngineSquadSharesHealthLengthAndBodies(t *testing.T) {
+	// Arrange
+	a1 := Battlesnake{ID: "a1", Squad: "a", Health: 50, Body: []Coord{{5, 5}, {5, 4}, {5, 3}}}
+	a2 := Battlesnake{ID: "a2", Squad: "a", Health: 30, Body: []Coord{{4, 4}, {3, 4}, {2, 4}}}
+	b := Battlesnake{ID: "b", Squad: "b", Health: 20, Body: []Coord{{9, 5}, {9, 4}, {9, 3}}}
+	e := newSquadEngine(t, a1, a2, b)
+	e.Board.Food = []Coord{{5, 6}}
+
+	// Act: a1 eats and a2 moves onto a1's body.
+	eliminated := e.Step(map[string]string{"a1": MoveUp, "a2": MoveRight, "b": MoveUp})
+
+	// Assert
+	if len(eliminated) != 0 {
+		t.Fatalf("expected a2 to pass through its teammate, got %v", eliminated)
+	}
+	for _, snake := range e.Board.Snakes[:2] {
+		if snake.Health != 100 || snake.Length != 4 || len(snake.Body) != 4 {
+			t.Errorf("expected %s to share a1's health and length, got health %d length %d", snake.ID, snake.Health, snake.Length)
+		}
+	}
+	if other := e.Board.Snakes[2]; other.Health != 19 || other.Length != 3 {
+		t.Errorf("expected the other squad not to share, got health %d length %d", other.Health, other.Length)
+	}
+}
+
+func TestEngineSquadBodyCollisionsOff(t *testing.T) {
+	// Arrange
+	a1 := Battlesnake{ID: "a1", Squad: "a", Body: []Coord{{5, 5}, {5, 4}, {5, 3}}}
+	a2 := Battlesnake{ID: "a2", Squad: "a", Body: []Coord{{4, 4}, {3, 4}, {2, 4}}}
+	e := newSquadEngine(t, a1, a2)
+	e.Game.Ruleset.Settings.Squad = Squad{}
+
+	// Act
+	eliminated := e.Step(map[string]string{"a1": MoveUp, "a2": MoveRight})
+
+	// Assert
+	if len(eliminated) != 1 || eliminated[0].ID != "a2" || eliminated[0].Cause != EliminatedByCollision {
+		t.Errorf("expected a2 to die on its teammate's body without the squad settings, got %v", eliminated)
+	}
+}
+
+func TestEngineSquadSharedElimination(t *testing.T) {
+	// Arrange
+	a1 := Battlesnake{ID: "a1", Squad: "a", Body: []Coord{{0, 5}, {1, 5}, {2, 5}}}
+	a2 := Battlesnake{ID: "a2", Squad: "a", Body: []Coord{{5, 5}, {5, 4}, {5, 3}}}
+	b1 := Battlesnake{ID: "b1", Squad: "b", Body: []Coord{{8, 5}, {8, 4}, {8, 3}}}
+	b2 := Battlesnake{ID: "b2", Squad: "b", Body: []Coord{{9, 5}, {9, 4}, {9, 3}}}
+	e := newSquadEngine(t, a1, a2, b1, b2)
+
+	// Act
+	moves := map[string]string{"a1": MoveLeft, "a2": MoveUp, "b1": MoveUp, "b2": MoveUp}
+	e.Step(moves)
+
+	// Assert
+	causes := make(map[string]string)
+	for _, elimination := range e.Eliminated {
+		causes[elimination.ID] = elimination.Cause
+	}
+	if causes["a1"] != EliminatedByOutOfBounds || causes["a2"] != EliminatedBySquad || len(causes) != 2 {
+		t.Errorf("expected a2 to go with a1, got %v", e.Eliminated)
+	}
+	winner, ok := e.Winner()
+	if !e.IsOver() || !ok || winner.Squad != "b" {
+		t.Errorf("expected squad b to win with two snakes left, got %+v (over %v)", winner, e.IsOver())
+	}
+}
@@ -0,0 +1,26 @@
+package rules
+
+import "fmt"
+
+// islandsBridgesHazards is the hazard layout of the official hz_islands_bridges map on an 11x11 board: a cross
+// through the centre and an L in each corner, cutting the board into four islands joined by one-cell bridges.
+var islandsBridgesHazards = []Coord{
+	{5, 10}, {5, 9}, {5, 7}, {5, 6}, {5, 5}, {5, 4}, {5, 3}, {5, 0}, {5, 1},
+	{6, 5}, {7, 5}, {9, 5}, {10, 5}, {4, 5}, {3, 5}, {1, 5}, {0, 5},
+	{1, 10}, {9, 10}, {1, 0}, {9, 0}, {10, 1}, {10, 0}, {10, 10}, {10, 9},
+	{0, 10}, {0, 9}, {0, 1}, {0, 0}, {0, 6}, {0, 4}, {10, 6}, {10, 4},
+	{6, 10}, {4, 10}, {6, 0}, {4, 0},
+}
+
+// mapHazards returns the hazards a map starts with on a width x height board. Like the official maps, hazard
+// maps only come in the sizes they were drawn for.
+func mapHazards(gameMap string, width, height int) ([]Coord, error) {
+	switch gameMap {
+	case MapHzIslandsBridges:
+		if width != 11 || height != 11 {
+			return nil, fmt.Errorf("map %s is only available on an 11x11 board, not %dx%d", gameMap, width, height)
+		}
+		return append([]Coord{}, islandsBridgesHazards...), nil
+	}
+	return []Coord{}, nil
+}
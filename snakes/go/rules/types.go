@@ -0,0 +1,154 @@
+/*
+Package rules is a small, in-repo implementation of the Battlesnake game rules. It lets us play complete games
+locally against any snake URL without depending on the official battlesnake CLI.
+
+The request types below mirror the structs every Go snake in this repo decodes in its main.go, so a GameState
+built here can be posted straight to any of our snakes (or the Ruby and Python ones).
+*/
+package rules
+
+type GameState struct {
+	Game  Game        `json:"game"`
+	Turn  int         `json:"turn"`
+	Board Board       `json:"board"`
+	You   Battlesnake `json:"you"`
+}
+
+type Game struct {
+	ID      string  `json:"id"`
+	Ruleset Ruleset `json:"ruleset"`
+	Timeout int32   `json:"timeout"`
+	Map     string  `json:"map"`
+	Source  string  `json:"source"`
+}
+
+type Ruleset struct {
+	Name     string   `json:"name"`
+	Version  string   `json:"version"`
+	Settings Settings `json:"settings"`
+}
+
+type Settings struct {
+	FoodSpawnChance     int32  `json:"foodSpawnChance"`
+	MinimumFood         int32  `json:"minimumFood"`
+	HazardDamagePerTurn int32  `json:"hazardDamagePerTurn"`
+	Royale              Royale `json:"royale"`
+	Squad               Squad  `json:"squad"`
+}
+
+type Royale struct {
+	ShrinkEveryNTurns int32 `json:"shrinkEveryNTurns"`
+}
+
+type Squad struct {
+	AllowBodyCollisions bool `json:"allowBodyCollisions"`
+	SharedElimination   bool `json:"sharedElimination"`
+	SharedHealth        bool `json:"sharedHealth"`
+	SharedLength        bool `json:"sharedLength"`
+}
+
+type Board struct {
+	Height int           `json:"height"`
+	Width  int           `json:"width"`
+	Food   []Coord       `json:"food"`
+	Snakes []Battlesnake `json:"snakes"`
+
+	// Used in non-standard game modes
+	Hazards []Coord `json:"hazards"`
+}
+
+type Battlesnake struct {
+	ID      string  `json:"id"`
+	Name    string  `json:"name"`
+	Health  int32   `json:"health"`
+	Body    []Coord `json:"body"`
+	Head    Coord   `json:"head"`
+	Length  int32   `json:"length"`
+	Latency string  `json:"latency"`
+
+	// Used in non-standard game modes
+	Shout string `json:"shout"`
+	Squad string `json:"squad"`
+}
+
+type Coord struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Response Structs
+
+type BattlesnakeInfoResponse struct {
+	APIVersion string `json:"apiversion"`
+	Author     string `json:"author"`
+	Color      string `json:"color"`
+	Head       string `json:"head"`
+	Tail       string `json:"tail"`
+}
+
+type BattlesnakeMoveResponse struct {
+	Move  string `json:"move"`
+	Shout string `json:"shout,omitempty"`
+}
+
+// Moves a snake can make. Anything else returned by a snake is treated as the snake's previous move.
+const (
+	MoveUp    = "up"
+	MoveDown  = "down"
+	MoveLeft  = "left"
+	MoveRight = "right"
+)
+
+// Moves lists every valid move in a fixed order.
+var Moves = []string{MoveUp, MoveDown, MoveLeft, MoveRight}
+
+// IsValidMove reports whether move is one of the four directions.
+func IsValidMove(move string) bool {
+	for _, m := range Moves {
+		if m == move {
+			return true
+		}
+	}
+	return false
+}
+
+// Neighbour returns the coord one step from c in the direction of move. It does not wrap or bounds check.
+func (c Coord) Neighbour(move string) Coord {
+	switch move {
+	case MoveUp:
+		return Coord{X: c.X, Y: c.Y + 1}
+	case MoveDown:
+		return Coord{X: c.X, Y: c.Y - 1}
+	case MoveLeft:
+		return Coord{X: c.X - 1, Y: c.Y}
+	case MoveRight:
+		return Coord{X: c.X + 1, Y: c.Y}
+	}
+	return c
+}
+
+// StateFor returns the GameState a single snake would receive from the engine, with You filled in.
+// The second return value is false if the snake is not on the board.
+func StateFor(game Game, turn int, board Board, snakeID string) (GameState, bool) {
+	state := GameState{Game: game, Turn: turn, Board: board}
+	for _, snake := range board.Snakes {
+		if snake.ID == snakeID {
+			state.You = snake
+			return state, true
+		}
+	}
+	return state, false
+}
+
+// Clone returns a deep copy of the board so it can be kept after the engine moves on.
+func (b Board) Clone() Board {
+	clone := b
+	clone.Food = append([]Coord{}, b.Food...)
+	clone.Hazards = append([]Coord{}, b.Hazards...)
+	clone.Snakes = make([]Battlesnake, len(b.Snakes))
+	for i, snake := range b.Snakes {
+		snake.Body = append([]Coord{}, snake.Body...)
+		clone.Snakes[i] = snake
+	}
+	return clone
+}
@@ -0,0 +1,287 @@
+/*
+Package safety holds the rules spring-league-2022 rules moves out with, so other snakes can check their moves
+against them too. pathy-snake uses them as a veto over its path planning.
+
+Some rules are hard: moving onto our neck, off the edge of a board that doesn't wrap, or onto a body. The others
+are soft, and only rule a move out while there is another move left: moving into a royale hazard, and moving onto a
+cell that is boxed in by bodies or is a corner with bodies next to it.
+*/
+package safety
+
+import (
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// The rules, as Check names them.
+const (
+	RuleNeck       = "neck"
+	RuleWall       = "wall"
+	RuleOwnBody    = "own body"
+	RuleSnake      = "snake"
+	RuleHazard     = "hazard"
+	RuleSurrounded = "surrounded"
+	RuleCorner     = "corner"
+)
+
+// Check returns the moves the rules rule out for the snake the state is for, each with the first rule that ruled
+// it out. Moves that aren't in it are safe.
+func Check(state rules.GameState) map[string]string {
+	if len(state.You.Body) == 0 {
+		return map[string]string{}
+	}
+	c := checker{state: state, head: state.You.Body[0], vetoes: make(map[string]string)}
+	c.neck()
+	c.walls()
+	c.bodies()
+	c.hazards()
+	c.traps()
+	return c.vetoes
+}
+
+// Safe returns the moves vetoes doesn't rule out, in the order of rules.Moves.
+func Safe(vetoes map[string]string) []string {
+	var moves []string
+	for _, move := range rules.Moves {
+		if _, ok := vetoes[move]; !ok {
+			moves = append(moves, move)
+		}
+	}
+	return moves
+}
+
+type checker struct {
+	state  rules.GameState
+	head   rules.Coord
+	vetoes map[string]string
+}
+
+// veto rules a move out, unless an earlier rule already has.
+func (c *checker) veto(move, rule string) {
+	if _, ok := c.vetoes[move]; !ok {
+		c.vetoes[move] = rule
+	}
+}
+
+// vetoIfSpare rules a move out for a soft rule, as long as it isn't the last move left.
+func (c *checker) vetoIfSpare(move, rule string) {
+	if len(Safe(c.vetoes)) > 1 {
+		c.veto(move, rule)
+	}
+}
+
+func (c *checker) allowed(move string) bool {
+	_, vetoed := c.vetoes[move]
+	return !vetoed
+}
+
+func (c *checker) wrapped() bool {
+	return c.state.Game.Ruleset.Name == rules.RulesetWrapped
+}
+
+// neck rules out moving back onto our own neck. On a wrapped board the neck can be across the edge, in which
+// case it is the other way.
+func (c *checker) neck() {
+	if len(c.state.You.Body) < 2 {
+		return
+	}
+	neck := c.state.You.Body[1]
+	dx, dy := neck.X-c.head.X, neck.Y-c.head.Y
+	if c.wrapped() && abs(dx) > 1 {
+		dx = -dx
+	}
+	if c.wrapped() && abs(dy) > 1 {
+		dy = -dy
+	}
+	switch {
+	case dx < 0:
+		c.veto(rules.MoveLeft, RuleNeck)
+	case dx > 0:
+		c.veto(rules.MoveRight, RuleNeck)
+	case dy < 0:
+		c.veto(rules.MoveDown, RuleNeck)
+	case dy > 0:
+		c.veto(rules.MoveUp, RuleNeck)
+	}
+}
+
+// walls rules out moving off the board, unless it wraps.
+func (c *checker) walls() {
+	if c.wrapped() {
+		return
+	}
+	if c.head.X == 0 {
+		c.veto(rules.MoveLeft, RuleWall)
+	}
+	if c.head.X == c.state.Board.Width-1 {
+		c.veto(rules.MoveRight, RuleWall)
+	}
+	if c.head.Y == 0 {
+		c.veto(rules.MoveDown, RuleWall)
+	}
+	if c.head.Y == c.state.Board.Height-1 {
+		c.veto(rules.MoveUp, RuleWall)
+	}
+}
+
+// bodies rules out moving onto our own body, then onto any snake's, across the edge too on a wrapped board.
+func (c *checker) bodies() {
+	width, height := c.state.Board.Width, c.state.Board.Height
+	for _, rule := range []string{RuleOwnBody, RuleSnake} {
+		occupied := func(x, y int) bool {
+			if rule == RuleOwnBody {
+				return contains(c.state.You.Body, x, y)
+			}
+			return isSnake(x, y, c.state.Board.Snakes)
+		}
+		if occupied(c.head.X+1, c.head.Y) {
+			c.veto(rules.MoveRight, rule)
+		}
+		if occupied(c.head.X-1, c.head.Y) {
+			c.veto(rules.MoveLeft, rule)
+		}
+		if occupied(c.head.X, c.head.Y+1) {
+			c.veto(rules.MoveUp, rule)
+		}
+		if occupied(c.head.X, c.head.Y-1) {
+			c.veto(rules.MoveDown, rule)
+		}
+		if !c.wrapped() || !onEdge(c.head.X, c.head.Y, width, height) {
+			continue
+		}
+		if c.head.X == width-1 && occupied(0, c.head.Y) {
+			c.veto(rules.MoveRight, rule)
+		}
+		if c.head.X == 0 && occupied(width-1, c.head.Y) {
+			c.veto(rules.MoveLeft, rule)
+		}
+		if c.head.Y == height-1 && occupied(c.head.X, 0) {
+			c.veto(rules.MoveUp, rule)
+		}
+		if c.head.Y == 0 && occupied(c.head.X, height-1) {
+			c.veto(rules.MoveDown, rule)
+		}
+	}
+}
+
+// hazards rules out moving into a hazard in royale games, while there is another way to go.
+func (c *checker) hazards() {
+	if c.state.Game.Ruleset.Name != rules.RulesetRoyale {
+		return
+	}
+	hazards := c.state.Board.Hazards
+	if c.head.X-1 >= 0 && contains(hazards, c.head.X-1, c.head.Y) {
+		c.vetoIfSpare(rules.MoveLeft, RuleHazard)
+	}
+	if c.head.X+1 <= c.state.Board.Width-1 && contains(hazards, c.head.X+1, c.head.Y) {
+		c.vetoIfSpare(rules.MoveRight, RuleHazard)
+	}
+	if c.head.Y-1 >= 0 && contains(hazards, c.head.X, c.head.Y-1) {
+		c.vetoIfSpare(rules.MoveDown, RuleHazard)
+	}
+	if c.head.Y+1 < c.state.Board.Height-1 && contains(hazards, c.head.X, c.head.Y+1) {
+		c.vetoIfSpare(rules.MoveUp, RuleHazard)
+	}
+}
+
+// traps rules out moving onto a cell boxed in by bodies, or into a corner with bodies next to it, while there is
+// another way to go. On a wrapped board the cell across the edge is checked too.
+func (c *checker) traps() {
+	if len(Safe(c.vetoes)) <= 1 {
+		return
+	}
+	width, height := c.state.Board.Width, c.state.Board.Height
+	moves := []struct {
+		move             string
+		x, y             int
+		acrossX, acrossY int
+	}{
+		{rules.MoveLeft, c.head.X - 1, c.head.Y, width - 1, c.head.Y},
+		{rules.MoveRight, c.head.X + 1, c.head.Y, 0, c.head.Y},
+		{rules.MoveDown, c.head.X, c.head.Y - 1, c.head.X, height - 1},
+		{rules.MoveUp, c.head.X, c.head.Y + 1, c.head.X, 0},
+	}
+	for _, m := range moves {
+		if !c.allowed(m.move) {
+			continue
+		}
+		if rule, trapped := c.trap(m.x, m.y, m.move); trapped {
+			c.vetoIfSpare(m.move, rule)
+		}
+		if c.wrapped() && c.allowed(m.move) && onEdge(c.head.X, c.head.Y, width, height) {
+			if rule, trapped := c.trap(m.acrossX, m.acrossY, m.move); trapped {
+				c.vetoIfSpare(m.move, rule)
+			}
+		}
+	}
+}
+
+// trap reports whether moving onto the cell would trap us, and which rule says so.
+func (c *checker) trap(x, y int, move string) (string, bool) {
+	if isSurrounded(x, y, move, c.state.Board.Snakes) {
+		return RuleSurrounded, true
+	}
+	if isUnsafeCorner(x, y, c.state.Board.Width, c.state.Board.Height, c.state.Board.Snakes) {
+		return RuleCorner, true
+	}
+	return "", false
+}
+
+// isSurrounded reports whether the cell we would move onto has a body on each side but the one we came from.
+func isSurrounded(x, y int, move string, snakes []rules.Battlesnake) bool {
+	switch move {
+	case rules.MoveLeft:
+		return isSnake(x-1, y, snakes) && isSnake(x, y+1, snakes) && isSnake(x, y-1, snakes)
+	case rules.MoveRight:
+		return isSnake(x+1, y, snakes) && isSnake(x, y+1, snakes) && isSnake(x, y-1, snakes)
+	case rules.MoveUp:
+		return isSnake(x, y+1, snakes) && isSnake(x+1, y, snakes) && isSnake(x-1, y, snakes)
+	case rules.MoveDown:
+		return isSnake(x, y-1, snakes) && isSnake(x+1, y, snakes) && isSnake(x-1, y, snakes)
+	}
+	return false
+}
+
+// isUnsafeCorner reports whether the cell is a corner of the board with bodies on both of its neighbours.
+func isUnsafeCorner(x, y, width, height int, snakes []rules.Battlesnake) bool {
+	switch {
+	case x == 0 && y == 0:
+		return isSnake(x+1, y, snakes) && isSnake(x, y+1, snakes)
+	case x == 0 && y == height-1:
+		return isSnake(x+1, y, snakes) && isSnake(x, y-1, snakes)
+	case x == width-1 && y == 0:
+		return isSnake(x-1, y, snakes) && isSnake(x, y+1, snakes)
+	case x == width-1 && y == height-1:
+		return isSnake(x-1, y, snakes) && isSnake(x, y-1, snakes)
+	}
+	return false
+}
+
+// isSnake reports whether any snake's body, ours included, is on the cell.
+func isSnake(x, y int, snakes []rules.Battlesnake) bool {
+	for _, snake := range snakes {
+		if contains(snake.Body, x, y) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(coords []rules.Coord, x, y int) bool {
+	for _, c := range coords {
+		if c.X == x && c.Y == y {
+			return true
+		}
+	}
+	return false
+}
+
+func onEdge(x, y, width, height int) bool {
+	return x == 0 || x == width-1 || y == 0 || y == height-1
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,88 @@
+package safety
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/asciiboard"
+)
+
+func TestCheck(t *testing.T) {
+	// Arrange
+	positions := []struct {
+		name     string
+		board    string
+		expected map[string]string
+	}{
+		{
+			name: "neck, wall and another snake",
+			board: `
+				. . . . .
+				. . . . .
+				b b B . .
+				A a a . .
+			`,
+			expected: map[string]string{"right": RuleNeck, "left": RuleWall, "down": RuleWall, "up": RuleSnake},
+		},
+		{
+			name: "a pocket is ruled out while there is another way",
+			board: `
+				. . . . .
+				. b b b .
+				. B . b .
+				. . A . .
+				. . a . .
+			`,
+			expected: map[string]string{"down": RuleNeck, "up": RuleSurrounded},
+		},
+		{
+			name: "the last way out isn't ruled out, even into a pocket",
+			board: `
+				. . . . .
+				. b b b .
+				. b . b .
+				. B A C .
+				. . a c .
+			`,
+			expected: map[string]string{"down": RuleNeck, "left": RuleSnake, "right": RuleSnake},
+		},
+		{
+			name: "bodies across the edge of a wrapped board",
+			board: `
+				ruleset: wrapped
+
+				. . . b .
+				. . . B .
+				. . . . .
+				. . . . .
+				. a a A .
+			`,
+			expected: map[string]string{"left": RuleNeck, "down": RuleSnake},
+		},
+	}
+
+	for _, p := range positions {
+		state := asciiboard.MustParse(p.board)
+
+		// Act
+		vetoes := Check(state)
+
+		// Assert
+		if !reflect.DeepEqual(vetoes, p.expected) {
+			t.Errorf("%s: expected %v, got %v", p.name, p.expected, vetoes)
+		}
+	}
+}
+
+func TestSafe(t *testing.T) {
+	// Arrange
+	vetoes := map[string]string{"down": RuleNeck, "left": RuleWall}
+
+	// Act
+	safe := Safe(vetoes)
+
+	// Assert
+	if !reflect.DeepEqual(safe, []string{"up", "right"}) {
+		t.Errorf("expected up and right in that order, got %v", safe)
+	}
+}
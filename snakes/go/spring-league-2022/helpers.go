@@ -3,11 +3,37 @@ package main
 // This file contains helper functions for the starter-snake project.
 
 import (
+	"hash/fnv"
 	"log"
+	"math/rand"
+	"os"
 	"strings"
 	"strconv"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
 )
 
+// Environment variable that overrides the game ID as the source of the random seed.
+// Setting it makes every game play the same way, which is handy when replaying a loss.
+const seedEnv = "BATTLESNAKE_SEED"
+
+// Function that returns the random source used for every decision on this turn.
+// It is seeded from the game ID (or BATTLESNAKE_SEED) and the turn, so the same request always gets the same move.
+func newGameRand(state GameState) *rand.Rand {
+	return rand.New(rand.NewSource(gameSeed(state)))
+}
+
+// Function that hashes the game ID, or the seed override, into a seed for the given turn.
+func gameSeed(state GameState) int64 {
+	h := fnv.New64a()
+	if seed, ok := os.LookupEnv(seedEnv); ok {
+		h.Write([]byte(seed))
+	} else {
+		h.Write([]byte(state.Game.ID))
+	}
+	return int64(h.Sum64()) + int64(state.Turn)
+}
+
 // This function helps ensure that user input is clearly marked in log entries, and that
 // a malicious user cannot cause confusion in other ways. Intended to be used with log.Printf.
 func sanatizeInput(s string) string {
@@ -36,4 +62,34 @@ func isNumber(i int) int {
 		// 0000 is meant to represent and error. Meaning we received a turn that was not a number.
 		return 0000
 	}
-}
\ No newline at end of file
+}
+
+// This function converts the game state into the shared rules package's types, for the checks in snakes/go/safety.
+func toRulesState(state GameState) rules.GameState {
+	converted := rules.GameState{
+		Game: rules.Game{
+			ID:      state.Game.ID,
+			Ruleset: rules.Ruleset{Name: state.Game.Ruleset.Name, Version: state.Game.Ruleset.Version},
+			Timeout: state.Game.Timeout,
+		},
+		Turn:  state.Turn,
+		Board: rules.Board{Width: state.Board.Width, Height: state.Board.Height, Food: toRulesCoords(state.Board.Food), Hazards: toRulesCoords(state.Board.Hazards)},
+		You:   toRulesSnake(state.You),
+	}
+	for _, snake := range state.Board.Snakes {
+		converted.Board.Snakes = append(converted.Board.Snakes, toRulesSnake(snake))
+	}
+	return converted
+}
+
+func toRulesSnake(snake Battlesnake) rules.Battlesnake {
+	return rules.Battlesnake{ID: snake.ID, Name: snake.Name, Health: snake.Health, Body: toRulesCoords(snake.Body), Head: rules.Coord(snake.Head), Length: snake.Length, Squad: snake.Squad}
+}
+
+func toRulesCoords(coords []Coord) []rules.Coord {
+	converted := make([]rules.Coord, len(coords))
+	for i, c := range coords {
+		converted[i] = rules.Coord(c)
+	}
+	return converted
+}
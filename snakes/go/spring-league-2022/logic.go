@@ -7,8 +7,10 @@ package main
 
 import (
 	"log"
-	"math/rand"
 	"sort"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+	"github.com/es-na-battlesnake/snakes/snakes/go/safety"
 )
 
 // This function is called when you register your Battlesnake on play.battlesnake.com
@@ -40,51 +42,24 @@ func end(state GameState) {
 	log.Printf("%s END\n\n", sanatizeInput(state.Game.ID))
 }
 
-// This function is used to check if a coordinate is in hazards.
-func isHazard(x int, y int, hazards []Coord) bool {
-	for _, hazard := range hazards {
-		if hazard.X == x && hazard.Y == y {
-			return true
-		}
-	}
-	return false
-}
-
-//This function is used to check if a coordinate is in your snake body.
-func isBody(x int, y int, body []Coord) bool {
-	for _, coord := range body {
-		if coord.X == x && coord.Y == y {
-			return true
-		}
-	}
-	return false
-}
-
-// This function is used to check if another snake is in the coordiantes.
-func isSnake(x int, y int, snakes []Battlesnake) bool {
+// This function is used to check if the coordinates are the tail of a snake that will move out of the way this turn.
+// A snake that has just eaten has its tail stacked, so that tail stays where it is.
+func isMovingTail(x int, y int, snakes []Battlesnake) bool {
 	for _, snake := range snakes {
-		for _, coord := range snake.Body {
-			if coord.X == x && coord.Y == y {
-				return true
-			}
+		n := len(snake.Body)
+		if n > 1 && snake.Body[n-1].X == x && snake.Body[n-1].Y == y && snake.Body[n-1] != snake.Body[n-2] {
+			return true
 		}
 	}
 	return false
 }
 
-// This function is used to check if our head x,y coord is on the edge of the board.
-func onEdge(x int, y int, width int, height int) bool {
-	if x == 0 || x == width-1 || y == 0 || y == height-1 {
-		return true
-	}
-	return false
-}
-
 // Function that takes in possibleMoves and tells us the currently available safe moves.
+// The moves are always listed in the same order so a seeded random choice between them is reproducible.
 func safeMoves(possibleMoves map[string]bool) []string {
 	var moves []string
-	for move, isSafe := range possibleMoves {
-		if isSafe {
+	for _, move := range []string{"up", "down", "left", "right"} {
+		if possibleMoves[move] {
 			moves = append(moves, move)
 		}
 	}
@@ -111,337 +86,26 @@ func sortFood(state GameState) []Coord {
 	return food
 }
 
-// This function takes in an x,y coordinate and tells us if it is surrounded by a snake body part.
-// Useful for checking if we are going to trap ourselves.
-func isSurrounded(x int, y int, move string, snakes []Battlesnake) bool {
-	if move == "left" {
-		if isSnake(x-1, y, snakes) && isSnake(x, y+1, snakes) && isSnake(x, y-1, snakes) {
-			return true
-		}
-	} else if move == "right" {
-		if isSnake(x+1, y, snakes) && isSnake(x, y+1, snakes) && isSnake(x, y-1, snakes) {
-			return true
-		}
-	} else if move == "up" {
-		if isSnake(x, y+1, snakes) && isSnake(x+1, y, snakes) && isSnake(x-1, y, snakes) {
-			return true
-		}
-	} else if move == "down" {
-		if isSnake(x, y-1, snakes) && isSnake(x+1, y, snakes) && isSnake(x-1, y, snakes) {
-			return true
-		}
-	}
-	return false
-}
-
-// This function takes in an x,y coordinate and tells us if it is a corner that is surrounded by another snake.
-// Possible corners are: (0,0), (0,state.Board.Height-1), (state.Board.Width-1,0), (state.Board.Width-1,state.Board.Height-1)
-func isUnsafeCorner(x int, y int, state GameState, snakes []Battlesnake) bool {
-	if x == 0 && y == 0 {
-		if isSnake(x+1, y, snakes) && isSnake(x, y+1, snakes) {
-			return true
-		}
-	} else if x == 0 && y == state.Board.Height-1 {
-		if isSnake(x+1, y, snakes) && isSnake(x, y-1, snakes) {
-			return true
-		}
-	} else if x == state.Board.Width-1 && y == 0 {
-		if isSnake(x-1, y, snakes) && isSnake(x, y+1, snakes) {
-			return true
-		}
-	} else if x == state.Board.Width-1 && y == state.Board.Height-1 {
-		if isSnake(x-1, y, snakes) && isSnake(x, y-1, snakes) {
-			return true
-		}
-	}
-	return false
-}
-
 // This function is called on every turn of a game. Use the provided GameState to decide
 // where to move -- valid moves are "up", "down", "left", or "right".
 // We've provided some code and comments to get you started.
 func move(state GameState) BattlesnakeMoveResponse {
-	possibleMoves := map[string]bool{
-		"up":    true,
-		"down":  true,
-		"left":  true,
-		"right": true,
-	}
-
-	// Don't move back on our own neck
+	rng := newGameRand(state)
 	myHead := state.You.Body[0] // Coordinates of your head
-	myNeck := state.You.Body[1] // Coordinates of body piece directly behind your head (your "neck")
-	if myNeck.X < myHead.X {
-		possibleMoves["left"] = false
-	} else if myNeck.X > myHead.X {
-		possibleMoves["right"] = false
-	} else if myNeck.Y < myHead.Y {
-		possibleMoves["down"] = false
-	} else if myNeck.Y > myHead.Y {
-		possibleMoves["up"] = false
-	}
-
-	// Avoid walls if we are in a game mode that doesn't allow wrapping.
 	boardWidth := state.Board.Width
 	boardHeight := state.Board.Height
 	gameMode := state.Game.Ruleset.Name
-	// Move away from edge of board if we are not in wrapped game mode.
-	if gameMode != "wrapped" {
-		if myHead.X == 0 {
-			log.Println("We are at the left edge of the board")
-			possibleMoves["left"] = false
-		}
-		if myHead.X == boardWidth-1 {
-			log.Println("We are at the right edge of the board")
-			possibleMoves["right"] = false
-		}
-		if myHead.Y == 0 {
-			log.Println("We are at the top edge of the board")
-			possibleMoves["down"] = false
-		}
-		if myHead.Y == boardHeight-1 {
-			log.Println("We are at the bottom edge of the board")
-			possibleMoves["up"] = false
-		}
-	}
 
-	// Don't hit yourself.
-	mybody := state.You.Body
-	// If a body part is immediately to the right or left of your head, you can't move right or left.
-	// Check if a body part is to the right of head. If it is, then we can't move right.
-	if isBody(myHead.X+1, myHead.Y, mybody) {
-		possibleMoves["right"] = false
-	}
-	// Check to see if body part is to the left of head. If it is, then we can't move left.
-	if isBody(myHead.X-1, myHead.Y, mybody) {
-		possibleMoves["left"] = false
-	}
-	// If a body part is immediately above or below your head, you can't move up or down.
-	// Check to see if body part is above head. If it is, then we can't move up.
-	if isBody(myHead.X, myHead.Y+1, mybody) {
-		possibleMoves["up"] = false
-	}
-	// Check to see if body part is below head. If it is, then we can't move down.
-	if isBody(myHead.X, myHead.Y-1, mybody) {
-		possibleMoves["down"] = false
-	}
-	// If we are in the wrapped game mode and we are on the edge of the board, we need to avoid wrapping into our own body.
-	if gameMode == "wrapped" && onEdge(myHead.X, myHead.Y, boardWidth, boardHeight) {
-		// Print that we are in this section of the code for debugging purposes.
-		log.Println("We are in wrapped game mode and don't want to wrap into our own body")
-		// If our head is at x = boardWidth - 1 then check if isBody(0, y) is true.
-		if myHead.X == boardWidth-1 && isBody(0, myHead.Y, mybody) {
-			possibleMoves["right"] = false
-		}
-		// If our head is at x = 0 then check if isBody(boardWidth-1, y) is true.
-		if myHead.X == 0 && isBody(boardWidth-1, myHead.Y, mybody) {
-			possibleMoves["left"] = false
-		}
-		// If our head is at y = boardHeight - 1 then check if isBody(x, 0) is true.
-		if myHead.Y == boardHeight-1 && isBody(myHead.X, 0, mybody) {
-			possibleMoves["up"] = false
-		}
-		// If our head is at y = 0 then check if isBody(x, boardHeight-1) is true.
-		if myHead.Y == 0 && isBody(myHead.X, boardHeight-1, mybody) {
-			possibleMoves["down"] = false
-		}
-	}
-
-	// TODO: Look ahead further than one cell to help with avoiding snakes.
-	// Don't collide with others snakes.
-	// If another snake is immediately to the right or left of your head, you can't move right or left.
-	// Check to see if other snake is to the right of head.
-	if isSnake(myHead.X+1, myHead.Y, state.Board.Snakes) {
-		possibleMoves["right"] = false
-	}
-	// Check to see if other snake is to the left of head.
-	if isSnake(myHead.X-1, myHead.Y, state.Board.Snakes) {
-		possibleMoves["left"] = false
-	}
-	// If another snake is immediately above or below your head, you can't move up or down.
-	// Check to see if other snake is above head.
-	if isSnake(myHead.X, myHead.Y+1, state.Board.Snakes) {
-		possibleMoves["up"] = false
-	}
-	// Check to see if other snake is below head.
-	if isSnake(myHead.X, myHead.Y-1, state.Board.Snakes) {
-		possibleMoves["down"] = false
-	}
-	// If we are in the wrapped game mode and we are on the edge of the board, we need to avoid wrapping into another snake.
-	if gameMode == "wrapped" && onEdge(myHead.X, myHead.Y, state.Board.Width, state.Board.Height) {
-		// Print that we are in this section of the code for debugging purposes.
-		log.Println("We are in wrapped game mode and don't want to wrap into another snake")
-		// If our head is at x = boardWidth - 1 then check if isSnake(0, y) is true.
-		if myHead.X == boardWidth-1 && isSnake(0, myHead.Y, state.Board.Snakes) {
-			possibleMoves["right"] = false
-		}
-		// If our head is at x = 0 then check if isSnake(boardWidth-1, y) is true.
-		if myHead.X == 0 && isSnake(boardWidth-1, myHead.Y, state.Board.Snakes) {
-			possibleMoves["left"] = false
-		}
-		// If our head is at y = boardHeight - 1 then check if isSnake(x, 0) is true.
-		if myHead.Y == boardHeight-1 && isSnake(myHead.X, 0, state.Board.Snakes) {
-			possibleMoves["up"] = false
-		}
-		// If our head is at y = 0 then check if isSnake(x, boardHeight-1) is true.
-		if myHead.Y == 0 && isSnake(myHead.X, boardHeight-1, state.Board.Snakes) {
-			possibleMoves["down"] = false
-		}
-	}
-
-	// Avoid Hazards if possible. If the only move is into a hazard, then we will take it.
-	// Hazards are represented as a list of coordinates.
-	hazards := state.Board.Hazards
-	// Only run this code if we are in royale game mode.
-	if gameMode == "royale" {
-		// Check if neighboring cells are in the list of hazards.
-		// If so, you can't move in that direction.
-		// Check to see if a hazard is to the left of our head.
-		if myHead.X-1 >= 0 {
-			if isHazard(myHead.X-1, myHead.Y, hazards) {
-				log.Printf("Going to hit a hazard to the left")
-				// If two or more safe moves are available, then set left to false.
-				if len(safeMoves(possibleMoves)) > 1 {
-					possibleMoves["left"] = false
-				}
-			}
-		}
-		// Check to see if hazard is to the right of our head.
-		if myHead.X+1 <= state.Board.Width-1 {
-			if isHazard(myHead.X+1, myHead.Y, hazards) {
-				log.Printf("Going to hit a hazard to the right")
-				// If two or more safe moves are available, then set right to false.
-				if len(safeMoves(possibleMoves)) > 1 {
-					possibleMoves["right"] = false
-				}
-			}
-		}
-		// Check to see if hazard is to below our head.
-		if myHead.Y-1 >= 0 {
-			if isHazard(myHead.X, myHead.Y-1, hazards) {
-				log.Printf("Going to hit a hazard below")
-				// If two or more safe moves are available, then set down to false.
-				if len(safeMoves(possibleMoves)) > 1 {
-					possibleMoves["down"] = false
-				}
-			}
-		}
-		// Check to see if hazard is above our head.
-		if myHead.Y+1 < state.Board.Height-1 {
-			if isHazard(myHead.X, myHead.Y+1, hazards) {
-				log.Printf("Going to hit a hazard above")
-				// If two or more safe moves are available, then set up to false.
-				if len(safeMoves(possibleMoves)) > 1 {
-					possibleMoves["up"] = false
-				}
-			}
-		}
-	}
-
-	// If there are more than one safe moves, then pick one that doesn't trap us in our own body or another snake.
-	if len(safeMoves(possibleMoves)) > 1 {
-		// Look at each safe move and see if it is surrounded by our body.
-		// If so, then set that move to false.
-		// if possible move left
-		if possibleMoves["left"] {
-			// Check to see if the cell to the left of our head isSurrounded by a snake body or 
-			// if the cell to the left of our head is a corner and surrounded by a snake.
-			if isSurrounded(myHead.X-1, myHead.Y, "left", state.Board.Snakes) || 
-			isUnsafeCorner(myHead.X-1, myHead.Y, state, state.Board.Snakes) {
-				if len(safeMoves(possibleMoves)) > 1 {
-					possibleMoves["left"] = false
-				}
-			}
-			// If gameMode is wrapped.
-			if gameMode == "wrapped" && possibleMoves["left"] {
-				// Check to see if our head is on the edge of the board.
-				if onEdge(myHead.X, myHead.Y, state.Board.Width, state.Board.Height) {
-					// Check to see if the cell on the opposite side of the board isSurrounded by a snake body or
-					// if the cell on the opposite side of the board is a corner and surrounded by a snake.
-					if isSurrounded(boardWidth-1, myHead.Y, "left", state.Board.Snakes) ||
-					isUnsafeCorner(boardWidth-1, myHead.Y, state, state.Board.Snakes) {
-						if len(safeMoves(possibleMoves)) > 1 {
-							possibleMoves["left"] = false
-						}
-					}
-				}
-			}
-		}
-		// Check to see if the move is to the right of our head.
-		if possibleMoves["right"] {
-			// Check to see if the cell to the right of our head isSurrounded by a snake body or
-			// if the cell to the right of our head is a corner and surrounded by a snake.
-			if isSurrounded(myHead.X+1, myHead.Y, "right", state.Board.Snakes) ||
-			isUnsafeCorner(myHead.X+1, myHead.Y, state, state.Board.Snakes) {
-				if len(safeMoves(possibleMoves)) > 1 {
-					possibleMoves["right"] = false
-				}
-			}
-			// If gameMode is wrapped.
-			if gameMode == "wrapped" && possibleMoves["right"] {
-				// Check to see if our head is on the edge of the board.
-				if onEdge(myHead.X, myHead.Y, state.Board.Width, state.Board.Height) {
-					// Check to see if the cell on the opposite side of the board isSurrounded by a snake body or
-					// if the cell on the opposite side of the board is a corner and surrounded by a snake.
-					if isSurrounded(0, myHead.Y, "right", state.Board.Snakes) ||
-					isUnsafeCorner(0, myHead.Y, state, state.Board.Snakes) {
-						if len(safeMoves(possibleMoves)) > 1 {
-							possibleMoves["right"] = false
-						}
-					}
-				}
-			}
-		}
-		// Check to see if the move is below our head.
-		if possibleMoves["down"] {
-			// Check to see if the cell below our head isSurrounded by a snake body or
-			// if the cell below our head is a corner and surrounded by a snake.
-			if isSurrounded(myHead.X, myHead.Y-1, "down", state.Board.Snakes) || 
-			isUnsafeCorner(myHead.X, myHead.Y-1, state, state.Board.Snakes) {
-				if len(safeMoves(possibleMoves)) > 1 {
-					possibleMoves["down"] = false
-				}
-			}
-			// If gameMode is wrapped.
-			if gameMode == "wrapped" && possibleMoves["down"] {
-				// Check to see if our head is on the edge of the board.
-				if onEdge(myHead.X, myHead.Y, state.Board.Width, state.Board.Height) {
-					// Check to see if the cell on the opposite side of the board isSurrounded by a snake body or
-					// if the cell on the opposite side of the board is a corner and surrounded by a snake.
-					if isSurrounded(myHead.X, boardHeight-1, "down", state.Board.Snakes) ||
-					isUnsafeCorner(myHead.X, boardHeight-1, state, state.Board.Snakes) {
-						if len(safeMoves(possibleMoves)) > 1 {
-							possibleMoves["down"] = false
-						}
-					}
-				}
-			}
-		}
-		// Check to see if the move is above our head.
-		if possibleMoves["up"] {
-			// Check to see if the cell above our head isSurrounded by a snake body or
-			// if the cell above our head is a corner and surrounded by a snake.
-			if isSurrounded(myHead.X, myHead.Y+1, "up", state.Board.Snakes) ||
-			isUnsafeCorner(myHead.X, myHead.Y+1, state, state.Board.Snakes) {
-				if len(safeMoves(possibleMoves)) > 1 {
-					possibleMoves["up"] = false
-				}
-			}
-			// If gameMode is wrapped.
-			if gameMode == "wrapped" && possibleMoves["up"] {
-				// Check to see if our head is on the edge of the board.
-				if onEdge(myHead.X, myHead.Y, state.Board.Width, state.Board.Height) {
-					// Check to see if the cell on the opposite side of the board isSurrounded by a snake body or
-					// if the cell on the opposite side of the board is a corner and surrounded by a snake.
-					if isSurrounded(myHead.X, 0, "up", state.Board.Snakes) ||
-					isUnsafeCorner(myHead.X, 0, state, state.Board.Snakes) {
-						if len(safeMoves(possibleMoves)) > 1 {
-							possibleMoves["up"] = false
-						}
-					}
-				}
-			}
+	// Rule out our neck, walls and bodies, and where there is a choice, hazards and cells that would trap us.
+	// The rules live in snakes/go/safety so pathy-snake can check its moves against them too.
+	vetoes := safety.Check(toRulesState(state))
+	possibleMoves := make(map[string]bool)
+	for _, move := range rules.Moves {
+		rule, vetoed := vetoes[move]
+		if vetoed {
+			log.Printf("Can't move %s: %s", move, rule)
 		}
+		possibleMoves[move] = !vetoed
 	}
 
 	// Find food if we are low on health
@@ -489,10 +153,31 @@ func move(state GameState) BattlesnakeMoveResponse {
 	var nextMove string
 
 	if len(safeMoves(possibleMoves)) == 0 {
+		// Every cell around us is taken, but a tail that is about to move will be free by the time we get there.
 		nextMove = "down"
+		for _, move := range []string{"up", "down", "left", "right"} {
+			x, y := myHead.X, myHead.Y
+			switch move {
+			case "up":
+				y++
+			case "down":
+				y--
+			case "left":
+				x--
+			case "right":
+				x++
+			}
+			if gameMode == "wrapped" {
+				x, y = (x+boardWidth)%boardWidth, (y+boardHeight)%boardHeight
+			}
+			if isMovingTail(x, y, state.Board.Snakes) {
+				nextMove = move
+				break
+			}
+		}
 		log.Printf("%s MOVE %d: No safe moves detected! Moving %s\n", sanatizeInput(state.Game.ID), isNumber(state.Turn), nextMove)
 	} else {
-		nextMove = safeMoves(possibleMoves)[rand.Intn(len(safeMoves(possibleMoves)))]
+		nextMove = safeMoves(possibleMoves)[rng.Intn(len(safeMoves(possibleMoves)))]
 		log.Printf("%s MOVE %d: %s\n", sanatizeInput(state.Game.ID), isNumber(state.Turn), nextMove)
 	}
 	return BattlesnakeMoveResponse{
@@ -1,10 +1,16 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
 	"testing"
 	"log"
 	"io/ioutil"
 	"os"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/asciiboard"
+	"github.com/es-na-battlesnake/snakes/snakes/go/conformance"
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
 )
 
 // Ignore log output when testing.
@@ -14,53 +20,48 @@ func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
 
-func TestNeckAvoidance(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		// Length 3, facing right
-		Head: Coord{X: 2, Y: 0},
-		Body: []Coord{{X: 2, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 0}},
-	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me},
-		},
-		You: me,
-	}
+// Seeds for the games each test position is played in. The snake seeds its random choices from the game ID, so
+// a few games exercise different choices while every run of a test stays exactly the same.
+var testSeeds = []int{0, 1, 2, 3, 5, 8, 13, 21}
 
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move left
-		if nextMove.Move == "left" {
-			t.Errorf("snake moved onto its own neck, %s", nextMove.Move)
-		}
-	}
+// Returns a copy of state with a game ID made from seed.
+func seededGame(state GameState, seed int) GameState {
+	state.Game.ID = fmt.Sprintf("test-game-%d", seed)
+	return state
 }
 
-// Test body avoidance.
-func TestBodyAvoidance(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		// Length 4, facing up (U shapped)
-		Head: Coord{X: 2, Y: 1},
-		Body: []Coord{{X: 2, Y: 1}, {X: 2, Y: 0}, {X: 3, Y: 0}, {X: 3, Y: 1}},
+// Returns the move the snake makes in state in each of the testSeeds games.
+func seededMoves(state GameState) []string {
+	var moves []string
+	for _, seed := range testSeeds {
+		moves = append(moves, move(seededGame(state, seed)).Move)
 	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me},
-		},
-		You: me,
+	return moves
+}
+
+// Parses an ASCII board (see snakes/go/asciiboard for the format) into a GameState for this snake.
+func parseBoard(t *testing.T, board string) GameState {
+	t.Helper()
+	parsed, err := asciiboard.Parse(board)
+	if err != nil {
+		t.Fatal(err)
 	}
+	return fromRules(t, parsed)
+}
 
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move right
-		if nextMove.Move == "right" {
-			t.Errorf("snake moved onto its own body, %s", nextMove.Move)
-		}
+// Converts a GameState from the shared rules package into this snake's own type. They have the same JSON, so
+// a round trip does it.
+func fromRules(t *testing.T, state rules.GameState) GameState {
+	t.Helper()
+	data, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
 	}
+	var converted GameState
+	if err := json.Unmarshal(data, &converted); err != nil {
+		t.Fatal(err)
+	}
+	return converted
 }
 
 // Test that we go towards nearest food.
@@ -78,12 +79,11 @@ func TestFoodEating(t *testing.T) {
 		},
 		You: me,
 	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
+	// Act
+	for _, nextMove := range seededMoves(state) {
 		// Assert never move left
-		if nextMove.Move != "left" {
-			t.Errorf("snake didn't move towards closest food, %s", nextMove.Move)
+		if nextMove != "left" {
+			t.Errorf("snake didn't move towards closest food, %s", nextMove)
 		}
 	}
 }
@@ -107,156 +107,11 @@ func TestFoodEating2(t *testing.T) {
 		},
 		You: me,
 	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move left
-		if nextMove.Move == "left" {
-			t.Errorf("snake didn't move towards second closest food, %s", nextMove.Move)
-		}
-	}
-}
-
-// Test that we do not wrap around into our own body.
-func TestBodyWrap(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		Head: Coord{X: 10, Y: 5},
-		Body: []Coord{{X: 10, Y: 5}, {X: 9, Y: 5}, {X: 8, Y: 5}, {X: 0, Y: 5}},
-	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me},
-			Height: 11,
-			Width:  11,
-		},
-		You: me,
-		Game: Game{
-			Ruleset: Ruleset{
-				Name: "wrapped",
-			},
-		},
-	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move right
-		if nextMove.Move == "right" {
-			t.Errorf("snake wrapped into its own body, %s", nextMove.Move)
-		}
-	}
-}
-
-// Test that we do not wrap around the board into another snake.
-func TestBodyWrap2(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		Head: Coord{X: 10, Y: 5},
-		Body: []Coord{{X: 10, Y: 5}, {X: 9, Y: 5}, {X: 8, Y: 5}, {X: 7, Y: 5}},
-	}
-	other := Battlesnake{
-		Head: Coord{X: 0, Y: 5},
-		Body: []Coord{{X: 0, Y: 5}, {X: 0, Y: 6}, {X: 0, Y: 7}, {X: 0, Y: 8}},
-	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me, other},
-			Height: 11,
-			Width:  11,
-		},
-		You: me,
-		Game: Game{
-			Ruleset: Ruleset{
-				Name: "wrapped",
-			},
-		},
-	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move right
-		if nextMove.Move == "right" {
-			t.Errorf("snake wrapped into another snake, %s", nextMove.Move)
-		}
-	}
-}
-
-// Test that we avoid walls when not in wrapped mode.
-func TestWallAvoidance(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		Head: Coord{X: 10, Y: 5},
-		Body: []Coord{{X: 10, Y: 5}, {X: 9, Y: 5}, {X: 8, Y: 5}, {X: 7, Y: 5}},
-	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me},
-			Height: 11,
-			Width:  11,
-		},
-		You: me,
-	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move right
-		if nextMove.Move == "right" {
-			t.Errorf("snake moved into wall, %s", nextMove.Move)
-		}
-	}
-}
-
-// Test that we avoid traping ourselves in our own body.
-func TestBodyTrap(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		Head: Coord{X: 2, Y: 1},
-		Body: []Coord{{X: 2, Y: 1}, {X: 2, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 0},
-			{X: 0, Y: 1}, {X: 0, Y: 2}, {X: 1, Y: 2}, {X: 2, Y: 2}},
-	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me},
-			Height: 11,
-			Width:  11,
-		},
-		You: me,
-	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
+	// Act
+	for _, nextMove := range seededMoves(state) {
 		// Assert never move left
-		if nextMove.Move == "left" {
-			t.Errorf("snake trapped in own body, %s", nextMove.Move)
-		}
-	}
-}
-
-// Test that we avoid traping ourselves in another snakes body.
-func TestBodyTrap2(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		Head: Coord{X: 0, Y: 2},
-		Body: []Coord{{X: 0, Y: 2}, {X: 0, Y: 1}, {X: 0, Y: 0}},
-	}
-	other := Battlesnake{
-		Head: Coord{X: 1, Y: 0},
-		Body: []Coord{{X: 1, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 1}, {X: 2, Y: 2}, {X: 2, Y: 3}, {X: 1, Y: 3}},
-	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me, other},
-			Height: 11,
-			Width:  11,
-		},
-		You: me,
-	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move right
-		if nextMove.Move == "right" {
-			t.Errorf("snake trapped in another snake body, %s", nextMove.Move)
+		if nextMove == "left" {
+			t.Errorf("snake didn't move towards second closest food, %s", nextMove)
 		}
 	}
 }
@@ -285,12 +140,11 @@ func TestBodyTrap3(t *testing.T) {
 			},
 		},
 	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
+	// Act
+	for _, nextMove := range seededMoves(state) {
 		// Assert never move down
-		if nextMove.Move == "down" {
-			t.Errorf("snake trapped in own body while wrapping, %s", nextMove.Move)
+		if nextMove == "down" {
+			t.Errorf("snake trapped in own body while wrapping, %s", nextMove)
 		}
 	}
 }
@@ -319,127 +173,17 @@ func TestBodyTrap4(t *testing.T) {
 			},
 		},
 	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
+	// Act
+	for _, nextMove := range seededMoves(state) {
 		// Assert never move down
-		if nextMove.Move == "down" {
-			t.Errorf("snake trapped in another body while wrapping, %s", nextMove.Move)
-		}
-	}
-}
-
-// Test that we avoid traping ourselves in a corner.
-func TestCornerTrap(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		Head: Coord{X: 0, Y: 1},
-		Body: []Coord{{X: 0, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}},
-	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me},
-			Height: 11,
-			Width:  11,
-		},
-		You: me,
-	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move down
-		if nextMove.Move == "down" {
-			t.Errorf("snake trapped in corner, %s", nextMove.Move)
-		}
-	}
-}
-
-// Test that we avoid traping ourselves in a corner. Just another variation of the previous test.
-func TestCornerTrap2(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		Head: Coord{X: 0, Y: 9},
-		Body: []Coord{{X: 0, Y: 9}, {X: 1, Y: 9}, {X: 1, Y: 10}, {X: 2, Y: 10}, {X: 3, Y: 10}},
-	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me},
-			Height: 11,
-			Width:  11,
-		},
-		You: me,
-	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move up
-		if nextMove.Move == "up" {
-			t.Errorf("snake trapped in corner, %s", nextMove.Move)
+		if nextMove == "down" {
+			t.Errorf("snake trapped in another body while wrapping, %s", nextMove)
 		}
 	}
 }
 
-// Test that we avoid traping ourselves in a corner when we wrap.
-func TestCornerTrap3(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		Head: Coord{X: 0, Y: 0},
-		Body: []Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}, {X: 9, Y: 0}, {X: 9, Y: 1}, {X: 10, Y: 1}},
-	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me},
-			Height: 11,
-			Width:  11,
-		},
-		You: me,
-		Game: Game{
-			Ruleset: Ruleset{
-				Name: "wrapped",
-			},
-		},
-	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move left
-		if nextMove.Move == "left" {
-			t.Errorf("snake trapped in corner while wrapping, %s", nextMove.Move)
-		}
-	}
+// Test the shared conformance scenarios. Run with -v to see the scorecard for every capability.
+func TestConformance(t *testing.T) {
+	conformance.Run(t, conformance.StrategyFunc(func(state rules.GameState) string { return move(fromRules(t, state)).Move }),
+		conformance.BasicSafety, conformance.WrappedSeams, conformance.Hazards, conformance.Traps)
 }
-
-// Test that we avoid traping ourselves in a corner when we wrap.
-// Same test as previous test, but testing that we don't trap in another snake.
-func TestCornerTrap4(t *testing.T) {
-	// Arrange
-	me := Battlesnake{
-		Head: Coord{X: 0, Y: 0},
-		Body: []Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}},
-	}
-	other := Battlesnake{
-		Head: Coord{X: 8, Y: 0},
-		Body: []Coord{{X: 8, Y: 0}, {X: 9, Y: 0}, {X: 9, Y: 1}, {X: 10, Y: 1}, {X: 10, Y: 2}},
-	}
-	state := GameState{
-		Board: Board{
-			Snakes: []Battlesnake{me, other},
-			Height: 11,
-			Width:  11,
-		},
-		You: me,
-		Game: Game{
-			Ruleset: Ruleset{
-				Name: "wrapped",
-			},
-		},
-	}
-	// Act 1000x (this isn't a great way to test, but it's okay for starting out)
-	for i := 0; i < 1000; i++ {
-		nextMove := move(state)
-		// Assert never move left
-		if nextMove.Move == "left" {
-			t.Errorf("snake trapped in corner while wrapping, %s", nextMove.Move)
-		}
-	}
-}
\ No newline at end of file
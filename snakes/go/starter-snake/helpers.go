@@ -3,11 +3,35 @@ package main
 // This file contains helper functions for the starter-snake project.
 
 import (
+	"hash/fnv"
 	"log"
+	"math/rand"
+	"os"
 	"strings"
 	"strconv"
 )
 
+// Environment variable that overrides the game ID as the source of the random seed.
+// Setting it makes every game play the same way, which is handy when replaying a loss.
+const seedEnv = "BATTLESNAKE_SEED"
+
+// Function that returns the random source used for every decision on this turn.
+// It is seeded from the game ID (or BATTLESNAKE_SEED) and the turn, so the same request always gets the same move.
+func newGameRand(state GameState) *rand.Rand {
+	return rand.New(rand.NewSource(gameSeed(state)))
+}
+
+// Function that hashes the game ID, or the seed override, into a seed for the given turn.
+func gameSeed(state GameState) int64 {
+	h := fnv.New64a()
+	if seed, ok := os.LookupEnv(seedEnv); ok {
+		h.Write([]byte(seed))
+	} else {
+		h.Write([]byte(state.Game.ID))
+	}
+	return int64(h.Sum64()) + int64(state.Turn)
+}
+
 // This function helps ensure that user input is clearly marked in log entries, and that
 // a malicious user cannot cause confusion in other ways. Intended to be used with log.Printf.
 func sanatizeInput(s string) string {
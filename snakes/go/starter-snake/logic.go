@@ -7,7 +7,6 @@ package main
 
 import (
 	"log"
-	"math/rand"
 )
 
 // This function is called when you register your Battlesnake on play.battlesnake.com
@@ -43,6 +42,7 @@ func end(state GameState) {
 // where to move -- valid moves are "up", "down", "left", or "right".
 // We've provided some code and comments to get you started.
 func move(state GameState) BattlesnakeMoveResponse {
+	rng := newGameRand(state)
 	possibleMoves := map[string]bool{
 		"up":    true,
 		"down":  true,
@@ -82,9 +82,10 @@ func move(state GameState) BattlesnakeMoveResponse {
 	// TODO: Step 5 - Select a move to make based on strategy, rather than random.
 	var nextMove string
 
+	// Walk the moves in a fixed order so the seeded random choice below is reproducible.
 	safeMoves := []string{}
-	for move, isSafe := range possibleMoves {
-		if isSafe {
+	for _, move := range []string{"up", "down", "left", "right"} {
+		if possibleMoves[move] {
 			safeMoves = append(safeMoves, move)
 		}
 	}
@@ -93,7 +94,7 @@ func move(state GameState) BattlesnakeMoveResponse {
 		nextMove = "down"
 		log.Printf("%s MOVE %d: No safe moves detected! Moving %s\n", sanatizeInput(state.Game.ID), isNumber(state.Turn), nextMove)
 	} else {
-		nextMove = safeMoves[rand.Intn(len(safeMoves))]
+		nextMove = safeMoves[rng.Intn(len(safeMoves))]
 		log.Printf("%s MOVE %d: %s\n", sanatizeInput(state.Game.ID), isNumber(state.Turn), nextMove)
 	}
 	return BattlesnakeMoveResponse{
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/conformance"
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// Ignore log output when testing.
+// Comment this function out to see log output when testing.
+func TestMain(m *testing.M) {
+	log.SetOutput(ioutil.Discard)
+	os.Exit(m.Run())
+}
+
+// Converts a GameState from the shared rules package into this snake's own type. They have the same JSON, so
+// a round trip does it.
+func fromRules(t *testing.T, state rules.GameState) GameState {
+	t.Helper()
+	data, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var converted GameState
+	if err := json.Unmarshal(data, &converted); err != nil {
+		t.Fatal(err)
+	}
+	return converted
+}
+
+// Test the shared conformance scenarios. Run with -v to see the scorecard for every capability. Nothing is
+// required yet: add capabilities to the list as Steps 1 to 3 in move get done.
+func TestConformance(t *testing.T) {
+	conformance.Run(t, conformance.StrategyFunc(func(state rules.GameState) string { return move(fromRules(t, state)).Move }))
+}
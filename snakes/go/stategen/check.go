@@ -0,0 +1,59 @@
+package stategen
+
+import (
+	"fmt"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// SafeMoves returns the moves that don't take you straight off the board or into a snake's body. A tail that
+// will move out of the way this turn counts as free, a tail that is stacked because the snake has just eaten
+// doesn't. Heads of other snakes count as bodies; whether a head-to-head is worth the risk is left to the snake.
+func SafeMoves(state rules.GameState) []string {
+	board := state.Board
+	wrapped := state.Game.Ruleset.Name == rules.RulesetWrapped
+	blocked := make(map[rules.Coord]bool)
+	for _, snake := range board.Snakes {
+		body := snake.Body
+		if n := len(body); n > 1 && body[n-1] != body[n-2] {
+			body = body[:n-1]
+		}
+		for _, c := range body {
+			blocked[c] = true
+		}
+	}
+
+	var safe []string
+	if len(state.You.Body) == 0 {
+		return safe
+	}
+	for _, move := range rules.Moves {
+		next := state.You.Body[0].Neighbour(move)
+		if wrapped {
+			next = rules.Coord{X: (next.X + board.Width) % board.Width, Y: (next.Y + board.Height) % board.Height}
+		}
+		if next.X < 0 || next.X >= board.Width || next.Y < 0 || next.Y >= board.Height || blocked[next] {
+			continue
+		}
+		safe = append(safe, move)
+	}
+	return safe
+}
+
+// Check returns an error if move isn't one of the four directions, or if it runs into a wall or a body while
+// SafeMoves has something better.
+func Check(state rules.GameState, move string) error {
+	if !rules.IsValidMove(move) {
+		return fmt.Errorf("%q is not a move", move)
+	}
+	safe := SafeMoves(state)
+	if len(safe) == 0 {
+		return nil
+	}
+	for _, m := range safe {
+		if m == move {
+			return nil
+		}
+	}
+	return fmt.Errorf("moved %s into a wall or body when %v were safe", move, safe)
+}
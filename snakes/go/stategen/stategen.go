@@ -0,0 +1,145 @@
+/*
+Package stategen builds random but valid game states for fuzzing snakes, and checks that the move a snake
+answers with is legal. The same seed always gives the same state, so a failure found by `go test -fuzz` can be
+rerun from the seed alone.
+*/
+package stategen
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// FromSeed returns the state for a seed.
+func FromSeed(seed int64) rules.GameState {
+	return Random(rand.New(rand.NewSource(seed)), fmt.Sprintf("fuzz-%d", seed))
+}
+
+// Random builds a state with a board between 3x3 and 25x25, usually one of the standard sizes, one to eight
+// snakes, and some food and hazards. Snakes are laid out along random walks so every body is connected, and
+// sometimes have stacked tails like a snake that has just eaten or just started.
+func Random(rng *rand.Rand, id string) rules.GameState {
+	width, height := boardSize(rng)
+	rulesets := []string{rules.RulesetStandard, rules.RulesetWrapped, rules.RulesetRoyale}
+	ruleset := rulesets[rng.Intn(len(rulesets))]
+
+	board := rules.Board{Width: width, Height: height, Food: []rules.Coord{}, Hazards: []rules.Coord{}, Snakes: []rules.Battlesnake{}}
+	occupied := make(map[rules.Coord]bool)
+	wanted := 1 + rng.Intn(4)
+	if rng.Intn(8) == 0 {
+		wanted += rng.Intn(5)
+	}
+	for i := 0; i < wanted; i++ {
+		snake, ok := randomSnake(rng, board, ruleset == rules.RulesetWrapped, occupied)
+		if !ok {
+			break
+		}
+		snake.ID = fmt.Sprintf("snake-%d", i+1)
+		snake.Name = snake.ID
+		board.Snakes = append(board.Snakes, snake)
+	}
+	// Bodies laid out on a wrapped board can cross the edge, so those stay wrapped.
+	if len(board.Snakes) == 1 && ruleset != rules.RulesetWrapped && rng.Intn(2) == 0 {
+		ruleset = rules.RulesetSolo
+	}
+
+	for i := rng.Intn(6); i > 0; i-- {
+		c := randomCoord(rng, width, height)
+		if !occupied[c] {
+			occupied[c] = true
+			board.Food = append(board.Food, c)
+		}
+	}
+	if ruleset == rules.RulesetRoyale || rng.Intn(4) == 0 {
+		// Hazards can be anywhere, including under snakes and food.
+		for i := rng.Intn(width * height / 2); i > 0; i-- {
+			board.Hazards = append(board.Hazards, randomCoord(rng, width, height))
+		}
+	}
+
+	game := rules.Game{
+		ID:      id,
+		Ruleset: rules.Ruleset{Name: ruleset, Version: "fuzz", Settings: rules.DefaultSettings()},
+		Timeout: 500,
+		Map:     rules.MapStandard,
+		Source:  "fuzz",
+	}
+	state := rules.GameState{Game: game, Turn: rng.Intn(500), Board: board}
+	if len(board.Snakes) > 0 {
+		state.You = board.Snakes[rng.Intn(len(board.Snakes))]
+	}
+	return state
+}
+
+func boardSize(rng *rand.Rand) (int, int) {
+	standard := []int{7, 11, 19}
+	if rng.Intn(3) > 0 {
+		size := standard[rng.Intn(len(standard))]
+		return size, size
+	}
+	return 3 + rng.Intn(23), 3 + rng.Intn(23)
+}
+
+// randomSnake lays a snake out along a random walk from a free cell. It fails if there is no free cell to
+// start from.
+func randomSnake(rng *rand.Rand, board rules.Board, wrapped bool, occupied map[rules.Coord]bool) (rules.Battlesnake, bool) {
+	var head rules.Coord
+	found := false
+	for try := 0; try < 50 && !found; try++ {
+		head = randomCoord(rng, board.Width, board.Height)
+		found = !occupied[head]
+	}
+	if !found {
+		return rules.Battlesnake{}, false
+	}
+
+	body := []rules.Coord{head}
+	occupied[head] = true
+	length := 3 + rng.Intn(13)
+	for len(body) < length {
+		var options []rules.Coord
+		for _, move := range rules.Moves {
+			next := body[len(body)-1].Neighbour(move)
+			if wrapped {
+				next = rules.Coord{X: (next.X + board.Width) % board.Width, Y: (next.Y + board.Height) % board.Height}
+			}
+			if next.X >= 0 && next.X < board.Width && next.Y >= 0 && next.Y < board.Height && !occupied[next] {
+				options = append(options, next)
+			}
+		}
+		if len(options) == 0 {
+			// Boxed in: stack the tail to make up the minimum length of three.
+			for len(body) < 3 {
+				body = append(body, body[len(body)-1])
+			}
+			break
+		}
+		next := options[rng.Intn(len(options))]
+		occupied[next] = true
+		body = append(body, next)
+	}
+	switch rng.Intn(8) {
+	case 0:
+		// Just eaten, so the tail is doubled up.
+		body = append(body, body[len(body)-1])
+	case 1:
+		// The start of a game, with every segment on the same cell.
+		for _, c := range body[1:] {
+			occupied[c] = false
+		}
+		body = []rules.Coord{head, head, head}
+	}
+	return rules.Battlesnake{
+		Health:  int32(1 + rng.Intn(100)),
+		Body:    body,
+		Head:    head,
+		Length:  int32(len(body)),
+		Latency: "0",
+	}, true
+}
+
+func randomCoord(rng *rand.Rand, width, height int) rules.Coord {
+	return rules.Coord{X: rng.Intn(width), Y: rng.Intn(height)}
+}
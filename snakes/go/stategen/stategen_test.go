@@ -0,0 +1,91 @@
+package stategen
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/asciiboard"
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+func TestFromSeedIsValid(t *testing.T) {
+	for seed := int64(0); seed < 500; seed++ {
+		// Act
+		state := FromSeed(seed)
+
+		// Assert
+		if !reflect.DeepEqual(state, FromSeed(seed)) {
+			t.Fatalf("seed %d: the same seed gave two different states", seed)
+		}
+		board := state.Board
+		if board.Width < 3 || board.Width > 25 || board.Height < 3 || board.Height > 25 {
+			t.Errorf("seed %d: unexpected board size %dx%d", seed, board.Width, board.Height)
+		}
+		seen := make(map[rules.Coord]string)
+		for _, snake := range board.Snakes {
+			if len(snake.Body) < 3 || snake.Head != snake.Body[0] || int(snake.Length) != len(snake.Body) {
+				t.Errorf("seed %d: bad snake %+v", seed, snake)
+				continue
+			}
+			for i, c := range snake.Body {
+				if c.X < 0 || c.X >= board.Width || c.Y < 0 || c.Y >= board.Height {
+					t.Errorf("seed %d: %s is off the board at %v", seed, snake.ID, c)
+				}
+				if other, ok := seen[c]; ok && other != snake.ID {
+					t.Errorf("seed %d: %s and %s overlap at %v", seed, snake.ID, other, c)
+				}
+				seen[c] = snake.ID
+				if i > 0 && c != snake.Body[i-1] && !touching(state, c, snake.Body[i-1]) {
+					t.Errorf("seed %d: %s has a gap between %v and %v", seed, snake.ID, snake.Body[i-1], c)
+				}
+			}
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	// Arrange
+	state := asciiboard.MustParse(`
+		. . .
+		a3 a2 .
+		A a1 .
+	`)
+	stacked := asciiboard.MustParse(`
+		length: A=5
+		. . .
+		a3 a2 .
+		A a1 .
+	`)
+
+	// Act
+	safe := SafeMoves(state)
+
+	// Assert
+	if !reflect.DeepEqual(safe, []string{rules.MoveUp}) {
+		t.Errorf("expected only up onto the tail to be safe, got %v", safe)
+	}
+	for _, move := range []string{rules.MoveDown, rules.MoveRight, "sideways"} {
+		if err := Check(state, move); err == nil {
+			t.Errorf("expected %s to fail", move)
+		}
+	}
+	if err := Check(state, rules.MoveUp); err != nil {
+		t.Errorf("expected moving onto a tail that will move to pass, got %v", err)
+	}
+	if err := Check(stacked, rules.MoveDown); err != nil {
+		t.Errorf("expected any move to pass with nowhere safe, got %v", err)
+	}
+}
+
+func touching(state rules.GameState, a, b rules.Coord) bool {
+	for _, move := range rules.Moves {
+		n := a.Neighbour(move)
+		if state.Game.Ruleset.Name == rules.RulesetWrapped {
+			n = rules.Coord{X: (n.X + state.Board.Width) % state.Board.Width, Y: (n.Y + state.Board.Height) % state.Board.Height}
+		}
+		if n == b {
+			return true
+		}
+	}
+	return false
+}
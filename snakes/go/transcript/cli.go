@@ -0,0 +1,89 @@
+package transcript
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// ReadCLI parses the output file of `battlesnake play --output`. Every turn line is a request body for one of the
+// snakes; its "you" is ignored because States rebuilds the request for any snake from the board.
+func ReadCLI(r io.Reader) (Transcript, error) {
+	var t Transcript
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	number := 0
+	complete := false
+	for scanner.Scan() {
+		number++
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if number == 1 {
+			if err := decodeLine(line, &t.Game, "game", number); err != nil {
+				return t, err
+			}
+			continue
+		}
+		if isResultLine(line) {
+			if err := decodeLine(line, &t.Result, "result", number); err != nil {
+				return t, err
+			}
+			complete = true
+			break
+		}
+		var state rules.GameState
+		if err := decodeLine(line, &state, "turn", number); err != nil {
+			return t, err
+		}
+		t.Turns = append(t.Turns, Turn{Turn: state.Turn, Board: state.Board})
+	}
+	if err := scanner.Err(); err != nil {
+		return t, err
+	}
+	if number == 0 {
+		return t, errors.New("empty CLI output")
+	}
+
+	for i := 0; i+1 < len(t.Turns); i++ {
+		t.Turns[i].Moves = inferMoves(t.Turns[i].Board, t.Turns[i+1].Board.Snakes)
+	}
+	if !complete {
+		return t, io.ErrUnexpectedEOF
+	}
+	return t, nil
+}
+
+// WriteCLI writes t in the battlesnake CLI's output format, so our games can be opened by any tool that reads
+// those files. Like the CLI, each turn is written as the request body of a single snake, here the first one on
+// the board.
+func WriteCLI(w io.Writer, t Transcript) error {
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(t.Game); err != nil {
+		return err
+	}
+	for _, turn := range t.Turns {
+		state := rules.GameState{Game: t.Game, Turn: turn.Turn, Board: turn.Board}
+		if len(turn.Board.Snakes) > 0 {
+			state.You = turn.Board.Snakes[0]
+		}
+		if err := enc.Encode(state); err != nil {
+			return err
+		}
+	}
+	return enc.Encode(t.Result)
+}
+
+func isResultLine(line []byte) bool {
+	var probe map[string]json.RawMessage
+	if json.Unmarshal(line, &probe) != nil {
+		return false
+	}
+	_, ok := probe["isDraw"]
+	return ok
+}
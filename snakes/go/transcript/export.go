@@ -0,0 +1,185 @@
+package transcript
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// The official game export uses the engine's own structs, which have Go-style keys and keep eliminated snakes
+// on the board with a Death.
+
+type exportFile struct {
+	Game   exportGame    `json:"Game"`
+	Frames []exportFrame `json:"Frames"`
+}
+
+type exportGame struct {
+	ID           string
+	Width        int
+	Height       int
+	Ruleset      map[string]json.RawMessage
+	RulesetName  string
+	SnakeTimeout int32
+	Source       string
+	Map          string
+}
+
+type exportFrame struct {
+	Turn    int
+	Snakes  []exportSnake
+	Food    []exportCoord
+	Hazards []exportCoord
+}
+
+type exportSnake struct {
+	ID      string
+	Name    string
+	Body    []exportCoord
+	Health  int32
+	Death   *exportDeath
+	Latency string
+	Shout   string
+	Squad   string
+}
+
+type exportDeath struct {
+	Cause        string
+	Turn         int
+	EliminatedBy string
+}
+
+type exportCoord struct {
+	X int
+	Y int
+}
+
+// ReadExport parses an official game export: the game as returned by the engine under "Game" and all of its
+// frames under "Frames". The winner is worked out from the last frame.
+func ReadExport(r io.Reader) (Transcript, error) {
+	var file exportFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return Transcript{}, fmt.Errorf("reading game export: %w", err)
+	}
+	if file.Game.Width == 0 || file.Game.Height == 0 {
+		return Transcript{}, errors.New("game export has no board size, is the Game missing?")
+	}
+	if len(file.Frames) == 0 {
+		return Transcript{}, errors.New("game export has no frames")
+	}
+
+	t := Transcript{Game: file.Game.game()}
+	for _, frame := range file.Frames {
+		board := rules.Board{
+			Width:   file.Game.Width,
+			Height:  file.Game.Height,
+			Food:    coords(frame.Food),
+			Hazards: coords(frame.Hazards),
+			Snakes:  []rules.Battlesnake{},
+		}
+		for _, snake := range frame.Snakes {
+			if snake.Death == nil {
+				board.Snakes = append(board.Snakes, snake.battlesnake())
+			}
+		}
+		t.Turns = append(t.Turns, Turn{Turn: frame.Turn, Board: board})
+	}
+
+	// A frame holds the result of the previous turn's moves: the snakes that died making them, still on the
+	// board, and how long each snake took to answer.
+	for i := 1; i < len(file.Frames); i++ {
+		previous := &t.Turns[i-1]
+		var moved []rules.Battlesnake
+		previous.Latency = make(map[string]int64)
+		for _, snake := range file.Frames[i].Snakes {
+			if snake.Death != nil && snake.Death.Turn != file.Frames[i].Turn {
+				continue
+			}
+			moved = append(moved, snake.battlesnake())
+			if snake.Death != nil {
+				previous.Eliminated = append(previous.Eliminated, rules.Elimination{ID: snake.ID, Cause: snake.Death.Cause, Turn: snake.Death.Turn, By: snake.Death.EliminatedBy})
+			}
+			if ms, err := strconv.ParseInt(snake.Latency, 10, 64); err == nil {
+				previous.Latency[snake.ID] = ms
+			}
+		}
+		previous.Moves = inferMoves(previous.Board, moved)
+	}
+
+	last := t.Turns[len(t.Turns)-1].Board.Snakes
+	switch len(last) {
+	case 0:
+		t.Result.IsDraw = true
+	case 1:
+		t.Result.WinnerID = last[0].ID
+		t.Result.WinnerName = last[0].Name
+	}
+	return t, nil
+}
+
+func (g exportGame) game() rules.Game {
+	game := rules.Game{ID: g.ID, Timeout: g.SnakeTimeout, Source: g.Source, Map: g.Map}
+	game.Ruleset.Name = g.RulesetName
+	if name := setting(g.Ruleset, "name"); name != "" {
+		game.Ruleset.Name = name
+	}
+	game.Ruleset.Version = setting(g.Ruleset, "version")
+	settings := &game.Ruleset.Settings
+	settings.FoodSpawnChance = int32(settingInt(g.Ruleset, "foodSpawnChance"))
+	settings.MinimumFood = int32(settingInt(g.Ruleset, "minimumFood"))
+	settings.HazardDamagePerTurn = int32(settingInt(g.Ruleset, "hazardDamagePerTurn"))
+	settings.Royale.ShrinkEveryNTurns = int32(settingInt(g.Ruleset, "shrinkEveryNTurns"))
+	settings.Squad.AllowBodyCollisions = setting(g.Ruleset, "allowBodyCollisions") == "true"
+	settings.Squad.SharedElimination = setting(g.Ruleset, "sharedElimination") == "true"
+	settings.Squad.SharedHealth = setting(g.Ruleset, "sharedHealth") == "true"
+	settings.Squad.SharedLength = setting(g.Ruleset, "sharedLength") == "true"
+	return game
+}
+
+// setting reads a ruleset setting. The engine stores them as strings, but numbers and booleans are accepted too.
+func setting(ruleset map[string]json.RawMessage, key string) string {
+	raw, ok := ruleset[key]
+	if !ok {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	return string(raw)
+}
+
+func settingInt(ruleset map[string]json.RawMessage, key string) int {
+	n, _ := strconv.Atoi(setting(ruleset, key))
+	return n
+}
+
+func (s exportSnake) battlesnake() rules.Battlesnake {
+	body := coords(s.Body)
+	snake := rules.Battlesnake{
+		ID:      s.ID,
+		Name:    s.Name,
+		Health:  s.Health,
+		Body:    body,
+		Length:  int32(len(body)),
+		Latency: s.Latency,
+		Shout:   s.Shout,
+		Squad:   s.Squad,
+	}
+	if len(body) > 0 {
+		snake.Head = body[0]
+	}
+	return snake
+}
+
+func coords(in []exportCoord) []rules.Coord {
+	out := make([]rules.Coord, len(in))
+	for i, c := range in {
+		out[i] = rules.Coord{X: c.X, Y: c.Y}
+	}
+	return out
+}
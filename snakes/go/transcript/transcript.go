@@ -0,0 +1,206 @@
+/*
+Package transcript loads games played outside this repo so they can be fed to our tools, and writes our games in
+the formats other tools expect.
+
+Three formats are understood:
+
+  - battlesnake CLI output, as written by `battlesnake play --output`: a line with the game, one request body
+    per turn and a final result line.
+  - the official game export: one JSON document with the game under "Game" and every turn under "Frames".
+  - our own arena recordings (see snakes/go/arena).
+
+Every format is turned into a Transcript, from which the GameState each snake received on every turn can be
+rebuilt with States.
+*/
+package transcript
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/arena"
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+// Transcript is a whole game as a sequence of boards.
+type Transcript struct {
+	Game   rules.Game
+	Turns  []Turn
+	Result Result
+}
+
+// Turn is the board at the start of a turn and what happened on it. Board only holds the snakes still alive.
+// Moves are inferred from the following board when the format doesn't record them, so a snake eliminated on the
+// turn may have no move. The last turn of a game has no moves.
+type Turn struct {
+	Turn       int
+	Board      rules.Board
+	Moves      map[string]string
+	Latency    map[string]int64
+	Eliminated []rules.Elimination
+}
+
+// Result is how the game ended, using the battlesnake CLI's keys.
+type Result struct {
+	WinnerID   string `json:"winnerId"`
+	WinnerName string `json:"winnerName"`
+	IsDraw     bool   `json:"isDraw"`
+}
+
+// States returns the GameState the given snake was sent on every turn it was alive, in order.
+func (t Transcript) States(snakeID string) []rules.GameState {
+	var states []rules.GameState
+	for _, turn := range t.Turns {
+		if state, ok := rules.StateFor(t.Game, turn.Turn, turn.Board, snakeID); ok {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+// Views returns States for every snake that took part in the game, keyed by snake ID.
+func (t Transcript) Views() map[string][]rules.GameState {
+	views := make(map[string][]rules.GameState)
+	for _, turn := range t.Turns {
+		for _, snake := range turn.Board.Snakes {
+			if _, ok := views[snake.ID]; !ok {
+				views[snake.ID] = t.States(snake.ID)
+			}
+		}
+	}
+	return views
+}
+
+// Places ranks the snakes by how long they lasted, keyed by snake ID. As in arena results, the winner is first,
+// everyone else is placed by the last turn they were on the board, and snakes eliminated on the same turn share a
+// place.
+func (t Transcript) Places() map[string]int {
+	lasted := make(map[string]int)
+	for _, turn := range t.Turns {
+		for _, snake := range turn.Board.Snakes {
+			lasted[snake.ID] = turn.Turn
+		}
+	}
+	if turn, ok := lasted[t.Result.WinnerID]; ok && !t.Result.IsDraw {
+		lasted[t.Result.WinnerID] = turn + 1
+	}
+	places := make(map[string]int, len(lasted))
+	for id := range lasted {
+		places[id] = 1
+		for _, other := range lasted {
+			if other > lasted[id] {
+				places[id]++
+			}
+		}
+	}
+	return places
+}
+
+// FromRecording converts an arena recording.
+func FromRecording(rec arena.Recording) Transcript {
+	t := Transcript{
+		Game:   rec.Header.Game,
+		Result: Result{WinnerID: rec.Result.WinnerID, WinnerName: rec.Result.WinnerName, IsDraw: rec.Result.IsDraw},
+	}
+	for _, frame := range rec.Frames {
+		t.Turns = append(t.Turns, Turn{Turn: frame.Turn, Board: frame.Board, Moves: frame.Moves, Latency: frame.Latency, Eliminated: frame.Eliminated})
+	}
+	return t
+}
+
+// Read works out which of the supported formats r holds and parses it. Like arena.ReadRecording, a game that
+// is missing its result line is returned with io.ErrUnexpectedEOF.
+func Read(r io.Reader) (Transcript, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return Transcript{}, err
+	}
+	switch detect(data) {
+	case formatExport:
+		return ReadExport(bytes.NewReader(data))
+	case formatRecording:
+		rec, err := arena.ReadRecording(bytes.NewReader(data))
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return Transcript{}, err
+		}
+		return FromRecording(rec), err
+	case formatCLI:
+		return ReadCLI(bytes.NewReader(data))
+	}
+	return Transcript{}, errors.New("not a battlesnake CLI output file, game export or arena recording")
+}
+
+type format int
+
+const (
+	formatUnknown format = iota
+	formatCLI
+	formatExport
+	formatRecording
+)
+
+// detect looks at the keys of the first JSON object in data.
+func detect(data []byte) format {
+	var probe map[string]json.RawMessage
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&probe); err != nil {
+		return formatUnknown
+	}
+	has := func(key string) bool {
+		_, ok := probe[key]
+		return ok
+	}
+	switch {
+	case has("Game") || has("Frames"):
+		return formatExport
+	case has("game") && has("snakes"):
+		return formatRecording
+	case has("id") && has("ruleset"):
+		return formatCLI
+	}
+	return formatUnknown
+}
+
+// inferMoves works out the move each snake made on turn from the position of its head on the next turn. next
+// may include snakes eliminated on the turn; snakes missing from it get no move.
+func inferMoves(turn rules.Board, next []rules.Battlesnake) map[string]string {
+	heads := make(map[string]rules.Coord)
+	for _, snake := range next {
+		if len(snake.Body) > 0 {
+			heads[snake.ID] = snake.Body[0]
+		}
+	}
+	moves := make(map[string]string)
+	for _, snake := range turn.Snakes {
+		head, ok := heads[snake.ID]
+		if !ok || len(snake.Body) == 0 {
+			continue
+		}
+		for _, move := range rules.Moves {
+			step := snake.Body[0].Neighbour(move)
+			// Compare modulo the board size so moves across the edge of a wrapped board are found too.
+			if mod(step.X, turn.Width) == mod(head.X, turn.Width) && mod(step.Y, turn.Height) == mod(head.Y, turn.Height) {
+				moves[snake.ID] = move
+				break
+			}
+		}
+	}
+	return moves
+}
+
+func mod(a, n int) int {
+	if n <= 0 {
+		return a
+	}
+	return ((a % n) + n) % n
+}
+
+func decodeLine(line []byte, v interface{}, what string, number int) error {
+	if err := json.Unmarshal(line, v); err != nil {
+		return fmt.Errorf("reading %s on line %d: %w", what, number, err)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package transcript
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/es-na-battlesnake/snakes/snakes/go/arena"
+	"github.com/es-na-battlesnake/snakes/snakes/go/rules"
+)
+
+func testRecording() arena.Recording {
+	snake := func(id string, health int32, body ...rules.Coord) rules.Battlesnake {
+		return rules.Battlesnake{ID: id, Name: id, Health: health, Body: body, Head: body[0], Length: int32(len(body))}
+	}
+	board := func(snakes ...rules.Battlesnake) rules.Board {
+		return rules.Board{Width: 5, Height: 5, Food: []rules.Coord{{X: 4, Y: 4}}, Hazards: []rules.Coord{}, Snakes: snakes}
+	}
+	return arena.Recording{
+		Header: arena.Header{Game: rules.Game{ID: "arena-7", Ruleset: rules.Ruleset{Name: rules.RulesetWrapped}, Map: rules.MapStandard, Timeout: 500}},
+		Frames: []arena.Frame{
+			{Turn: 0, Board: board(snake("a", 100, rules.Coord{X: 0, Y: 1}, rules.Coord{X: 0, Y: 0}), snake("b", 100, rules.Coord{X: 3, Y: 3}, rules.Coord{X: 3, Y: 2}))},
+			{Turn: 1, Board: board(snake("a", 99, rules.Coord{X: 4, Y: 1}, rules.Coord{X: 0, Y: 1}), snake("b", 99, rules.Coord{X: 3, Y: 4}, rules.Coord{X: 3, Y: 3}))},
+			{Turn: 2, Board: board(snake("a", 98, rules.Coord{X: 4, Y: 0}, rules.Coord{X: 4, Y: 1}))},
+		},
+		Result: arena.Result{WinnerID: "a", WinnerName: "a"},
+	}
+}
+
+func TestCLIRoundTrip(t *testing.T) {
+	// Arrange
+	original := FromRecording(testRecording())
+	var buf bytes.Buffer
+
+	// Act
+	err := WriteCLI(&buf, original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := Read(&buf)
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Game.ID != "arena-7" || read.Game.Ruleset.Name != rules.RulesetWrapped {
+		t.Errorf("game not read back, got %+v", read.Game)
+	}
+	if len(read.Turns) != 3 || read.Result.WinnerID != "a" {
+		t.Fatalf("expected 3 turns won by a, got %d turns and %+v", len(read.Turns), read.Result)
+	}
+	// a moved left across the wrapped edge and b moved up before it was eliminated.
+	if read.Turns[0].Moves["a"] != rules.MoveLeft || read.Turns[0].Moves["b"] != rules.MoveUp {
+		t.Errorf("unexpected inferred moves %v", read.Turns[0].Moves)
+	}
+	if _, ok := read.Turns[1].Moves["b"]; ok {
+		t.Errorf("expected no move for b once it has left the board")
+	}
+}
+
+func TestPlaces(t *testing.T) {
+	// Arrange
+	won := FromRecording(testRecording())
+	drawn := FromRecording(testRecording())
+	drawn.Turns[2].Board.Snakes = nil
+	drawn.Result = Result{IsDraw: true}
+
+	// Act
+	wonPlaces := won.Places()
+	drawnPlaces := drawn.Places()
+
+	// Assert
+	if wonPlaces["a"] != 1 || wonPlaces["b"] != 2 {
+		t.Errorf("expected a first and b second, got %v", wonPlaces)
+	}
+	if drawnPlaces["a"] != 1 || drawnPlaces["b"] != 1 {
+		t.Errorf("expected snakes eliminated together to share first place, got %v", drawnPlaces)
+	}
+}
+
+func TestCLIMissingResult(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	WriteCLI(&buf, FromRecording(testRecording()))
+	lines := strings.SplitAfter(buf.String(), "\n")
+	truncated := strings.Join(lines[:len(lines)-2], "")
+
+	// Act
+	read, err := ReadCLI(strings.NewReader(truncated))
+
+	// Assert
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	if len(read.Turns) != 3 {
+		t.Errorf("expected the turns before the cut to be kept, got %d", len(read.Turns))
+	}
+}
+
+const testExport = `{
+  "Game": {"ID": "4b0d6f3c", "Width": 7, "Height": 7, "SnakeTimeout": 500, "Source": "league", "Map": "standard",
+    "Ruleset": {"name": "standard", "foodSpawnChance": "15", "minimumFood": "1", "hazardDamagePerTurn": "14", "shrinkEveryNTurns": "25"}},
+  "Frames": [
+    {"Turn": 0, "Food": [{"X": 3, "Y": 3}], "Hazards": [], "Snakes": [
+      {"ID": "gs_1", "Name": "pathy", "Health": 100, "Latency": "0", "Death": null, "Body": [{"X": 1, "Y": 1}, {"X": 1, "Y": 1}, {"X": 1, "Y": 1}]},
+      {"ID": "gs_2", "Name": "other", "Health": 100, "Latency": "0", "Death": null, "Body": [{"X": 0, "Y": 5}, {"X": 0, "Y": 5}, {"X": 0, "Y": 5}]}]},
+    {"Turn": 1, "Food": [{"X": 3, "Y": 3}], "Hazards": [], "Snakes": [
+      {"ID": "gs_1", "Name": "pathy", "Health": 99, "Latency": "42", "Death": null, "Body": [{"X": 2, "Y": 1}, {"X": 1, "Y": 1}, {"X": 1, "Y": 1}]},
+      {"ID": "gs_2", "Name": "other", "Health": 99, "Latency": "120", "Death": {"Cause": "wall-collision", "Turn": 1, "EliminatedBy": ""}, "Body": [{"X": -1, "Y": 5}, {"X": 0, "Y": 5}, {"X": 0, "Y": 5}]}]},
+    {"Turn": 2, "Food": [{"X": 3, "Y": 3}], "Hazards": [], "Snakes": [
+      {"ID": "gs_1", "Name": "pathy", "Health": 98, "Latency": "40", "Death": null, "Body": [{"X": 2, "Y": 2}, {"X": 2, "Y": 1}, {"X": 1, "Y": 1}]},
+      {"ID": "gs_2", "Name": "other", "Health": 99, "Latency": "120", "Death": {"Cause": "wall-collision", "Turn": 1, "EliminatedBy": ""}, "Body": [{"X": -1, "Y": 5}, {"X": 0, "Y": 5}, {"X": 0, "Y": 5}]}]}
+  ]
+}`
+
+func TestReadExport(t *testing.T) {
+	// Act
+	read, err := Read(strings.NewReader(testExport))
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Game.Ruleset.Settings.HazardDamagePerTurn != 14 || read.Game.Timeout != 500 {
+		t.Errorf("settings not read, got %+v", read.Game)
+	}
+	if read.Result.WinnerName != "pathy" {
+		t.Errorf("expected pathy to win, got %+v", read.Result)
+	}
+	if n := len(read.Turns[1].Board.Snakes); n != 1 {
+		t.Errorf("expected dead snakes to be left off the board, got %d snakes", n)
+	}
+	first := read.Turns[0]
+	if first.Moves["gs_1"] != rules.MoveRight || first.Moves["gs_2"] != rules.MoveLeft {
+		t.Errorf("unexpected inferred moves %v", first.Moves)
+	}
+	if len(first.Eliminated) != 1 || first.Eliminated[0].Cause != rules.EliminatedByOutOfBounds {
+		t.Errorf("expected other to be eliminated on turn 0, got %+v", first.Eliminated)
+	}
+	if first.Latency["gs_2"] != 120 {
+		t.Errorf("expected latency to be attached to the turn it was measured on, got %v", first.Latency)
+	}
+
+	states := read.States("gs_1")
+	if len(states) != 3 || states[2].You.Health != 98 || states[2].Board.Width != 7 {
+		t.Errorf("unexpected states for pathy: %+v", states)
+	}
+	if views := read.Views(); len(views["gs_2"]) != 1 {
+		t.Errorf("expected one state for other, got %d", len(views["gs_2"]))
+	}
+}
+
+func TestReadUnknown(t *testing.T) {
+	// Act
+	_, err := Read(strings.NewReader(`{"hello": "world"}`))
+
+	// Assert
+	if err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Package tuning searches for good snake parameters. It runs a (1+lambda) evolution strategy: each generation
+mutates the current best parameter vector lambda times, keeps the best child if it beats its parent, and
+adapts the mutation size with the 1/5th success rule.
+
+Fitness is whatever the caller's Evaluate function returns, normally the mean score over a fixed set of
+seeded games, so parent and children are always compared on exactly the same games.
+*/
+package tuning
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// Param is one dimension of the search space. Values are kept within [Min, Max] and rounded when Integer is set.
+type Param struct {
+	Name    string
+	Min     float64
+	Max     float64
+	Start   float64
+	Integer bool
+}
+
+// Space is the full list of parameters being tuned.
+type Space []Param
+
+// Vector holds one value per Param, in the same order as the Space.
+type Vector []float64
+
+// Start returns the vector every search begins from.
+func (s Space) Start() Vector {
+	v := make(Vector, len(s))
+	for i, p := range s {
+		v[i] = p.Start
+	}
+	return s.Clip(v)
+}
+
+// Clip pulls every value back inside its bounds and rounds integer parameters.
+func (s Space) Clip(v Vector) Vector {
+	clipped := make(Vector, len(v))
+	for i, p := range s {
+		x := math.Max(p.Min, math.Min(p.Max, v[i]))
+		if p.Integer {
+			x = math.Round(x)
+		}
+		clipped[i] = x
+	}
+	return clipped
+}
+
+// Values returns the vector keyed by parameter name.
+func (s Space) Values(v Vector) map[string]float64 {
+	values := make(map[string]float64, len(s))
+	for i, p := range s {
+		values[p.Name] = v[i]
+	}
+	return values
+}
+
+// Generation reports the state of the search after one generation.
+type Generation struct {
+	Index    int
+	Best     Vector
+	Fitness  float64
+	Sigma    float64
+	Improved bool
+}
+
+// Optimiser runs the evolution strategy. Sigma is the mutation size as a fraction of each parameter's range.
+type Optimiser struct {
+	Space    Space
+	Lambda   int
+	Sigma    float64
+	Rand     *rand.Rand
+	Evaluate func(Vector) (float64, error)
+}
+
+// Run searches for the given number of generations and returns the best vector found and its fitness.
+// progress, if not nil, is called after the starting vector is evaluated (generation 0) and after every
+// generation.
+func (o *Optimiser) Run(generations int, progress func(Generation)) (Vector, float64, error) {
+	if o.Lambda < 1 {
+		return nil, 0, errors.New("lambda must be at least 1")
+	}
+	if o.Rand == nil {
+		o.Rand = rand.New(rand.NewSource(1))
+	}
+	sigma := o.Sigma
+	if sigma <= 0 {
+		sigma = 0.2
+	}
+
+	parent := o.Space.Start()
+	fitness, err := o.Evaluate(parent)
+	if err != nil {
+		return nil, 0, err
+	}
+	if progress != nil {
+		progress(Generation{Best: parent, Fitness: fitness, Sigma: sigma})
+	}
+
+	for g := 1; g <= generations; g++ {
+		successes := 0
+		best, bestFitness := parent, fitness
+		for i := 0; i < o.Lambda; i++ {
+			child := o.mutate(parent, sigma)
+			childFitness, err := o.Evaluate(child)
+			if err != nil {
+				return nil, 0, err
+			}
+			if childFitness > fitness {
+				successes++
+			}
+			if childFitness > bestFitness {
+				best, bestFitness = child, childFitness
+			}
+		}
+		improved := bestFitness > fitness
+		parent, fitness = best, bestFitness
+
+		// The 1/5th success rule: grow the steps while more than a fifth of children improve, shrink otherwise.
+		if float64(successes)/float64(o.Lambda) > 0.2 {
+			sigma *= 1.22
+		} else {
+			sigma *= 0.82
+		}
+		if progress != nil {
+			progress(Generation{Index: g, Best: parent, Fitness: fitness, Sigma: sigma, Improved: improved})
+		}
+	}
+	return parent, fitness, nil
+}
+
+func (o *Optimiser) mutate(v Vector, sigma float64) Vector {
+	child := make(Vector, len(v))
+	for i, p := range o.Space {
+		child[i] = v[i] + o.Rand.NormFloat64()*sigma*(p.Max-p.Min)
+	}
+	return o.Space.Clip(child)
+}
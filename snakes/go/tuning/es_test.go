@@ -0,0 +1,80 @@
+package tuning
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestOptimiserFindsOptimum(t *testing.T) {
+	// Arrange
+	space := Space{
+		{Name: "x", Min: -10, Max: 10, Start: 8},
+		{Name: "n", Min: 0, Max: 100, Start: 10, Integer: true},
+	}
+	o := Optimiser{
+		Space:  space,
+		Lambda: 6,
+		Rand:   rand.New(rand.NewSource(3)),
+		Evaluate: func(v Vector) (float64, error) {
+			return -(v[0]-2)*(v[0]-2) - (v[1]-60)*(v[1]-60)/100, nil
+		},
+	}
+
+	// Act
+	best, _, err := o.Run(60, nil)
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(best[0]-2) > 0.5 || math.Abs(best[1]-60) > 5 {
+		t.Errorf("expected to get close to (2, 60), got %v", best)
+	}
+	if best[1] != math.Round(best[1]) {
+		t.Errorf("integer parameter was not rounded, got %v", best[1])
+	}
+}
+
+func TestOptimiserNeverAcceptsWorse(t *testing.T) {
+	// Arrange
+	previous := math.Inf(-1)
+	o := Optimiser{
+		Space:    Space{{Name: "x", Min: 0, Max: 1, Start: 0.5}},
+		Lambda:   2,
+		Rand:     rand.New(rand.NewSource(1)),
+		Evaluate: func(v Vector) (float64, error) { return v[0], nil },
+	}
+
+	// Act
+	_, _, err := o.Run(10, func(g Generation) {
+		// Assert
+		if g.Fitness < previous {
+			t.Errorf("generation %d went backwards: %f < %f", g.Index, g.Fitness, previous)
+		}
+		previous = g.Fitness
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// Arrange
+	a := []float64{1, 1, 1, 0.5, 1, 1, 1, 1}
+	b := []float64{0, 0, 1, 0.5, 0, 0, 0, 0}
+
+	// Act
+	c := Compare(a, b)
+
+	// Assert
+	if c.Wins != 6 || c.Ties != 2 || c.Losses != 0 {
+		t.Errorf("unexpected tally %+v", c)
+	}
+	if !c.Better() {
+		t.Errorf("expected a to be clearly better, difference %+v", c.Difference)
+	}
+	if Compare(a, a).Better() {
+		t.Errorf("a candidate should never be better than itself")
+	}
+}
@@ -0,0 +1,71 @@
+package tuning
+
+import (
+	"math"
+)
+
+// Summary is the mean of a set of game scores with a 95% confidence interval.
+type Summary struct {
+	N    int
+	Mean float64
+	// CI95 is the half width of the interval, i.e. the true mean is within Mean ± CI95 with ~95% confidence.
+	CI95 float64
+}
+
+// Summarise returns the mean and normal-approximation confidence interval of scores.
+func Summarise(scores []float64) Summary {
+	s := Summary{N: len(scores)}
+	if s.N == 0 {
+		return s
+	}
+	for _, x := range scores {
+		s.Mean += x
+	}
+	s.Mean /= float64(s.N)
+	if s.N < 2 {
+		return s
+	}
+	variance := 0.0
+	for _, x := range scores {
+		variance += (x - s.Mean) * (x - s.Mean)
+	}
+	variance /= float64(s.N - 1)
+	s.CI95 = 1.96 * math.Sqrt(variance/float64(s.N))
+	return s
+}
+
+// Comparison is a paired comparison of two candidates that played the same seeded games.
+type Comparison struct {
+	Difference Summary
+	Wins       int
+	Losses     int
+	Ties       int
+}
+
+// Better reports whether the first candidate is better with ~95% confidence.
+func (c Comparison) Better() bool {
+	return c.Difference.N > 1 && c.Difference.Mean-c.Difference.CI95 > 0
+}
+
+// Compare pairs up the scores of a and b game by game. Both slices must come from the same games in the same order.
+func Compare(a, b []float64) Comparison {
+	var c Comparison
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	diffs := make([]float64, n)
+	for i := 0; i < n; i++ {
+		diffs[i] = a[i] - b[i]
+		switch {
+		case diffs[i] > 0:
+			c.Wins++
+		case diffs[i] < 0:
+			c.Losses++
+		default:
+			c.Ties++
+		}
+	}
+	c.Difference = Summarise(diffs)
+	return c
+}